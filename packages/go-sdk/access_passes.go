@@ -1,116 +1,777 @@
 package doorpasses
 
-import "fmt"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // AccessPasses provides methods for managing access passes
 type AccessPasses struct {
 	http *HTTPClient
+
+	// generateIdempotencyKeys adds a random idempotency key to issue
+	// requests that don't carry one
+	generateIdempotencyKeys bool
+
+	// skipValidation disables validating params before issuing, updating
+	// or patching
+	skipValidation bool
+
+	// normalizeInputs trims IssueAccessPassParams before issuing
+	normalizeInputs bool
+
+	// defaultStartImmediate starts passes issued without a start date now
+	defaultStartImmediate bool
+
+	// dedupe coalesces concurrent issue calls with the same idempotency
+	// key, nil when disabled
+	dedupe *issueGroup
+
+	// bulkConcurrency limits the requests a bulk operation keeps in flight
+	bulkConcurrency int
+
+	// bulkAdaptive configures the adaptive concurrency of BulkIssue, and
+	// bulkAdaptiveMax is its Max when the config leaves it unset
+	bulkAdaptive    *AdaptiveConcurrencyConfig
+	bulkAdaptiveMax int
+
+	// fixedBulkIssue makes BulkIssue use a fixed bulkConcurrency instead
+	fixedBulkIssue bool
+
+	// bulkLimiterCreated, when set, is handed the limiter of every adaptive
+	// BulkIssue call, so tests can inspect how it adapted
+	bulkLimiterCreated func(*adaptiveLimiter)
+
+	// console resolves IssueAccessPassParams.CardTemplateRef. It is the
+	// client's Console, so both share one cache of resolved references.
+	console *Console
 }
 
 // newAccessPasses creates a new AccessPasses resource
 func newAccessPasses(httpClient *HTTPClient) *AccessPasses {
-	return &AccessPasses{
-		http: httpClient,
+	a := &AccessPasses{
+		http:            httpClient,
+		bulkConcurrency: DefaultBulkConcurrency,
+		console:         newConsole(httpClient),
 	}
+	a.console.passes = a
+	return a
 }
 
 // Issue creates a new access pass
-func (a *AccessPasses) Issue(params IssueAccessPassParams) (*AccessPass, error) {
+//
+// The params are checked with Validate before any request is sent, unless
+// Config.DisableClientValidation is set.
+//
+// When params.IdempotencyKey is set, replaying the same key returns the
+// originally issued pass instead of creating a duplicate, and the request
+// is retried on transient failures.
+//
+// With Config.Dedupe set, concurrent calls with the same IdempotencyKey
+// share a single request.
+//
+// When params.CardTemplateRef is set instead of CardTemplateID, it is
+// resolved with Console.ResolveTemplate first.
+//
+// The API may return the existing pass for a card number that already has
+// one instead of creating another; the result's NewlyCreated field tells the
+// two apart.
+//
+// When params.ID is set the pass gets that ID, and an ID that is already
+// taken fails with ErrPassIDTaken.
+//
+// Options such as WithTimeout apply to this call only.
+func (a *AccessPasses) Issue(params IssueAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	return a.IssueWithContext(context.Background(), params, opts...)
+}
+
+// IssueWithContext creates a new access pass, aborting if ctx is done
+func (a *AccessPasses) IssueWithContext(ctx context.Context, params IssueAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Issue")
+	params, err := params.withFormattedDates()
+	if err != nil {
+		return nil, err
+	}
+	if a.defaultStartImmediate && params.StartDate == "" {
+		// params is a copy, so the caller's struct is unchanged
+		params.StartDate = time.Now().UTC().Format(time.RFC3339)
+	}
+	if a.normalizeInputs {
+		if params, err = params.normalized(); err != nil {
+			return nil, err
+		}
+	}
+	if !a.skipValidation {
+		if err := params.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	if params.CardTemplateRef != "" {
+		if params.CardTemplateID != "" {
+			return nil, fmt.Errorf("cardTemplateId and cardTemplateRef must not both be set")
+		}
+		if params.CardTemplateID, err = a.console.ResolveTemplateWithContext(ctx, params.CardTemplateRef, opts...); err != nil {
+			return nil, err
+		}
+	}
+
+	dryRun := newRequestOptions(opts).dryRun
+
+	key := params.IdempotencyKey
+	if key == "" && a.generateIdempotencyKeys && !dryRun {
+		if key, err = newIdempotencyKey(); err != nil {
+			return nil, err
+		}
+	}
+
+	if key != "" && !dryRun {
+		// Copy opts so the caller's slice is never written to
+		opts = append(opts[:len(opts):len(opts)], withHeader(idempotencyKeyHeader, key))
+	}
+
+	if a.dedupe != nil && params.IdempotencyKey != "" && !dryRun {
+		return a.dedupe.do(ctx, params, func() (*AccessPass, error) {
+			return a.sendIssue(ctx, params, false, opts)
+		})
+	}
+	return a.sendIssue(ctx, params, dryRun, opts)
+}
+
+// sendIssue sends the issue request for params, which are ready to send
+func (a *AccessPasses) sendIssue(ctx context.Context, params IssueAccessPassParams, dryRun bool, opts []RequestOption) (*AccessPass, error) {
+	var meta ResponseMeta
+	opts = append(opts[:len(opts):len(opts)], WithResponseMeta(&meta))
+
+	var result AccessPass
+	err := a.http.PostWithContext(ctx, "/v1/access-passes", params, &result, opts...)
+	if err != nil {
+		if params.ID != "" && hasStatus(err, http.StatusConflict) {
+			return nil, fmt.Errorf("%w: %w", ErrPassIDTaken, err)
+		}
+		return nil, err
+	}
+	result.Replayed = meta.Replayed
+	result.NewlyCreated = meta.StatusCode == http.StatusCreated && !meta.Replayed && !dryRun
+	if dryRun {
+		if err := checkDryRun(&result); err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
+// Get retrieves a single access pass by ID
+func (a *AccessPasses) Get(accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	return a.GetWithContext(context.Background(), accessPassID, opts...)
+}
+
+// GetWithContext retrieves a single access pass by ID, aborting if ctx is done
+func (a *AccessPasses) GetWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Get")
+	if accessPassID == "" {
+		return nil, fmt.Errorf("accessPassId is required")
+	}
+
+	sigPayload := map[string]interface{}{
+		"id": accessPassID,
+	}
+
+	path := fmt.Sprintf("/v1/access-passes/%s", accessPassID)
+	if a.http.responseCache != nil {
+		return a.getCached(ctx, path, sigPayload, opts)
+	}
+
 	var result AccessPass
-	err := a.http.Post("/v1/access-passes", params, &result)
+	err := a.http.GetWithContext(ctx, path, sigPayload, &result, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-// List retrieves access passes with optional filtering
-func (a *AccessPasses) List(params *ListAccessPassesParams) ([]AccessPass, error) {
-	sigPayload := make(map[string]interface{})
+// getCached fetches an access pass with If-None-Match when a cached copy
+// exists, returning the cached copy marked NotModified if the API answers
+// 304 Not Modified
+func (a *AccessPasses) getCached(ctx context.Context, path string, sigPayload map[string]interface{}, opts []RequestOption) (*AccessPass, error) {
+	key := a.http.cacheKey(path, opts)
+	cached, ok := a.http.responseCache.Get(key)
+	if ok {
+		opts = append(opts[:len(opts):len(opts)], withHeader("If-None-Match", cached.ETag))
+	}
+	var meta ResponseMeta
+	opts = append(opts[:len(opts):len(opts)], WithResponseMeta(&meta))
 
-	if params != nil {
-		if params.TemplateID != "" {
-			sigPayload["template_id"] = params.TemplateID
+	var result AccessPass
+	err := a.http.GetWithContext(ctx, path, sigPayload, &result, opts...)
+	if ok && hasStatus(err, http.StatusNotModified) {
+		var accessPass AccessPass
+		if err := json.Unmarshal(cached.Body, &accessPass); err != nil {
+			return nil, fmt.Errorf("failed to decode cached access pass: %w", err)
 		}
-		if params.State != "" {
-			sigPayload["state"] = params.State
+		accessPass.NotModified = true
+		return &accessPass, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if meta.ETag != "" && len(result.Raw) > 0 {
+		a.http.responseCache.Set(key, CachedResponse{ETag: meta.ETag, Body: result.Raw})
+	}
+	return &result, nil
+}
+
+// List retrieves access passes with optional filtering. Only the page
+// selected by params.Cursor is returned; use ListPage to read the cursor
+// for the next page.
+func (a *AccessPasses) List(params *ListAccessPassesParams, opts ...RequestOption) ([]AccessPass, error) {
+	return a.ListWithContext(context.Background(), params, opts...)
+}
+
+// ListWithContext retrieves access passes with optional filtering, aborting if ctx is done
+func (a *AccessPasses) ListWithContext(ctx context.Context, params *ListAccessPassesParams, opts ...RequestOption) ([]AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.List")
+	page, err := a.ListPageWithContext(ctx, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return page.Items, nil
+}
+
+// ListByHolder returns every access pass issued to email, in any state,
+// oldest first. It fetches as many pages as needed.
+func (a *AccessPasses) ListByHolder(email string, opts ...RequestOption) ([]*AccessPass, error) {
+	return a.ListByHolderWithContext(context.Background(), email, opts...)
+}
+
+// ListByHolderWithContext returns every access pass issued to email,
+// aborting if ctx is done. The email filter is sent to the API, and results
+// are also matched case-insensitively on the client, so passes with a
+// different email are never returned even if the API ignores the filter.
+func (a *AccessPasses) ListByHolderWithContext(ctx context.Context, email string, opts ...RequestOption) ([]*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.ListByHolder")
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	var passes []*AccessPass
+	it := a.ListAllWithContext(ctx, &ListAccessPassesParams{Email: email}, opts...)
+	for it.Next() {
+		if strings.EqualFold(it.Pass().Email, email) {
+			passes = append(passes, it.Pass())
 		}
 	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
 
-	var result []AccessPass
-	err := a.http.Get("/v1/access-passes", sigPayload, &result)
+	// Passes without a parseable creation date go last
+	sort.SliceStable(passes, func(i, j int) bool {
+		ci, cj := passes[i].Created, passes[j].Created
+		if ci.IsZero() || cj.IsZero() {
+			return !ci.IsZero() && cj.IsZero()
+		}
+		return ci.Before(cj)
+	})
+	return passes, nil
+}
+
+// ListPage retrieves a single page of access passes. Pass the returned
+// NextCursor back in params.Cursor to fetch the following page.
+func (a *AccessPasses) ListPage(params *ListAccessPassesParams, opts ...RequestOption) (*AccessPassPage, error) {
+	return a.ListPageWithContext(context.Background(), params, opts...)
+}
+
+// ListPageWithContext retrieves a single page of access passes, aborting if ctx is done
+func (a *AccessPasses) ListPageWithContext(ctx context.Context, params *ListAccessPassesParams, opts ...RequestOption) (*AccessPassPage, error) {
+	opts = withOperation(opts, "AccessPasses.ListPage")
+	var meta ResponseMeta
+	opts = append(opts[:len(opts):len(opts)], WithResponseMeta(&meta))
+
+	var result AccessPassPage
+	err := a.http.GetWithContext(ctx, "/v1/access-passes", params.sigPayload(), &result, opts...)
 	if err != nil {
 		return nil, err
 	}
-	return result, nil
+	if !result.HasTotal {
+		if total, err := strconv.Atoi(meta.Header.Get("X-Total-Count")); err == nil && total >= 0 {
+			result.TotalCount, result.HasTotal = total, true
+		}
+	}
+	return &result, nil
+}
+
+// Update updates an existing access pass. Empty fields in params are left
+// unchanged; use Patch to set a field to its zero value. With
+// params.IfUnmodifiedSince set, the update fails with ErrConflict if the
+// pass has changed since.
+func (a *AccessPasses) Update(params UpdateAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	return a.UpdateWithContext(context.Background(), params, opts...)
 }
 
-// Update updates an existing access pass
-func (a *AccessPasses) Update(params UpdateAccessPassParams) (*AccessPass, error) {
+// UpdateWithContext updates an existing access pass, aborting if ctx is done
+func (a *AccessPasses) UpdateWithContext(ctx context.Context, params UpdateAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Update")
 	if params.AccessPassID == "" {
 		return nil, fmt.Errorf("accessPassId is required")
 	}
+	if !a.skipValidation {
+		if err := params.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if !params.IfUnmodifiedSince.IsZero() {
+		opts = append(opts, withHeader("If-Unmodified-Since", params.IfUnmodifiedSince.UTC().Format(http.TimeFormat)))
+	}
 
 	var result AccessPass
-	err := a.http.Patch(fmt.Sprintf("/v1/access-passes/%s", params.AccessPassID), params, &result)
+	err := a.http.PatchWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s", params.AccessPassID), params, &result, opts...)
 	if err != nil {
+		if stateErr := passStateError(err); stateErr != err {
+			return nil, stateErr
+		}
+		if hasStatus(err, http.StatusConflict) || hasStatus(err, http.StatusPreconditionFailed) {
+			return nil, fmt.Errorf("%w: %w", ErrConflict, err)
+		}
 		return nil, err
 	}
 	return &result, nil
 }
 
-// Suspend suspends an access pass
-func (a *AccessPasses) Suspend(accessPassID string) (*SuccessResponse, error) {
+// Patch changes only the fields of an access pass that are set in params and
+// returns the updated pass. Unlike Update, a field can be set to its zero
+// value. Changing an expired pass the API refuses to update returns
+// ErrPassExpired.
+func (a *AccessPasses) Patch(accessPassID string, params PatchAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	return a.PatchWithContext(context.Background(), accessPassID, params, opts...)
+}
+
+// PatchWithContext changes only the fields of an access pass that are set in
+// params, aborting if ctx is done
+func (a *AccessPasses) PatchWithContext(ctx context.Context, accessPassID string, params PatchAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Patch")
 	if accessPassID == "" {
 		return nil, fmt.Errorf("accessPassId is required")
 	}
 
-	var result SuccessResponse
-	err := a.http.Post(fmt.Sprintf("/v1/access-passes/%s/suspend", accessPassID), nil, &result)
+	if params.ExpiresAt != nil {
+		if params.ExpirationDate != nil {
+			return nil, fmt.Errorf("only one of expirationDate and ExpiresAt may be set")
+		}
+		expirationDate := params.ExpiresAt.UTC().Format(time.RFC3339)
+		params.ExpirationDate = &expirationDate
+	}
+	if !a.skipValidation {
+		if err := params.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	var result AccessPass
+	err := a.http.PatchWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s", accessPassID), params, &result, opts...)
 	if err != nil {
-		return nil, err
+		return nil, passStateError(err)
 	}
 	return &result, nil
 }
 
+// Suspend suspends an access pass. Use SuspendPass to get the updated pass
+// back and a typed error when the pass can't be suspended.
+func (a *AccessPasses) Suspend(accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	return a.SuspendWithContext(context.Background(), accessPassID, opts...)
+}
+
+// SuspendWithContext suspends an access pass, aborting if ctx is done
+func (a *AccessPasses) SuspendWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	opts = withOperation(opts, "AccessPasses.Suspend")
+	return a.postAction(ctx, accessPassID, "suspend", opts...)
+}
+
 // Resume resumes a suspended access pass
-func (a *AccessPasses) Resume(accessPassID string) (*SuccessResponse, error) {
+func (a *AccessPasses) Resume(accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	return a.ResumeWithContext(context.Background(), accessPassID, opts...)
+}
+
+// ResumeWithContext resumes a suspended access pass, aborting if ctx is done
+func (a *AccessPasses) ResumeWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	opts = withOperation(opts, "AccessPasses.Resume")
+	return a.postAction(ctx, accessPassID, "resume", opts...)
+}
+
+// SuspendPass temporarily pauses an access pass, keeping its identity so it
+// can be reactivated later, and returns the updated pass. Suspending a pass
+// that is already suspended returns ErrPassAlreadySuspended; a revoked or
+// deleted pass returns ErrPassAlreadyRevoked, and an expired one
+// ErrPassExpired.
+//
+// The pass's state is checked with a Get first, so this makes two requests.
+func (a *AccessPasses) SuspendPass(accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	return a.SuspendPassWithContext(context.Background(), accessPassID, opts...)
+}
+
+// SuspendPassWithContext temporarily pauses an access pass, aborting if ctx
+// is done
+func (a *AccessPasses) SuspendPassWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.SuspendPass")
+	return a.changeState(ctx, accessPassID, "suspend", opts, func(state AccessPassState) error {
+		if state == AccessPassStateSuspended {
+			return ErrPassAlreadySuspended
+		}
+		return nil
+	})
+}
+
+// Reactivate resumes a suspended access pass and returns the updated pass.
+// Reactivating a pass that isn't suspended returns ErrPassNotSuspended; a
+// revoked or deleted pass returns ErrPassAlreadyRevoked, and an expired one
+// ErrPassExpired.
+//
+// The pass's state is checked with a Get first, so this makes two requests.
+func (a *AccessPasses) Reactivate(accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	return a.ReactivateWithContext(context.Background(), accessPassID, opts...)
+}
+
+// ReactivateWithContext resumes a suspended access pass, aborting if ctx is
+// done
+func (a *AccessPasses) ReactivateWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Reactivate")
+	return a.changeState(ctx, accessPassID, "resume", opts, func(state AccessPassState) error {
+		if state != AccessPassStateSuspended {
+			return ErrPassNotSuspended
+		}
+		return nil
+	})
+}
+
+// changeState checks the current state of an access pass with allowed
+// before posting action, returning the updated pass
+func (a *AccessPasses) changeState(ctx context.Context, accessPassID, action string, opts []RequestOption, allowed func(AccessPassState) error) (*AccessPass, error) {
+	current, err := a.GetWithContext(ctx, accessPassID, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The API has no revoked state; its deleted passes are just as final
+	switch current.State {
+	case AccessPassStateRevoked, AccessPassStateDeleted:
+		return nil, fmt.Errorf("%w: cannot %s %s", ErrPassAlreadyRevoked, action, accessPassID)
+	case AccessPassStateExpired:
+		return nil, fmt.Errorf("%w: cannot %s %s", ErrPassExpired, action, accessPassID)
+	}
+	if err := allowed(current.State); err != nil {
+		return nil, fmt.Errorf("%w: cannot %s %s", err, action, accessPassID)
+	}
+
+	var result AccessPass
+	err = a.http.PostWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/%s", accessPassID, action), nil, &result, opts...)
+	if err != nil {
+		return nil, passStateError(err)
+	}
+	return &result, nil
+}
+
+// Unlink unlinks an access pass from the user's device
+func (a *AccessPasses) Unlink(accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	return a.UnlinkWithContext(context.Background(), accessPassID, opts...)
+}
+
+// UnlinkWithContext unlinks an access pass from the user's device, aborting if ctx is done
+func (a *AccessPasses) UnlinkWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	opts = withOperation(opts, "AccessPasses.Unlink")
+	return a.postAction(ctx, accessPassID, "unlink", opts...)
+}
+
+// Revoke permanently deactivates an access pass and returns its updated state.
+// Revoking a pass that is already revoked returns ErrPassAlreadyRevoked.
+func (a *AccessPasses) Revoke(accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	return a.RevokeWithContext(context.Background(), accessPassID, "", opts...)
+}
+
+// RevokeWithReason revokes an access pass, recording why it was revoked
+func (a *AccessPasses) RevokeWithReason(accessPassID, reason string, opts ...RequestOption) (*AccessPass, error) {
+	return a.RevokeWithContext(context.Background(), accessPassID, reason, opts...)
+}
+
+// RevokeWithContext revokes an access pass, aborting if ctx is done.
+// The reason is optional.
+func (a *AccessPasses) RevokeWithContext(ctx context.Context, accessPassID, reason string, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Revoke")
 	if accessPassID == "" {
 		return nil, fmt.Errorf("accessPassId is required")
 	}
 
-	var result SuccessResponse
-	err := a.http.Post(fmt.Sprintf("/v1/access-passes/%s/resume", accessPassID), nil, &result)
+	var body interface{}
+	if reason != "" {
+		body = revokeAccessPassParams{Reason: reason}
+	}
+
+	var result AccessPass
+	err := a.http.PostWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/revoke", accessPassID), body, &result, opts...)
 	if err != nil {
+		if hasStatus(err, http.StatusConflict) {
+			return nil, fmt.Errorf("%w: %w", ErrPassAlreadyRevoked, err)
+		}
 		return nil, err
 	}
 	return &result, nil
 }
 
-// Unlink unlinks an access pass from the user's device
-func (a *AccessPasses) Unlink(accessPassID string) (*SuccessResponse, error) {
+// ExpireNow ends an access pass immediately by setting its expiration to the
+// API's current time, without revoking it, and returns its updated state.
+// Expiring a pass that has already expired returns ErrPassExpired, and a
+// revoked pass returns ErrPassAlreadyRevoked.
+func (a *AccessPasses) ExpireNow(accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	return a.ExpireNowWithContext(context.Background(), accessPassID, opts...)
+}
+
+// ExpireNowWithContext expires an access pass immediately, aborting if ctx is done
+func (a *AccessPasses) ExpireNowWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.ExpireNow")
 	if accessPassID == "" {
 		return nil, fmt.Errorf("accessPassId is required")
 	}
 
-	var result SuccessResponse
-	err := a.http.Post(fmt.Sprintf("/v1/access-passes/%s/unlink", accessPassID), nil, &result)
+	var result AccessPass
+	err := a.http.PostWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/expire", accessPassID), nil, &result, opts...)
+	if err != nil {
+		return nil, passStateError(err)
+	}
+	return &result, nil
+}
+
+// rotateCredentialParams is the body of a credential rotation request
+type rotateCredentialParams struct {
+	CardNumber string `json:"cardNumber"`
+}
+
+// RotateCredential replaces the card number of an access pass, e.g. after
+// the old one was compromised, keeping the same pass and holder. The API
+// invalidates the old card number, and the returned pass carries the new one
+// and the rotation time in CredentialRotatedAt. If the pass's card template
+// doesn't support rotation, ErrCredentialRotationNotSupported is returned; a
+// revoked or expired pass returns ErrPassAlreadyRevoked or ErrPassExpired.
+func (a *AccessPasses) RotateCredential(accessPassID, newCardNumber string, opts ...RequestOption) (*AccessPass, error) {
+	return a.RotateCredentialWithContext(context.Background(), accessPassID, newCardNumber, opts...)
+}
+
+// RotateCredentialWithContext replaces the card number of an access pass, aborting if ctx is done
+func (a *AccessPasses) RotateCredentialWithContext(ctx context.Context, accessPassID, newCardNumber string, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.RotateCredential")
+	if accessPassID == "" {
+		return nil, fmt.Errorf("accessPassId is required")
+	}
+	if newCardNumber == "" {
+		return nil, fmt.Errorf("newCardNumber is required")
+	}
+
+	var result AccessPass
+	body := rotateCredentialParams{CardNumber: newCardNumber}
+	err := a.http.PostWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/rotate-credential", accessPassID), body, &result, opts...)
 	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Code == ErrorCodeCredentialRotationNotSupported {
+			return nil, fmt.Errorf("%w: %w", ErrCredentialRotationNotSupported, err)
+		}
+		return nil, passStateError(err)
+	}
+	return &result, nil
+}
+
+// Reissue issues a new access pass for the holder of an existing one, e.g. to
+// bring back a returning contractor whose pass was revoked. The holder
+// details, card template and dates of the source pass are copied and any
+// non-zero field of overrides replaces them; Metadata keys are merged, with
+// overrides winning. The credential is never copied, so overrides must
+// carry a new CardNumber or FileData. The source pass is left as it is.
+func (a *AccessPasses) Reissue(accessPassID string, overrides *IssueAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	return a.ReissueWithContext(context.Background(), accessPassID, overrides, opts...)
+}
+
+// ReissueWithContext issues a new access pass for the holder of an existing
+// one, aborting if ctx is done
+func (a *AccessPasses) ReissueWithContext(ctx context.Context, accessPassID string, overrides *IssueAccessPassParams, opts ...RequestOption) (*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.Reissue")
+	if accessPassID == "" {
+		return nil, fmt.Errorf("accessPassId is required")
+	}
+
+	source, err := a.GetWithContext(ctx, accessPassID, opts...)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, fmt.Errorf("access pass %s to reissue was not found: %w", accessPassID, err)
+		}
 		return nil, err
 	}
+	return a.IssueWithContext(ctx, reissueParams(source, overrides), opts...)
+}
+
+// reissueParams returns the params issuing a copy of source, without its
+// credential, with overrides applied
+func reissueParams(source *AccessPass, overrides *IssueAccessPassParams) IssueAccessPassParams {
+	params := IssueAccessPassParams{
+		CardTemplateID: source.CardTemplateID,
+		EmployeeID:     source.EmployeeID,
+		TagID:          source.TagID,
+		SiteCode:       source.SiteCode,
+		FullName:       source.FullName,
+		Email:          source.Email,
+		PhoneNumber:    source.PhoneNumber,
+		Classification: source.Classification,
+		StartDate:      source.StartDate,
+		ExpirationDate: source.ExpirationDate,
+		EmployeePhoto:  source.EmployeePhoto,
+		Title:          source.Title,
+	}
+	if overrides == nil {
+		overrides = &IssueAccessPassParams{}
+	}
+
+	override := func(field *string, value string) {
+		if value != "" {
+			*field = value
+		}
+	}
+	override(&params.CardTemplateID, overrides.CardTemplateID)
+	override(&params.EmployeeID, overrides.EmployeeID)
+	override(&params.TagID, overrides.TagID)
+	override(&params.SiteCode, overrides.SiteCode)
+	override(&params.CardNumber, overrides.CardNumber)
+	override(&params.FileData, overrides.FileData)
+	override(&params.FullName, overrides.FullName)
+	override(&params.Email, overrides.Email)
+	override(&params.PhoneNumber, overrides.PhoneNumber)
+	override(&params.StartDate, overrides.StartDate)
+	override(&params.ExpirationDate, overrides.ExpirationDate)
+	override(&params.EmployeePhoto, overrides.EmployeePhoto)
+	override(&params.Title, overrides.Title)
+	if overrides.Classification != "" {
+		params.Classification = overrides.Classification
+	}
+
+	// The alternative forms replace the copied value rather than conflict
+	// with it, though setting both forms in overrides is still an error
+	params.StartAt, params.ExpiresAt = overrides.StartAt, overrides.ExpiresAt
+	params.CardTemplateRef = overrides.CardTemplateRef
+	if !overrides.StartAt.IsZero() && overrides.StartDate == "" {
+		params.StartDate = ""
+	}
+	if !overrides.ExpiresAt.IsZero() && overrides.ExpirationDate == "" {
+		params.ExpirationDate = ""
+	}
+	if overrides.CardTemplateRef != "" && overrides.CardTemplateID == "" {
+		params.CardTemplateID = ""
+	}
+	params.IdempotencyKey = overrides.IdempotencyKey
+
+	if len(source.Metadata) > 0 || len(overrides.Metadata) > 0 {
+		params.Metadata = make(map[string]interface{}, len(source.Metadata)+len(overrides.Metadata))
+		for key, value := range source.Metadata {
+			params.Metadata[key] = value
+		}
+		for key, value := range overrides.Metadata {
+			params.Metadata[key] = value
+		}
+	}
+	return params
+}
+
+// ResendInvite sends the access pass invitation to its holder again over
+// channel, or over the channel it was issued with when channel is empty.
+// Resending for a revoked or expired pass returns ErrPassAlreadyRevoked or
+// ErrPassExpired.
+func (a *AccessPasses) ResendInvite(accessPassID string, channel DeliveryChannel, opts ...RequestOption) (*ResendInviteResult, error) {
+	return a.ResendInviteWithContext(context.Background(), accessPassID, channel, opts...)
+}
+
+// ResendInviteWithContext sends the access pass invitation again, aborting if ctx is done
+func (a *AccessPasses) ResendInviteWithContext(ctx context.Context, accessPassID string, channel DeliveryChannel, opts ...RequestOption) (*ResendInviteResult, error) {
+	opts = withOperation(opts, "AccessPasses.ResendInvite")
+	if accessPassID == "" {
+		return nil, fmt.Errorf("accessPassId is required")
+	}
+
+	var body interface{}
+	if channel != "" {
+		body = resendInviteParams{Channel: channel}
+	}
+
+	var result ResendInviteResult
+	err := a.http.PostWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/resend", accessPassID), body, &result, opts...)
+	if err != nil {
+		return nil, passStateError(err)
+	}
 	return &result, nil
 }
 
 // Delete permanently deletes an access pass
-func (a *AccessPasses) Delete(accessPassID string) (*SuccessResponse, error) {
+func (a *AccessPasses) Delete(accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	return a.DeleteWithContext(context.Background(), accessPassID, opts...)
+}
+
+// DeleteWithContext permanently deletes an access pass, aborting if ctx is done
+func (a *AccessPasses) DeleteWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*SuccessResponse, error) {
+	opts = withOperation(opts, "AccessPasses.Delete")
+	return a.postAction(ctx, accessPassID, "delete", opts...)
+}
+
+// postAction calls one of the POST /v1/access-passes/{id}/{action} endpoints
+func (a *AccessPasses) postAction(ctx context.Context, accessPassID, action string, opts ...RequestOption) (*SuccessResponse, error) {
 	if accessPassID == "" {
 		return nil, fmt.Errorf("accessPassId is required")
 	}
 
 	var result SuccessResponse
-	err := a.http.Post(fmt.Sprintf("/v1/access-passes/%s/delete", accessPassID), nil, &result)
+	err := a.http.PostWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/%s", accessPassID, action), nil, &result, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
+
+// ValidFor returns the StartDate and ExpirationDate of a pass that is valid
+// for d from start, formatted in UTC as RFC3339. It returns an error if d
+// isn't positive.
+//
+// Example:
+//
+//	params.StartDate, params.ExpirationDate, err = doorpasses.ValidFor(start, 90*24*time.Hour)
+func ValidFor(start time.Time, d time.Duration) (startDate, expirationDate string, err error) {
+	if d <= 0 {
+		return "", "", fmt.Errorf("validity duration must be positive, got %v", d)
+	}
+	if start.IsZero() {
+		return "", "", fmt.Errorf("validity start is required")
+	}
+	return start.UTC().Format(time.RFC3339), start.Add(d).UTC().Format(time.RFC3339), nil
+}
+
+// ValidFromNow returns the StartDate and ExpirationDate of a pass that is
+// valid for d starting now
+func ValidFromNow(d time.Duration) (startDate, expirationDate string, err error) {
+	return ValidFor(time.Now(), d)
+}
+
+// withFormattedDates returns a copy of params with StartAt and ExpiresAt
+// formatted into StartDate and ExpirationDate
+func (p IssueAccessPassParams) withFormattedDates() (IssueAccessPassParams, error) {
+	if !p.StartAt.IsZero() {
+		if p.StartDate != "" {
+			return p, fmt.Errorf("only one of startDate and StartAt may be set")
+		}
+		p.StartDate = p.StartAt.UTC().Format(time.RFC3339)
+	}
+	if !p.ExpiresAt.IsZero() {
+		if p.ExpirationDate != "" {
+			return p, fmt.Errorf("only one of expirationDate and ExpiresAt may be set")
+		}
+		p.ExpirationDate = p.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	return p, nil
+}
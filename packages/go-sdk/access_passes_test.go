@@ -0,0 +1,1211 @@
+package doorpasses
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAccessPassesGet(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Method = %v, want GET", r.Method)
+		}
+		if r.URL.Query().Get("sig_payload") == "" {
+			t.Error("sig_payload query parameter is empty")
+		}
+
+		switch r.URL.Path {
+		case "/v1/access-passes/pass_123":
+			w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "fullName": "John Doe", "state": "active"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+		}
+	})
+
+	accessPass, err := client.AccessPasses.Get("pass_123")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if accessPass.ID != "pass_123" || accessPass.State != AccessPassStateActive {
+		t.Errorf("Get() = %+v, want active pass_123", accessPass)
+	}
+
+	if _, err := client.AccessPasses.Get("pass_missing"); !IsNotFound(err) {
+		t.Errorf("Get() error = %v, want not found", err)
+	}
+
+	if _, err := client.AccessPasses.Get(""); err == nil {
+		t.Error("Get() with empty ID should return an error")
+	}
+}
+
+func TestAccessPassesRevoke(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/access-passes/pass_123/revoke":
+			w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "state": "revoked"}}`))
+		case "/v1/access-passes/pass_revoked/revoke":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"success": false, "error": {"code": "PASS_ALREADY_REVOKED", "message": "Access pass is already revoked"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	accessPass, err := client.AccessPasses.RevokeWithReason("pass_123", "left the company")
+	if err != nil {
+		t.Fatalf("RevokeWithReason() error = %v", err)
+	}
+	if accessPass.State != AccessPassStateRevoked {
+		t.Errorf("State = %v, want %v", accessPass.State, AccessPassStateRevoked)
+	}
+
+	_, err = client.AccessPasses.Revoke("pass_revoked")
+	if !errors.Is(err, ErrPassAlreadyRevoked) {
+		t.Errorf("Revoke() error = %v, want ErrPassAlreadyRevoked", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Errorf("Revoke() error = %v, want wrapped 409 APIError", err)
+	}
+}
+
+func TestAccessPassesExpireNow(t *testing.T) {
+	tests := []struct {
+		name      string
+		id        string
+		status    int
+		body      string
+		wantState AccessPassState
+		wantErr   error
+	}{
+		{
+			name:      "expires the pass",
+			id:        "pass_123",
+			status:    http.StatusOK,
+			body:      `{"success": true, "data": {"id": "pass_123", "state": "expired", "expirationDate": "2025-11-01T10:00:00Z"}}`,
+			wantState: AccessPassStateExpired,
+		},
+		{
+			name:    "already expired",
+			id:      "pass_expired",
+			status:  http.StatusConflict,
+			body:    `{"success": false, "error": {"code": "ACCESS_PASS_EXPIRED", "message": "Access pass has already expired"}}`,
+			wantErr: ErrPassExpired,
+		},
+		{
+			name:    "revoked",
+			id:      "pass_revoked",
+			status:  http.StatusConflict,
+			body:    `{"success": false, "error": {"code": "ACCESS_PASS_REVOKED", "message": "Access pass is revoked"}}`,
+			wantErr: ErrPassAlreadyRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if want := "/v1/access-passes/" + tt.id + "/expire"; r.Method != http.MethodPost || r.URL.Path != want {
+					t.Errorf("request = %s %s, want POST %s", r.Method, r.URL.Path, want)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			accessPass, err := client.AccessPasses.ExpireNow(tt.id)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ExpireNow() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExpireNow() error = %v", err)
+			}
+			if accessPass.State != tt.wantState {
+				t.Errorf("State = %v, want %v", accessPass.State, tt.wantState)
+			}
+		})
+	}
+
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+	if _, err := client.AccessPasses.ExpireNow(""); err == nil {
+		t.Error("ExpireNow(\"\") error = nil, want error")
+	}
+}
+
+func TestAccessPassesRotateCredential(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr error
+	}{
+		{
+			name:   "rotated",
+			status: http.StatusOK,
+			body:   `{"success": true, "data": {"id": "pass_123", "cardNumber": "67890", "credentialRotatedAt": "2025-11-01T10:00:00Z"}}`,
+		},
+		{
+			name:    "not supported by the template",
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"success": false, "error": {"code": "CREDENTIAL_ROTATION_NOT_SUPPORTED", "message": "Card template does not support credential rotation"}}`,
+			wantErr: ErrCredentialRotationNotSupported,
+		},
+		{
+			name:    "revoked",
+			status:  http.StatusConflict,
+			body:    `{"success": false, "error": {"code": "ACCESS_PASS_REVOKED", "message": "Access pass is revoked"}}`,
+			wantErr: ErrPassAlreadyRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/access-passes/pass_123/rotate-credential" {
+					t.Errorf("request = %s %s, want POST /v1/access-passes/pass_123/rotate-credential", r.Method, r.URL.Path)
+				}
+				var body map[string]string
+				json.NewDecoder(r.Body).Decode(&body)
+				if body["cardNumber"] != "67890" {
+					t.Errorf("body = %v, want cardNumber 67890", body)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			accessPass, err := client.AccessPasses.RotateCredential("pass_123", "67890")
+			if tt.wantErr != nil {
+				var apiErr *APIError
+				if !errors.Is(err, tt.wantErr) || !errors.As(err, &apiErr) {
+					t.Errorf("RotateCredential() error = %v, want %v wrapping an APIError", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RotateCredential() error = %v", err)
+			}
+			if accessPass.CardNumber != "67890" {
+				t.Errorf("CardNumber = %q, want 67890", accessPass.CardNumber)
+			}
+			if want := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC); !accessPass.CredentialRotated.Equal(want) {
+				t.Errorf("CredentialRotated = %v, want %v", accessPass.CredentialRotated, want)
+			}
+		})
+	}
+
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request")
+	})
+	if _, err := client.AccessPasses.RotateCredential("pass_123", ""); err == nil {
+		t.Error("RotateCredential() without a card number error = nil, want error")
+	}
+}
+
+func TestAccessPassesListPageTotalCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       string
+		body         string
+		wantTotal    int
+		wantHasTotal bool
+	}{
+		{
+			name:         "API pagination",
+			body:         `{"items": [{"id": "pass_1", "state": "ACTIVE"}], "pagination": {"page": 1, "limit": 50, "total": 3412, "totalPages": 69}}`,
+			wantTotal:    3412,
+			wantHasTotal: true,
+		},
+		{
+			name:         "API pagination of an empty listing",
+			header:       "57",
+			body:         `{"items": [], "pagination": {"page": 1, "limit": 50, "total": 0, "totalPages": 0}}`,
+			wantHasTotal: true,
+		},
+		{
+			name: "pagination without a total",
+			body: `{"items": [], "pagination": {"page": 1, "limit": 50}}`,
+		},
+		{
+			name:         "field",
+			body:         `{"items": [{"id": "pass_1"}], "hasMore": true, "totalCount": 3412}`,
+			wantTotal:    3412,
+			wantHasTotal: true,
+		},
+		{
+			name:         "zero field",
+			body:         `{"items": [], "hasMore": false, "totalCount": 0}`,
+			wantHasTotal: true,
+		},
+		{
+			name:         "header",
+			header:       "57",
+			body:         `{"items": [{"id": "pass_1"}], "hasMore": true}`,
+			wantTotal:    57,
+			wantHasTotal: true,
+		},
+		{
+			name:         "field wins over header",
+			header:       "57",
+			body:         `{"items": [], "totalCount": 12}`,
+			wantTotal:    12,
+			wantHasTotal: true,
+		},
+		{name: "none", body: `{"items": [{"id": "pass_1"}], "hasMore": false}`},
+		{name: "invalid header", header: "many", body: `[{"id": "pass_1"}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if tt.header != "" {
+					w.Header().Set("X-Total-Count", tt.header)
+				}
+				w.Write([]byte(`{"success": true, "data": ` + tt.body + `}`))
+			})
+
+			page, err := client.AccessPasses.ListPage(nil)
+			if err != nil {
+				t.Fatalf("ListPage() error = %v", err)
+			}
+			if page.TotalCount != tt.wantTotal || page.HasTotal != tt.wantHasTotal {
+				t.Errorf("TotalCount, HasTotal = %d, %v, want %d, %v", page.TotalCount, page.HasTotal, tt.wantTotal, tt.wantHasTotal)
+			}
+			if requests != 1 {
+				t.Errorf("sent %d requests, want 1", requests)
+			}
+		})
+	}
+}
+
+func TestAccessPassesListPage(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		payload, err := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		if err != nil {
+			t.Errorf("failed to decode sig_payload: %v", err)
+			return
+		}
+
+		var params map[string]interface{}
+		json.Unmarshal(payload, &params)
+
+		switch params["cursor"] {
+		case nil:
+			if params["email"] != "john@example.com" || params["limit"] != float64(1) {
+				t.Errorf("sig_payload = %v, want email and limit", params)
+			}
+			w.Write([]byte(`{"success": true, "data": {"items": [{"id": "pass_1"}], "nextCursor": "cursor_2", "hasMore": true}}`))
+		case "cursor_2":
+			w.Write([]byte(`{"success": true, "data": {"items": [{"id": "pass_2"}], "hasMore": false}}`))
+		default:
+			t.Errorf("unexpected cursor %v", params["cursor"])
+		}
+	})
+
+	params := &ListAccessPassesParams{Email: "john@example.com", Limit: 1}
+	page, err := client.AccessPasses.ListPage(params)
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "pass_1" || !page.HasMore || page.NextCursor != "cursor_2" {
+		t.Errorf("ListPage() = %+v, want first page", page)
+	}
+
+	params.Cursor = page.NextCursor
+	page, err = client.AccessPasses.ListPage(params)
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "pass_2" || page.HasMore {
+		t.Errorf("ListPage() = %+v, want last page", page)
+	}
+}
+
+func TestAccessPassesListIncludeArchived(t *testing.T) {
+	tests := []struct {
+		name            string
+		includeArchived bool
+		wantIDs         []string
+	}{
+		{name: "default", wantIDs: []string{"pass_active"}},
+		{name: "include archived", includeArchived: true, wantIDs: []string{"pass_active", "pass_archived"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				payload, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+				var params map[string]interface{}
+				json.Unmarshal(payload, &params)
+
+				if _, ok := params["include_archived"]; !tt.includeArchived && ok {
+					t.Errorf("sig_payload = %v, want no include_archived", params)
+				}
+				if params["include_archived"] == true {
+					w.Write([]byte(`{"success": true, "data": {"items": [
+						{"id": "pass_active", "state": "active"},
+						{"id": "pass_archived", "state": "revoked", "archived": true}
+					]}}`))
+					return
+				}
+				w.Write([]byte(`{"success": true, "data": {"items": [{"id": "pass_active", "state": "active"}]}}`))
+			})
+
+			passes, err := client.AccessPasses.List(&ListAccessPassesParams{IncludeArchived: tt.includeArchived})
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if len(passes) != len(tt.wantIDs) {
+				t.Fatalf("List() returned %d passes, want %d", len(passes), len(tt.wantIDs))
+			}
+			for i, id := range tt.wantIDs {
+				if passes[i].ID != id {
+					t.Errorf("passes[%d].ID = %q, want %q", i, passes[i].ID, id)
+				}
+				if wantArchived := id == "pass_archived"; passes[i].Archived != wantArchived {
+					t.Errorf("passes[%d].Archived = %v, want %v", i, passes[i].Archived, wantArchived)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessPassPageUnmarshalArray(t *testing.T) {
+	var page AccessPassPage
+	if err := json.Unmarshal([]byte(`[{"id": "pass_1"}, {"id": "pass_2"}]`), &page); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(page.Items) != 2 || page.HasMore {
+		t.Errorf("Unmarshal() = %+v, want two items and no more pages", page)
+	}
+}
+
+func TestAccessPassesIssueIdempotencyKey(t *testing.T) {
+	noBackoff := func(attempt int) time.Duration { return 0 }
+
+	tests := []struct {
+		name         string
+		config       *Config
+		key          string
+		wantKey      string
+		wantRequests int
+	}{
+		{
+			name:         "explicit key is sent and retried",
+			config:       &Config{RetryBackoff: noBackoff},
+			key:          "issue-emp-456",
+			wantKey:      "issue-emp-456",
+			wantRequests: 2,
+		},
+		{
+			name:         "generated key is reused across retries",
+			config:       &Config{RetryBackoff: noBackoff, GenerateIdempotencyKeys: true},
+			wantRequests: 2,
+		},
+		{
+			name:         "no key is not retried",
+			config:       &Config{RetryBackoff: noBackoff},
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keys []string
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				keys = append(keys, r.Header.Get("Idempotency-Key"))
+				if len(keys) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			params := validIssueParams()
+			params.IdempotencyKey = tt.key
+			client.AccessPasses.Issue(params)
+
+			if len(keys) != tt.wantRequests {
+				t.Fatalf("server received %d requests, want %d", len(keys), tt.wantRequests)
+			}
+			if tt.wantKey != "" && keys[0] != tt.wantKey {
+				t.Errorf("Idempotency-Key = %q, want %q", keys[0], tt.wantKey)
+			}
+			for _, key := range keys[1:] {
+				if key != keys[0] {
+					t.Errorf("Idempotency-Key changed between attempts: %v", keys)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueReplayed(t *testing.T) {
+	tests := []struct {
+		name         string
+		replayed     string
+		wantReplayed bool
+	}{
+		{name: "created", wantReplayed: false},
+		{name: "replayed", replayed: "true", wantReplayed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if tt.replayed != "" {
+					w.Header().Set("Idempotency-Replayed", tt.replayed)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			params := validIssueParams()
+			params.IdempotencyKey = "issue-emp-456"
+			var meta ResponseMeta
+			accessPass, err := client.AccessPasses.Issue(params, WithResponseMeta(&meta))
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			if accessPass.Replayed != tt.wantReplayed {
+				t.Errorf("Replayed = %v, want %v", accessPass.Replayed, tt.wantReplayed)
+			}
+			if meta.Replayed != tt.wantReplayed || meta.StatusCode != http.StatusCreated {
+				t.Errorf("caller's ResponseMeta = %+v, want it filled in too", meta)
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueNewlyCreated(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCode       int
+		replayed         bool
+		dryRun           bool
+		wantNewlyCreated bool
+	}{
+		{name: "created", statusCode: http.StatusCreated, wantNewlyCreated: true},
+		{name: "existing pass", statusCode: http.StatusOK},
+		{name: "replayed", statusCode: http.StatusCreated, replayed: true},
+		{name: "dry run", statusCode: http.StatusCreated, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if tt.replayed {
+					w.Header().Set("Idempotency-Replayed", "true")
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "dryRun": ` + fmt.Sprint(tt.dryRun) + `}}`))
+			})
+
+			var opts []RequestOption
+			if tt.dryRun {
+				opts = append(opts, WithDryRun())
+			}
+			accessPass, err := client.AccessPasses.Issue(validIssueParams(), opts...)
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			if accessPass.NewlyCreated != tt.wantNewlyCreated {
+				t.Errorf("NewlyCreated = %v, want %v", accessPass.NewlyCreated, tt.wantNewlyCreated)
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueByTemplateRef(t *testing.T) {
+	var gotBody map[string]interface{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/console/card-templates":
+			w.Write([]byte(`{"success": true, "data": {"items": [{"id": "template_456", "externalRef": "employee-badge"}]}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/access-passes":
+			json.NewDecoder(r.Body).Decode(&gotBody)
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "cardTemplateId": "template_456"}}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	params := validIssueParams()
+	params.CardTemplateID = ""
+	params.CardTemplateRef = "employee-badge"
+	if _, err := client.AccessPasses.Issue(params); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if gotBody["cardTemplateId"] != "template_456" {
+		t.Errorf("cardTemplateId = %v, want template_456", gotBody["cardTemplateId"])
+	}
+	if _, ok := gotBody["cardTemplateRef"]; ok {
+		t.Errorf("body sent cardTemplateRef: %v", gotBody)
+	}
+
+	params.CardTemplateID = "template_123"
+	var validationErr *ValidationError
+	if _, err := client.AccessPasses.Issue(params); !errors.As(err, &validationErr) {
+		t.Errorf("Issue() with ID and ref error = %v, want *ValidationError", err)
+	}
+}
+
+func TestValidFor(t *testing.T) {
+	riyadh := time.FixedZone("AST", 3*60*60)
+
+	tests := []struct {
+		name           string
+		start          time.Time
+		d              time.Duration
+		wantStart      string
+		wantExpiration string
+		wantErr        bool
+	}{
+		{
+			name:           "converts to UTC",
+			start:          time.Date(2025, 11, 1, 9, 0, 0, 0, riyadh),
+			d:              365 * 24 * time.Hour,
+			wantStart:      "2025-11-01T06:00:00Z",
+			wantExpiration: "2026-11-01T06:00:00Z",
+		},
+		{
+			name:           "drops sub-second precision",
+			start:          time.Date(2025, 11, 1, 0, 0, 0, 500, time.UTC),
+			d:              time.Hour,
+			wantStart:      "2025-11-01T00:00:00Z",
+			wantExpiration: "2025-11-01T01:00:00Z",
+		},
+		{name: "zero duration", start: time.Now(), d: 0, wantErr: true},
+		{name: "negative duration", start: time.Now(), d: -time.Hour, wantErr: true},
+		{name: "zero start", d: time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, expiration, err := ValidFor(tt.start, tt.d)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ValidFor() = %q, %q, want error", start, expiration)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidFor() error = %v", err)
+			}
+			if start != tt.wantStart || expiration != tt.wantExpiration {
+				t.Errorf("ValidFor() = %q, %q, want %q, %q", start, expiration, tt.wantStart, tt.wantExpiration)
+			}
+
+			params := validIssueParams()
+			params.StartDate, params.ExpirationDate = start, expiration
+			if err := params.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+
+	before := time.Now().Truncate(time.Second)
+	start, _, err := ValidFromNow(time.Hour)
+	if err != nil {
+		t.Fatalf("ValidFromNow() error = %v", err)
+	}
+	if got, _ := time.Parse(time.RFC3339, start); got.Before(before) || got.After(time.Now()) {
+		t.Errorf("ValidFromNow() start = %q, want now", start)
+	}
+}
+
+func TestIssueAccessPassParamsWithFormattedDates(t *testing.T) {
+	riyadh := time.FixedZone("AST", 3*60*60)
+	start := time.Date(2025, 11, 1, 9, 0, 0, 0, riyadh)
+
+	tests := []struct {
+		name               string
+		params             IssueAccessPassParams
+		wantStartDate      string
+		wantExpirationDate string
+		wantErr            bool
+	}{
+		{
+			name:               "time fields are formatted in UTC",
+			params:             IssueAccessPassParams{StartAt: start, ExpiresAt: start.AddDate(1, 0, 0)},
+			wantStartDate:      "2025-11-01T06:00:00Z",
+			wantExpirationDate: "2026-11-01T06:00:00Z",
+		},
+		{
+			name:               "string fields are kept",
+			params:             IssueAccessPassParams{StartDate: "2025-11-01T00:00:00Z", ExpirationDate: "2026-11-01T00:00:00Z"},
+			wantStartDate:      "2025-11-01T00:00:00Z",
+			wantExpirationDate: "2026-11-01T00:00:00Z",
+		},
+		{
+			name:    "both forms of start date",
+			params:  IssueAccessPassParams{StartDate: "2025-11-01T00:00:00Z", StartAt: start},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.params.withFormattedDates()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("withFormattedDates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.StartDate != tt.wantStartDate {
+				t.Errorf("StartDate = %v, want %v", got.StartDate, tt.wantStartDate)
+			}
+			if got.ExpirationDate != tt.wantExpirationDate {
+				t.Errorf("ExpirationDate = %v, want %v", got.ExpirationDate, tt.wantExpirationDate)
+			}
+		})
+	}
+}
+
+// validIssueParams returns params that pass IssueAccessPassParams.Validate
+func validIssueParams() IssueAccessPassParams {
+	return IssueAccessPassParams{
+		CardTemplateID: "template_123",
+		CardNumber:     "12345",
+		FullName:       "John Doe",
+		Email:          "john@example.com",
+		StartDate:      "2025-11-01T00:00:00Z",
+		ExpirationDate: "2026-11-01T00:00:00Z",
+	}
+}
+
+func TestAccessPassesPatch(t *testing.T) {
+	title := ""
+	expiresAt := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		params     PatchAccessPassParams
+		statusCode int
+		response   string
+		wantBody   map[string]interface{}
+		wantErr    error
+	}{
+		{
+			name:       "only set fields are sent",
+			params:     PatchAccessPassParams{Title: &title, ExpiresAt: &expiresAt},
+			statusCode: http.StatusOK,
+			response:   `{"success": true, "data": {"id": "pass_123", "expirationDate": "2027-01-01T00:00:00Z"}}`,
+			wantBody:   map[string]interface{}{"title": "", "expirationDate": "2027-01-01T00:00:00Z"},
+		},
+		{
+			name:       "expired pass",
+			params:     PatchAccessPassParams{ExpiresAt: &expiresAt},
+			statusCode: http.StatusConflict,
+			response:   `{"success": false, "error": {"code": "ACCESS_PASS_EXPIRED", "message": "Access pass has expired"}}`,
+			wantErr:    ErrPassExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody map[string]interface{}
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPatch || r.URL.Path != "/v1/access-passes/pass_123" {
+					t.Errorf("request = %s %s, want PATCH /v1/access-passes/pass_123", r.Method, r.URL.Path)
+				}
+				json.NewDecoder(r.Body).Decode(&gotBody)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			})
+
+			accessPass, err := client.AccessPasses.Patch("pass_123", tt.params)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Patch() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Patch() error = %v", err)
+			}
+			if accessPass.ID != "pass_123" {
+				t.Errorf("ID = %q, want pass_123", accessPass.ID)
+			}
+			if len(gotBody) != len(tt.wantBody) {
+				t.Errorf("body = %v, want %v", gotBody, tt.wantBody)
+			}
+			for key, want := range tt.wantBody {
+				if gotBody[key] != want {
+					t.Errorf("body[%q] = %v, want %v", key, gotBody[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestAccessPassesResendInvite(t *testing.T) {
+	tests := []struct {
+		name        string
+		channel     DeliveryChannel
+		statusCode  int
+		response    string
+		wantChannel DeliveryChannel
+		wantBody    string
+		wantErr     error
+	}{
+		{
+			name:        "default channel",
+			statusCode:  http.StatusOK,
+			response:    `{"success": true, "data": {"success": true, "channel": "email"}}`,
+			wantChannel: DeliveryChannelEmail,
+		},
+		{
+			name:        "explicit channel",
+			channel:     DeliveryChannelSMS,
+			statusCode:  http.StatusOK,
+			response:    `{"success": true, "data": {"success": true, "channel": "sms"}}`,
+			wantChannel: DeliveryChannelSMS,
+			wantBody:    `{"channel":"sms"}`,
+		},
+		{
+			name:       "revoked pass",
+			statusCode: http.StatusConflict,
+			response:   `{"success": false, "error": {"code": "ACCESS_PASS_REVOKED", "message": "Access pass has been revoked"}}`,
+			wantErr:    ErrPassAlreadyRevoked,
+		},
+		{
+			name:       "expired pass",
+			statusCode: http.StatusConflict,
+			response:   `{"success": false, "error": {"code": "ACCESS_PASS_EXPIRED", "message": "Access pass has expired"}}`,
+			wantErr:    ErrPassExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody []byte
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/access-passes/pass_123/resend" {
+					t.Errorf("request = %s %s, want POST /v1/access-passes/pass_123/resend", r.Method, r.URL.Path)
+				}
+				gotBody, _ = io.ReadAll(r.Body)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			})
+
+			result, err := client.AccessPasses.ResendInvite("pass_123", tt.channel)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ResendInvite() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResendInvite() error = %v", err)
+			}
+			if result.Channel != tt.wantChannel {
+				t.Errorf("Channel = %q, want %q", result.Channel, tt.wantChannel)
+			}
+			if string(gotBody) != tt.wantBody {
+				t.Errorf("body = %s, want %s", gotBody, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAccessPassesListByHolder(t *testing.T) {
+	pages := map[string]string{
+		"": `{"items": [
+			{"id": "pass_3", "email": "John@Example.com", "state": "revoked", "createdAt": "2025-03-01T00:00:00Z"},
+			{"id": "pass_other", "email": "jane@example.com", "createdAt": "2025-01-01T00:00:00Z"}
+		], "nextCursor": "page_2", "hasMore": true}`,
+		"page_2": `{"items": [
+			{"id": "pass_1", "email": "john@example.com", "state": "active", "createdAt": "2025-01-01T00:00:00+02:00"},
+			{"id": "pass_undated", "email": "john@example.com"},
+			{"id": "pass_2", "email": "JOHN@example.com", "state": "suspended", "createdAt": "2025-02-01T00:00:00Z"}
+		]}`,
+	}
+
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		var sig map[string]interface{}
+		json.Unmarshal(payload, &sig)
+		if sig["email"] != "john@example.com" {
+			t.Errorf("email filter = %v, want john@example.com", sig["email"])
+		}
+		cursor, _ := sig["cursor"].(string)
+		w.Write([]byte(`{"success": true, "data": ` + pages[cursor] + `}`))
+	})
+
+	passes, err := client.AccessPasses.ListByHolder("john@example.com")
+	if err != nil {
+		t.Fatalf("ListByHolder() error = %v", err)
+	}
+
+	want := []string{"pass_1", "pass_2", "pass_3", "pass_undated"}
+	if len(passes) != len(want) {
+		t.Fatalf("ListByHolder() returned %d passes, want %d", len(passes), len(want))
+	}
+	for i, id := range want {
+		if passes[i].ID != id {
+			t.Errorf("passes[%d].ID = %q, want %q", i, passes[i].ID, id)
+		}
+	}
+
+	if _, err := client.AccessPasses.ListByHolder(""); err == nil {
+		t.Error("ListByHolder(\"\") returned no error")
+	}
+}
+
+func TestAccessPassesSuspendAndReactivate(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		suspend   bool
+		wantState AccessPassState
+		wantErr   error
+	}{
+		{name: "suspend active", state: "active", suspend: true, wantState: AccessPassStateSuspended},
+		{name: "suspend suspended", state: "suspended", suspend: true, wantErr: ErrPassAlreadySuspended},
+		{name: "suspend revoked", state: "revoked", suspend: true, wantErr: ErrPassAlreadyRevoked},
+		{name: "reactivate suspended", state: "suspended", wantState: AccessPassStateActive},
+		{name: "reactivate active", state: "active", wantErr: ErrPassNotSuspended},
+		{name: "reactivate expired", state: "expired", wantErr: ErrPassExpired},
+
+		// States as the API sends them
+		{name: "suspend ACTIVE", state: "ACTIVE", suspend: true, wantState: AccessPassStateSuspended},
+		{name: "suspend SUSPENDED", state: "SUSPENDED", suspend: true, wantErr: ErrPassAlreadySuspended},
+		{name: "suspend DELETED", state: "DELETED", suspend: true, wantErr: ErrPassAlreadyRevoked},
+		{name: "suspend EXPIRED", state: "EXPIRED", suspend: true, wantErr: ErrPassExpired},
+		{name: "reactivate SUSPENDED", state: "SUSPENDED", wantState: AccessPassStateActive},
+		{name: "reactivate ACTIVE", state: "ACTIVE", wantErr: ErrPassNotSuspended},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posted := ""
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "state": "` + tt.state + `"}}`))
+					return
+				}
+				posted = r.URL.Path
+				state := "ACTIVE"
+				if strings.HasSuffix(r.URL.Path, "/suspend") {
+					state = "SUSPENDED"
+				}
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "state": "` + state + `"}}`))
+			})
+
+			var accessPass *AccessPass
+			var err error
+			wantPath := "/v1/access-passes/pass_123/resume"
+			if tt.suspend {
+				accessPass, err = client.AccessPasses.SuspendPass("pass_123")
+				wantPath = "/v1/access-passes/pass_123/suspend"
+			} else {
+				accessPass, err = client.AccessPasses.Reactivate("pass_123")
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if posted != "" {
+					t.Errorf("state change was posted to %s despite the error", posted)
+				}
+				return
+			}
+			if posted != wantPath {
+				t.Errorf("posted to %q, want %q", posted, wantPath)
+			}
+			if accessPass.State != tt.wantState {
+				t.Errorf("State = %q, want %q", accessPass.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueDeterministicSignature(t *testing.T) {
+	type signed struct {
+		body      string
+		signature string
+	}
+	var requests []signed
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signature := r.Header.Get("X-PAYLOAD-SIG")
+		if !verifySignature("test_secret", base64.StdEncoding.EncodeToString(body), signature) {
+			t.Errorf("request body %s is not what was signed", body)
+		}
+		requests = append(requests, signed{body: string(body), signature: signature})
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	// Map iteration order is random, so a large metadata map would expose
+	// any dependence on it
+	metadata := make(map[string]interface{})
+	for i := 0; i < 50; i++ {
+		metadata[fmt.Sprintf("key_%d", i)] = map[string]interface{}{"a": i, "b": "value", "c": []int{i}}
+	}
+
+	for i := 0; i < 5; i++ {
+		params := validIssueParams()
+		params.Metadata = metadata
+		if _, err := client.AccessPasses.Issue(params); err != nil {
+			t.Fatalf("Issue() error = %v", err)
+		}
+	}
+
+	for i, req := range requests[1:] {
+		if req != requests[0] {
+			t.Errorf("request %d = %+v, want %+v", i+2, req, requests[0])
+		}
+	}
+}
+
+func TestAccessPassesUpdateIfUnmodifiedSince(t *testing.T) {
+	updated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		since      time.Time
+		statusCode int
+		body       string
+		wantHeader string
+		wantErr    error
+	}{
+		{
+			name:       "no precondition",
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123"}}`,
+		},
+		{
+			name:       "unchanged",
+			since:      updated,
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123"}}`,
+			wantHeader: "Sun, 01 Mar 2026 12:00:00 GMT",
+		},
+		{
+			name:       "precondition failed",
+			since:      updated,
+			statusCode: http.StatusPreconditionFailed,
+			body:       `{"success": false, "error": {"code": "PRECONDITION_FAILED", "message": "Access pass was modified"}}`,
+			wantHeader: "Sun, 01 Mar 2026 12:00:00 GMT",
+			wantErr:    ErrConflict,
+		},
+		{
+			name:       "conflict",
+			statusCode: http.StatusConflict,
+			body:       `{"success": false, "error": {"code": "CONFLICT", "message": "Access pass was modified"}}`,
+			wantErr:    ErrConflict,
+		},
+		{
+			name:       "expired pass",
+			statusCode: http.StatusConflict,
+			body:       `{"success": false, "error": {"code": "ACCESS_PASS_EXPIRED", "message": "Access pass has expired"}}`,
+			wantErr:    ErrPassExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("If-Unmodified-Since"); got != tt.wantHeader {
+					t.Errorf("If-Unmodified-Since = %q, want %q", got, tt.wantHeader)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := client.AccessPasses.Update(UpdateAccessPassParams{
+				AccessPassID:      "pass_123",
+				Title:             "Engineer",
+				IfUnmodifiedSince: tt.since.In(time.FixedZone("UTC+4", 4*60*60)),
+			})
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Errorf("Update() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == ErrPassExpired && errors.Is(err, ErrConflict) {
+				t.Errorf("Update() error = %v, want no ErrConflict", err)
+			}
+		})
+	}
+}
+
+func TestAccessPassesReissue(t *testing.T) {
+	const source = `{"success": true, "data": {"id": "pass_old", "cardTemplateId": "template_123", "cardNumber": "12345", "fullName": "John Doe", "email": "john@example.com", "state": "revoked", "startDate": "2025-01-01T00:00:00Z", "expirationDate": "2025-06-01T00:00:00Z", "metadata": {"building": "A", "cohort": "2025"}}}`
+
+	tests := []struct {
+		name      string
+		status    int
+		overrides *IssueAccessPassParams
+		wantBody  map[string]interface{}
+		wantErr   bool
+	}{
+		{
+			name:   "copies holder and applies overrides",
+			status: http.StatusOK,
+			overrides: &IssueAccessPassParams{
+				CardNumber:     "67890",
+				StartDate:      "2026-01-01T00:00:00Z",
+				ExpirationDate: "2026-06-01T00:00:00Z",
+				Metadata:       map[string]interface{}{"cohort": "2026"},
+			},
+			wantBody: map[string]interface{}{
+				"cardTemplateId": "template_123",
+				"cardNumber":     "67890",
+				"fullName":       "John Doe",
+				"email":          "john@example.com",
+				"startDate":      "2026-01-01T00:00:00Z",
+				"expirationDate": "2026-06-01T00:00:00Z",
+				"metadata":       map[string]interface{}{"building": "A", "cohort": "2026"},
+			},
+		},
+		{
+			name:   "StartAt replaces the copied startDate",
+			status: http.StatusOK,
+			overrides: &IssueAccessPassParams{
+				CardNumber: "67890",
+				StartAt:    time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantBody: map[string]interface{}{
+				"cardTemplateId": "template_123",
+				"cardNumber":     "67890",
+				"fullName":       "John Doe",
+				"email":          "john@example.com",
+				"startDate":      "2025-02-01T00:00:00Z",
+				"expirationDate": "2025-06-01T00:00:00Z",
+				"metadata":       map[string]interface{}{"building": "A", "cohort": "2025"},
+			},
+		},
+		{
+			name:      "source not found",
+			status:    http.StatusNotFound,
+			overrides: &IssueAccessPassParams{CardNumber: "67890"},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issued map[string]interface{}
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/v1/access-passes/pass_old":
+					w.WriteHeader(tt.status)
+					if tt.status == http.StatusNotFound {
+						w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+						return
+					}
+					w.Write([]byte(source))
+				case r.Method == http.MethodPost && r.URL.Path == "/v1/access-passes":
+					json.NewDecoder(r.Body).Decode(&issued)
+					w.WriteHeader(http.StatusCreated)
+					w.Write([]byte(`{"success": true, "data": {"id": "pass_new", "state": "active"}}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+			})
+
+			accessPass, err := client.AccessPasses.Reissue("pass_old", tt.overrides)
+			if tt.wantErr {
+				if !IsNotFound(err) || !strings.Contains(err.Error(), "pass_old") {
+					t.Errorf("Reissue() error = %v, want a not found error naming pass_old", err)
+				}
+				if issued != nil {
+					t.Errorf("issued %v, want no new pass", issued)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Reissue() error = %v", err)
+			}
+			if accessPass.ID != "pass_new" {
+				t.Errorf("ID = %q, want pass_new", accessPass.ID)
+			}
+			if !reflect.DeepEqual(issued, tt.wantBody) {
+				t.Errorf("issued body = %v, want %v", issued, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueDefaultStartImmediate(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		startDate string
+		wantStart string
+		wantErr   bool
+	}{
+		{name: "fills an empty start date", enabled: true},
+		{name: "keeps a given start date", enabled: true, startDate: "2025-11-01T00:00:00Z", wantStart: "2025-11-01T00:00:00Z"},
+		{name: "disabled", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			client := newTestClient(t, &Config{DefaultStartImmediate: tt.enabled}, func(w http.ResponseWriter, r *http.Request) {
+				json.NewDecoder(r.Body).Decode(&body)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			params := validIssueParams()
+			params.StartDate = tt.startDate
+			params.ExpirationDate = "2099-01-01T00:00:00Z"
+			want := params
+
+			before := time.Now().UTC().Truncate(time.Second)
+			_, err := client.AccessPasses.Issue(params)
+			after := time.Now().UTC()
+			if !reflect.DeepEqual(params, want) {
+				t.Errorf("params = %+v after Issue, want them unchanged", params)
+			}
+			if tt.wantErr {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "startDate" {
+					t.Errorf("Issue() error = %v, want a startDate validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			got, _ := body["startDate"].(string)
+			if tt.wantStart != "" {
+				if got != tt.wantStart {
+					t.Errorf("startDate = %q, want %q", got, tt.wantStart)
+				}
+				return
+			}
+			start, err := time.Parse(time.RFC3339, got)
+			if err != nil || start.Before(before) || start.After(after) {
+				t.Errorf("startDate = %q, want the time of the call", got)
+			}
+		})
+	}
+}
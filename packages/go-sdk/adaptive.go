@@ -0,0 +1,264 @@
+package doorpasses
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultAdaptiveMaxConcurrency is the most requests BulkIssue keeps in
+// flight when neither AdaptiveConcurrencyConfig.Max nor
+// Config.BulkConcurrency is set
+const DefaultAdaptiveMaxConcurrency = 20
+
+// AdaptiveConcurrencyConfig tunes how BulkIssue adapts its concurrency to
+// the API's rate limits. It starts at Initial requests in flight, adds one
+// after every IncreaseAfter successful responses, and on a 429 or 503
+// response multiplies the limit by DecreaseFactor and holds new requests
+// back for the response's Retry-After. The throttled item is then sent
+// again, up to MaxRequeues times. Every field is optional.
+type AdaptiveConcurrencyConfig struct {
+	// Initial is the number of requests in flight at the start. Defaults
+	// to 2.
+	Initial int
+
+	// Min is the fewest requests kept in flight after backing off.
+	// Defaults to 1.
+	Min int
+
+	// Max is the most requests kept in flight. Defaults to
+	// Config.BulkConcurrency when set, DefaultAdaptiveMaxConcurrency
+	// otherwise.
+	Max int
+
+	// IncreaseAfter is the number of successful responses after which the
+	// limit grows by one. Defaults to 5.
+	IncreaseAfter int
+
+	// DecreaseFactor is what the limit is multiplied by on a 429 or 503
+	// response, between 0 and 1. Defaults to 0.5.
+	DecreaseFactor float64
+
+	// MaxRequeues is how many times an item answered with 429 or 503 is
+	// sent again before it is recorded as failed. Defaults to 3; negative
+	// records it as failed at once.
+	MaxRequeues int
+}
+
+// AIMD controller defaults
+const (
+	defaultAdaptiveInitial        = 2
+	defaultAdaptiveIncreaseAfter  = 5
+	defaultAdaptiveDecreaseFactor = 0.5
+	defaultAdaptiveMaxRequeues    = 3
+)
+
+// adaptiveLimiter bounds the requests a bulk operation keeps in flight with
+// an additive-increase, multiplicative-decrease limit
+type adaptiveLimiter struct {
+	min, max       int
+	increaseAfter  int
+	decreaseFactor float64
+	maxRequeues    int
+	now            func() time.Time
+
+	mu        sync.Mutex
+	limit     int
+	inFlight  int
+	successes int
+
+	// epoch counts the decreases so far. A throttled response only
+	// decreases the limit when its request was started in the current
+	// epoch, so a burst of 429s to requests sent together backs off once.
+	epoch int
+
+	// pausedUntil holds new requests back until the Retry-After of the
+	// latest throttled response has passed
+	pausedUntil time.Time
+
+	// changed is closed and replaced whenever a waiting acquire may be able
+	// to proceed
+	changed chan struct{}
+}
+
+// newAdaptiveLimiter returns a limiter for config with its defaults
+// applied, using fallbackMax when config doesn't set Max
+func newAdaptiveLimiter(config *AdaptiveConcurrencyConfig, fallbackMax int) *adaptiveLimiter {
+	if config == nil {
+		config = &AdaptiveConcurrencyConfig{}
+	}
+	l := &adaptiveLimiter{
+		min:            config.Min,
+		max:            config.Max,
+		increaseAfter:  config.IncreaseAfter,
+		decreaseFactor: config.DecreaseFactor,
+		maxRequeues:    config.MaxRequeues,
+		limit:          config.Initial,
+		now:            time.Now,
+		changed:        make(chan struct{}),
+	}
+	if l.max <= 0 {
+		l.max = fallbackMax
+	}
+	if l.max <= 0 {
+		l.max = DefaultAdaptiveMaxConcurrency
+	}
+	if l.min <= 0 {
+		l.min = 1
+	}
+	l.min = min(l.min, l.max)
+	if l.limit <= 0 {
+		l.limit = defaultAdaptiveInitial
+	}
+	l.limit = min(max(l.limit, l.min), l.max)
+	if l.increaseAfter <= 0 {
+		l.increaseAfter = defaultAdaptiveIncreaseAfter
+	}
+	if l.decreaseFactor <= 0 || l.decreaseFactor >= 1 {
+		l.decreaseFactor = defaultAdaptiveDecreaseFactor
+	}
+	switch {
+	case l.maxRequeues == 0:
+		l.maxRequeues = defaultAdaptiveMaxRequeues
+	case l.maxRequeues < 0:
+		l.maxRequeues = 0
+	}
+	return l
+}
+
+// acquire waits until a request may start, returning the epoch it starts
+// in, or ctx.Err() once ctx is done. Every successful acquire must be
+// paired with release.
+func (l *adaptiveLimiter) acquire(ctx context.Context) (int, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		l.mu.Lock()
+		wait := l.pausedUntil.Sub(l.now())
+		if wait <= 0 && l.inFlight < l.limit {
+			l.inFlight++
+			epoch := l.epoch
+			l.mu.Unlock()
+			return epoch, nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		if err := waitChange(ctx, changed, wait); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// waitChange waits until changed is closed, wait has passed when positive,
+// or ctx is done, in which case it returns ctx.Err()
+func waitChange(ctx context.Context, changed <-chan struct{}, wait time.Duration) error {
+	var timeout <-chan time.Time
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-changed:
+	case <-timeout:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// release frees the slot taken by acquire
+func (l *adaptiveLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	l.notify()
+}
+
+// observe adjusts the limit to a response to a request started in epoch
+func (l *adaptiveLimiter) observe(epoch int, resp *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		now := l.now()
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"), now); retryAfter > 0 {
+			if until := now.Add(retryAfter); until.After(l.pausedUntil) {
+				l.pausedUntil = until
+			}
+		}
+		if epoch == l.epoch {
+			l.limit = max(int(float64(l.limit)*l.decreaseFactor), l.min)
+			l.successes = 0
+			l.epoch++
+		}
+	default:
+		if resp.StatusCode >= 500 {
+			return
+		}
+		l.successes++
+		if l.successes >= l.increaseAfter && l.limit < l.max {
+			l.limit++
+			l.successes = 0
+			l.notify()
+		}
+	}
+}
+
+// currentLimit returns the number of requests currently allowed in flight
+func (l *adaptiveLimiter) currentLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// notify wakes the waiting acquires. l.mu must be held.
+func (l *adaptiveLimiter) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// forEachAdaptively calls fn for every index below n, keeping as many calls
+// running at once as limiter allows. fn is given the options that report
+// its responses to limiter, and returns true when it was throttled; the
+// index is then sent back through limiter, which waits out the Retry-After,
+// up to limiter.maxRequeues times. Like forEachConcurrently, it stops
+// handing out indexes when ctx is done and returns how many were started
+// along with ctx.Err(). A throttled index that can't be sent again because
+// ctx is done keeps the outcome of its last call.
+func forEachAdaptively(ctx context.Context, n int, limiter *adaptiveLimiter, fn func(i int, opt RequestOption) (throttled bool)) (int, error) {
+	call := func(i, epoch int) bool {
+		defer limiter.release()
+		return fn(i, withResponseObserver(func(resp *http.Response) {
+			limiter.observe(epoch, resp)
+		}))
+	}
+
+	var wg sync.WaitGroup
+	started := 0
+	var err error
+	for ; started < n; started++ {
+		var epoch int
+		if epoch, err = limiter.acquire(ctx); err != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i, epoch int) {
+			defer wg.Done()
+			for requeues := 0; call(i, epoch) && requeues < limiter.maxRequeues; requeues++ {
+				var err error
+				if epoch, err = limiter.acquire(ctx); err != nil {
+					return
+				}
+			}
+		}(started, epoch)
+	}
+	wg.Wait()
+
+	return started, err
+}
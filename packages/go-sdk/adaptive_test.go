@@ -0,0 +1,223 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// limiterResponse returns a response with the given status and Retry-After
+func limiterResponse(statusCode int, retryAfter string) *http.Response {
+	resp := &http.Response{StatusCode: statusCode, Header: make(http.Header)}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return resp
+}
+
+func TestAdaptiveLimiterDefaults(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *AdaptiveConcurrencyConfig
+		fallbackMax int
+		wantLimit   int
+		wantMax     int
+	}{
+		{name: "defaults", wantLimit: 2, wantMax: DefaultAdaptiveMaxConcurrency},
+		{name: "BulkConcurrency caps", fallbackMax: 8, wantLimit: 2, wantMax: 8},
+		{name: "initial above max", config: &AdaptiveConcurrencyConfig{Initial: 10, Max: 4}, fallbackMax: 8, wantLimit: 4, wantMax: 4},
+		{name: "max of one", fallbackMax: 1, wantLimit: 1, wantMax: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newAdaptiveLimiter(tt.config, tt.fallbackMax)
+			if l.limit != tt.wantLimit || l.max != tt.wantMax {
+				t.Errorf("limit, max = %d, %d, want %d, %d", l.limit, l.max, tt.wantLimit, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimiterAIMD(t *testing.T) {
+	l := newAdaptiveLimiter(&AdaptiveConcurrencyConfig{Initial: 2, Max: 8, IncreaseAfter: 2}, 0)
+	ok := limiterResponse(http.StatusCreated, "")
+
+	// Additive increase, one per IncreaseAfter successes, up to Max
+	for i := 0; i < 20; i++ {
+		l.observe(0, ok)
+	}
+	if got := l.currentLimit(); got != 8 {
+		t.Fatalf("limit after 20 successes = %d, want 8", got)
+	}
+
+	// Server errors other than 503 say nothing about capacity
+	l.observe(0, limiterResponse(http.StatusInternalServerError, ""))
+	if got := l.currentLimit(); got != 8 {
+		t.Errorf("limit after a 500 = %d, want 8", got)
+	}
+
+	// Multiplicative decrease, once for every request of the epoch
+	l.observe(0, limiterResponse(http.StatusTooManyRequests, ""))
+	l.observe(0, limiterResponse(http.StatusTooManyRequests, ""))
+	if got := l.currentLimit(); got != 4 {
+		t.Errorf("limit after a burst of 429s = %d, want 4", got)
+	}
+	l.observe(1, limiterResponse(http.StatusServiceUnavailable, ""))
+	if got := l.currentLimit(); got != 2 {
+		t.Errorf("limit after a 503 in the next epoch = %d, want 2", got)
+	}
+	for epoch := 2; epoch < 5; epoch++ {
+		l.observe(epoch, limiterResponse(http.StatusTooManyRequests, ""))
+	}
+	if got := l.currentLimit(); got != 1 {
+		t.Errorf("limit after repeated 429s = %d, want Min 1", got)
+	}
+}
+
+func TestAdaptiveLimiterRetryAfter(t *testing.T) {
+	now := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	l := newAdaptiveLimiter(&AdaptiveConcurrencyConfig{Initial: 4}, 0)
+	l.now = func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+
+	l.observe(0, limiterResponse(http.StatusTooManyRequests, "2"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("acquire() during Retry-After error = %v, want context.DeadlineExceeded", err)
+	}
+
+	mu.Lock()
+	now = now.Add(2 * time.Second)
+	mu.Unlock()
+	epoch, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() after Retry-After error = %v", err)
+	}
+	if epoch != 1 {
+		t.Errorf("epoch = %d, want 1", epoch)
+	}
+	l.release()
+}
+
+func TestAccessPassesBulkIssueAdaptive(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight, requests := 0, 0, 0
+	client := newTestClient(t, &Config{
+		MaxRetries:              -1,
+		BulkAdaptiveConcurrency: &AdaptiveConcurrencyConfig{Initial: 1, Max: 4, IncreaseAfter: 2},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		requests++
+		throttle := requests == 12
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		time.Sleep(5 * time.Millisecond)
+		if throttle {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"success": false, "error": {"code": "RATE_LIMIT_EXCEEDED", "message": "Too many requests"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_1"}}`))
+	})
+
+	var limiter *adaptiveLimiter
+	client.AccessPasses.bulkLimiterCreated = func(l *adaptiveLimiter) { limiter = l }
+
+	params := make([]IssueAccessPassParams, 40)
+	for i := range params {
+		params[i] = validIssueParams()
+	}
+	result, err := client.AccessPasses.BulkIssue(params)
+	if err != nil {
+		t.Fatalf("BulkIssue() error = %v", err)
+	}
+
+	if maxInFlight < 2 {
+		t.Errorf("%d requests in flight at most, want the concurrency to grow past Initial", maxInFlight)
+	}
+	if maxInFlight > 4 {
+		t.Errorf("%d requests in flight, want at most Max 4", maxInFlight)
+	}
+	// Without an idempotency key Issue isn't retried, so the throttled item
+	// must have been requeued by BulkIssue itself
+	if failed := result.Failed(); len(failed) != 0 {
+		t.Errorf("Failed() = %+v, want every item issued", failed)
+	}
+	if len(result.Succeeded()) != 40 || requests != 41 {
+		t.Errorf("%d items issued with %d requests, want 40 with 41", len(result.Succeeded()), requests)
+	}
+	if limiter.epoch != 1 {
+		t.Errorf("limiter backed off %d times, want once for the 429", limiter.epoch)
+	}
+}
+
+func TestAccessPassesBulkIssueRequeueBound(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, &Config{
+		MaxRetries:              -1,
+		BulkAdaptiveConcurrency: &AdaptiveConcurrencyConfig{MaxRequeues: 2},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"success": false, "error": {"code": "INTERNAL_ERROR", "message": "Unavailable"}}`))
+	})
+
+	result, err := client.AccessPasses.BulkIssue([]IssueAccessPassParams{validIssueParams()})
+	if err != nil {
+		t.Fatalf("BulkIssue() error = %v", err)
+	}
+	if failed := result.Failed(); len(failed) != 1 || !hasStatus(failed[0].Err, http.StatusServiceUnavailable) {
+		t.Errorf("Failed() = %+v, want the item failed with 503", failed)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("%d requests, want 1 plus 2 requeues", got)
+	}
+}
+
+func TestAccessPassesBulkIssueFixedConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	client := newTestClient(t, &Config{BulkConcurrency: 3, DisableAdaptiveBulkConcurrency: true}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_1"}}`))
+	})
+
+	params := make([]IssueAccessPassParams, 9)
+	for i := range params {
+		params[i] = validIssueParams()
+	}
+	if _, err := client.AccessPasses.BulkIssue(params); err != nil {
+		t.Fatalf("BulkIssue() error = %v", err)
+	}
+	if maxInFlight != 3 {
+		t.Errorf("%d requests in flight at most, want a fixed 3", maxInFlight)
+	}
+}
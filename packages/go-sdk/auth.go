@@ -2,6 +2,7 @@ package doorpasses
 
 import (
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -18,6 +19,7 @@ func encodePayload(payload interface{}) (string, error) {
 
 // createSignature creates a signature for a payload using the shared secret
 // Uses SHA256(shared_secret + base64_encoded_payload).hexdigest()
+// The signature carries no timestamp, so it doesn't depend on the local clock.
 func createSignature(sharedSecret, encodedPayload string) string {
 	message := sharedSecret + encodedPayload
 	hash := sha256.Sum256([]byte(message))
@@ -27,55 +29,60 @@ func createSignature(sharedSecret, encodedPayload string) string {
 // verifySignature verifies a signature matches the expected value
 func verifySignature(sharedSecret, encodedPayload, signature string) bool {
 	expectedSignature := createSignature(sharedSecret, encodedPayload)
-	return expectedSignature == signature
+	return subtle.ConstantTimeCompare([]byte(expectedSignature), []byte(signature)) == 1
 }
 
 // createAuthHeaders creates authentication headers for API requests
 func createAuthHeaders(accountID, sharedSecret string, payload interface{}) (map[string]string, error) {
-	var encodedPayload string
-	var err error
-
-	if payload != nil {
-		encodedPayload, err = encodePayload(payload)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		// Default payload when no body is provided
-		encodedPayload, err = encodePayload(map[string]string{"id": "0"})
-		if err != nil {
-			return nil, err
-		}
-	}
-
-	signature := createSignature(sharedSecret, encodedPayload)
-
-	return map[string]string{
-		"X-ACCT-ID":      accountID,
-		"X-PAYLOAD-SIG":  signature,
-		"Content-Type":   "application/json",
-	}, nil
+	headers, _, err := signPayload(SHA256Signer{AccountID: accountID, SharedSecret: sharedSecret}, payload)
+	return headers, err
 }
 
 // createGetAuthHeaders creates authentication headers for GET requests
 func createGetAuthHeaders(accountID, sharedSecret string, sigPayload map[string]interface{}) (map[string]string, string, error) {
-	payload := sigPayload
+	return signQuery(SHA256Signer{AccountID: accountID, SharedSecret: sharedSecret}, sigPayload)
+}
+
+// defaultPayload is signed when a request has nothing else to sign
+var defaultPayload = map[string]string{"id": "0"}
+
+// signPayload encodes payload and signs it with signer, returning the
+// request headers and the encoded payload. A nil payload is replaced by
+// defaultPayload.
+func signPayload(signer Signer, payload interface{}) (map[string]string, string, error) {
 	if payload == nil {
-		payload = map[string]interface{}{"id": "0"}
+		payload = defaultPayload
 	}
 
-	encodedPayload, err := encodePayload(payload)
+	jsonBytes, err := json.Marshal(payload)
 	if err != nil {
-		return nil, "", err
+		return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
 	}
+	return signJSON(signer, jsonBytes)
+}
 
-	signature := createSignature(sharedSecret, encodedPayload)
-
-	headers := map[string]string{
-		"X-ACCT-ID":      accountID,
-		"X-PAYLOAD-SIG":  signature,
-		"Content-Type":   "application/json",
+// signJSON signs an already marshalled payload with signer, returning the
+// request headers and the encoded payload
+func signJSON(signer Signer, jsonBytes []byte) (map[string]string, string, error) {
+	encodedPayload := base64.StdEncoding.EncodeToString(jsonBytes)
+	signed, err := signer.Sign(encodedPayload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to sign request: %w", err)
 	}
 
+	// Copy so a signer may return a shared map
+	headers := make(map[string]string, len(signed)+1)
+	for key, value := range signed {
+		headers[key] = value
+	}
+	headers["Content-Type"] = "application/json"
 	return headers, encodedPayload, nil
 }
+
+// signQuery signs the sig_payload of a GET request
+func signQuery(signer Signer, sigPayload map[string]interface{}) (map[string]string, string, error) {
+	if sigPayload == nil {
+		return signPayload(signer, nil)
+	}
+	return signPayload(signer, sigPayload)
+}
@@ -0,0 +1,405 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// DefaultBulkConcurrency is the number of requests a bulk operation keeps in
+// flight when Config.BulkConcurrency is unset
+const DefaultBulkConcurrency = 5
+
+// BulkIssueItem is the outcome of issuing a single access pass in a bulk
+// operation
+type BulkIssueItem struct {
+	// Index is the position of the params in the BulkIssue input
+	Index int
+
+	// AccessPass is the issued pass, nil when Err is set
+	AccessPass *AccessPass
+
+	// Created reports that this call created the pass, as
+	// AccessPass.NewlyCreated does
+	Created bool
+
+	// Replayed reports that the item's IdempotencyKey was used by an
+	// earlier run, so the API returned the pass that run created, as
+	// AccessPass.Replayed does. An item that is neither Created nor
+	// Replayed got a pass that already existed for its card number.
+	Replayed bool
+
+	// Err is why the pass could not be issued
+	Err error
+}
+
+// BulkIssueResult reports the outcome of every item of a BulkIssue call, in
+// input order
+type BulkIssueResult struct {
+	Items []BulkIssueItem
+}
+
+// Succeeded returns the items that were issued
+func (r *BulkIssueResult) Succeeded() []BulkIssueItem {
+	var items []BulkIssueItem
+	for _, item := range r.Items {
+		if item.Err == nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Failed returns the items that could not be issued
+func (r *BulkIssueResult) Failed() []BulkIssueItem {
+	var items []BulkIssueItem
+	for _, item := range r.Items {
+		if item.Err != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Created returns the items whose pass was created by this call
+func (r *BulkIssueResult) Created() []BulkIssueItem {
+	var items []BulkIssueItem
+	for _, item := range r.Items {
+		if item.Created {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Replayed returns the items whose pass was created by an earlier run with
+// the same idempotency key
+func (r *BulkIssueResult) Replayed() []BulkIssueItem {
+	var items []BulkIssueItem
+	for _, item := range r.Items {
+		if item.Replayed {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Err returns a *BulkError describing the items that could not be issued,
+// or nil if every item was issued
+func (r *BulkIssueResult) Err() error {
+	var errs []error
+	for _, item := range r.Failed() {
+		errs = append(errs, fmt.Errorf("item %d: %w", item.Index, item.Err))
+	}
+	return newBulkError("issue", len(r.Items), errs)
+}
+
+// BulkIssue issues several access passes concurrently. A failing item does
+// not stop the others; check each item's Err in the result. Give every item
+// its own IdempotencyKey to make the whole batch safe to run again: items
+// issued by an earlier run then come back Replayed instead of Created.
+//
+// The number of requests in flight adapts to the API: it starts low, grows
+// while requests succeed and is cut back on rate limits, waiting out their
+// Retry-After, as tuned by Config.BulkAdaptiveConcurrency. An item answered
+// with 429 or 503 is sent again once the Retry-After has passed, and only
+// recorded as failed after AdaptiveConcurrencyConfig.MaxRequeues attempts.
+// Each call starts over from the initial concurrency.
+func (a *AccessPasses) BulkIssue(params []IssueAccessPassParams, opts ...RequestOption) (*BulkIssueResult, error) {
+	return a.BulkIssueWithContext(context.Background(), params, opts...)
+}
+
+// BulkIssueWithContext issues several access passes concurrently, aborting
+// if ctx is done. When ctx is cancelled part way through, the partial result
+// is returned along with ctx.Err(), and items that were never sent carry
+// ctx.Err() as their error.
+func (a *AccessPasses) BulkIssueWithContext(ctx context.Context, params []IssueAccessPassParams, opts ...RequestOption) (*BulkIssueResult, error) {
+	result := &BulkIssueResult{Items: make([]BulkIssueItem, len(params))}
+
+	issue := func(i int, opts []RequestOption) (throttled bool) {
+		item := BulkIssueItem{Index: i}
+		item.AccessPass, item.Err = a.IssueWithContext(ctx, params[i], opts...)
+		if item.AccessPass != nil {
+			item.Created, item.Replayed = item.AccessPass.NewlyCreated, item.AccessPass.Replayed
+		}
+		result.Items[i] = item
+		return hasStatus(item.Err, http.StatusTooManyRequests) || hasStatus(item.Err, http.StatusServiceUnavailable)
+	}
+
+	var started int
+	var err error
+	if a.fixedBulkIssue {
+		started, err = forEachConcurrently(ctx, len(params), a.bulkConcurrency, func(i int) {
+			issue(i, opts)
+		})
+	} else {
+		limiter := newAdaptiveLimiter(a.bulkAdaptive, a.bulkAdaptiveMax)
+		if a.bulkLimiterCreated != nil {
+			a.bulkLimiterCreated(limiter)
+		}
+		started, err = forEachAdaptively(ctx, len(params), limiter, func(i int, observe RequestOption) bool {
+			// Copy opts so the items never share a backing array
+			return issue(i, append(opts[:len(opts):len(opts)], observe))
+		})
+	}
+	for i := started; i < len(params); i++ {
+		result.Items[i] = BulkIssueItem{Index: i, Err: err}
+	}
+
+	return result, err
+}
+
+// BulkRevokeItem is the outcome of revoking a single access pass in a bulk
+// operation
+type BulkRevokeItem struct {
+	// ID is the access pass ID
+	ID string
+
+	// AccessPass is the revoked pass, nil when Skipped or Err is set
+	AccessPass *AccessPass
+
+	// Skipped reports that the pass was already revoked
+	Skipped bool
+
+	// Err is why the pass could not be revoked
+	Err error
+}
+
+// BulkRevokeResult reports the outcome of every distinct ID of a BulkRevoke
+// call, in the order each ID first appeared in the input
+type BulkRevokeResult struct {
+	Items []BulkRevokeItem
+}
+
+// Succeeded returns the items that were revoked by this call
+func (r *BulkRevokeResult) Succeeded() []BulkRevokeItem {
+	var items []BulkRevokeItem
+	for _, item := range r.Items {
+		if item.Err == nil && !item.Skipped {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Skipped returns the items that were already revoked
+func (r *BulkRevokeResult) Skipped() []BulkRevokeItem {
+	var items []BulkRevokeItem
+	for _, item := range r.Items {
+		if item.Skipped {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Failed returns the items that could not be revoked
+func (r *BulkRevokeResult) Failed() []BulkRevokeItem {
+	var items []BulkRevokeItem
+	for _, item := range r.Items {
+		if item.Err != nil {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Err returns a *BulkError describing the items that could not be revoked,
+// or nil if none failed. Skipped items are not failures.
+func (r *BulkRevokeResult) Err() error {
+	var errs []error
+	for _, item := range r.Failed() {
+		errs = append(errs, fmt.Errorf("%s: %w", item.ID, item.Err))
+	}
+	return newBulkError("revoke", len(r.Items), errs)
+}
+
+// BulkRevoke revokes several access passes concurrently. Duplicate IDs are
+// revoked once, and passes that are already revoked are reported as skipped
+// rather than failed.
+func (a *AccessPasses) BulkRevoke(ids []string, opts ...RequestOption) (*BulkRevokeResult, error) {
+	return a.BulkRevokeWithContext(context.Background(), ids, "", opts...)
+}
+
+// BulkRevokeWithContext revokes several access passes concurrently,
+// recording the optional reason on each, and aborting if ctx is done. When
+// ctx is cancelled part way through, the partial result is returned along
+// with ctx.Err(), and items that were never sent carry ctx.Err() as their
+// error.
+func (a *AccessPasses) BulkRevokeWithContext(ctx context.Context, ids []string, reason string, opts ...RequestOption) (*BulkRevokeResult, error) {
+	ids = dedupe(ids)
+	result := &BulkRevokeResult{Items: make([]BulkRevokeItem, len(ids))}
+
+	started, err := forEachConcurrently(ctx, len(ids), a.bulkConcurrency, func(i int) {
+		item := BulkRevokeItem{ID: ids[i]}
+		item.AccessPass, item.Err = a.RevokeWithContext(ctx, ids[i], reason, opts...)
+		if errors.Is(item.Err, ErrPassAlreadyRevoked) {
+			item.Skipped, item.Err = true, nil
+		}
+		result.Items[i] = item
+	})
+	for i := started; i < len(ids); i++ {
+		result.Items[i] = BulkRevokeItem{ID: ids[i], Err: err}
+	}
+
+	return result, err
+}
+
+// BulkError reports the items of a bulk operation that failed. It unwraps to
+// each item's error, so errors.Is and errors.As see through it, e.g. to find
+// an *APIError or ErrPassAlreadyRevoked.
+type BulkError struct {
+	// Operation is the bulk operation, e.g. "issue" or "revoke"
+	Operation string
+
+	// Total is the number of items in the operation
+	Total int
+
+	// Errors holds the error of every failed item, prefixed with the item's
+	// index or ID
+	Errors []error
+}
+
+// newBulkError returns a *BulkError for errs, or nil if there are none
+func newBulkError(operation string, total int, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &BulkError{Operation: operation, Total: total, Errors: errs}
+}
+
+func (e *BulkError) Error() string {
+	return fmt.Sprintf("bulk %s: %d of %d failed", e.Operation, len(e.Errors), e.Total)
+}
+
+// Unwrap returns the error of every failed item
+func (e *BulkError) Unwrap() []error {
+	return e.Errors
+}
+
+// GetManyError is returned by GetMany when some access passes could not be
+// fetched for a reason other than not existing
+type GetManyError struct {
+	// Errors maps each ID that failed to why
+	Errors map[string]error
+}
+
+func (e *GetManyError) Error() string {
+	return fmt.Sprintf("failed to get %d access passes", len(e.Errors))
+}
+
+// Unwrap returns the error of every failed ID, ordered by ID
+func (e *GetManyError) Unwrap() []error {
+	ids := make([]string, 0, len(e.Errors))
+	for id := range e.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = e.Errors[id]
+	}
+	return errs
+}
+
+// GetMany fetches several access passes concurrently, returning them by ID.
+// IDs that don't exist are left out of the map. When other IDs fail, the
+// passes that were fetched are returned along with a *GetManyError.
+func (a *AccessPasses) GetMany(ids []string, opts ...RequestOption) (map[string]*AccessPass, error) {
+	return a.GetManyWithContext(context.Background(), ids, opts...)
+}
+
+// GetManyWithContext fetches several access passes concurrently, aborting if
+// ctx is done. When ctx is cancelled part way through, the passes fetched so
+// far are returned along with ctx.Err().
+func (a *AccessPasses) GetManyWithContext(ctx context.Context, ids []string, opts ...RequestOption) (map[string]*AccessPass, error) {
+	opts = withOperation(opts, "AccessPasses.GetMany")
+	ids = dedupe(ids)
+
+	var mu sync.Mutex
+	passes := make(map[string]*AccessPass, len(ids))
+	failed := make(map[string]error)
+	_, err := forEachConcurrently(ctx, len(ids), a.bulkConcurrency, func(i int) {
+		accessPass, err := a.GetWithContext(ctx, ids[i], opts...)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case err == nil:
+			passes[ids[i]] = accessPass
+		case !IsNotFound(err):
+			failed[ids[i]] = err
+		}
+	})
+	if err != nil {
+		return passes, err
+	}
+	if err := ctx.Err(); err != nil {
+		// Cancelled after the last ID was handed out
+		return passes, err
+	}
+	if len(failed) > 0 {
+		return passes, &GetManyError{Errors: failed}
+	}
+	return passes, nil
+}
+
+// dedupe returns ids without repeats, keeping the first occurrence of each
+func dedupe(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// forEachConcurrently calls fn for every index below n, with at most
+// concurrency calls running at once. It stops handing out indexes when ctx
+// is done and returns how many were started along with ctx.Err(). Indexes
+// are started in order, so every index from the returned count onwards was
+// skipped.
+func forEachConcurrently(ctx context.Context, n, concurrency int, fn func(i int)) (int, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	started := 0
+	var err error
+dispatch:
+	for ; started < n; started++ {
+		// Check ctx first so a cancelled context never wins a race against
+		// an idle worker
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		select {
+		case jobs <- started:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	return started, err
+}
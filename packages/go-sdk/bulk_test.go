@@ -0,0 +1,345 @@
+package doorpasses
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAccessPassesBulkIssue(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	client := newTestClient(t, &Config{BulkConcurrency: 2, MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(10 * time.Millisecond)
+
+		var params IssueAccessPassParams
+		json.NewDecoder(r.Body).Decode(&params)
+		if params.CardNumber == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"success": false, "error": {"code": "VALIDATION_ERROR", "message": "invalid card number"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_` + params.CardNumber + `"}}`))
+	})
+
+	var params []IssueAccessPassParams
+	for _, cardNumber := range []string{"1", "bad", "3", "4", "5"} {
+		p := validIssueParams()
+		p.CardNumber = cardNumber
+		params = append(params, p)
+	}
+	params[3].CardTemplateID = ""
+
+	result, err := client.AccessPasses.BulkIssue(params)
+	if err != nil {
+		t.Fatalf("BulkIssue() error = %v", err)
+	}
+	if len(result.Items) != len(params) {
+		t.Fatalf("BulkIssue() returned %d items, want %d", len(result.Items), len(params))
+	}
+
+	for i, item := range result.Items {
+		if item.Index != i {
+			t.Errorf("Items[%d].Index = %d", i, item.Index)
+		}
+	}
+	if got := result.Items[0].AccessPass; got == nil || got.ID != "pass_1" {
+		t.Errorf("Items[0].AccessPass = %+v, want pass_1", got)
+	}
+	if !IsValidation(result.Items[1].Err) {
+		t.Errorf("Items[1].Err = %v, want API validation error", result.Items[1].Err)
+	}
+	var validationErr *ValidationError
+	if !errors.As(result.Items[3].Err, &validationErr) {
+		t.Errorf("Items[3].Err = %v, want *ValidationError", result.Items[3].Err)
+	}
+	if got := len(result.Succeeded()); got != 3 {
+		t.Errorf("len(Succeeded()) = %d, want 3", got)
+	}
+	if got := len(result.Failed()); got != 2 {
+		t.Errorf("len(Failed()) = %d, want 2", got)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("%d requests in flight, want at most 2", maxInFlight)
+	}
+
+	var bulkErr *BulkError
+	if err := result.Err(); !errors.As(err, &bulkErr) {
+		t.Fatalf("Err() = %v, want *BulkError", err)
+	}
+	if got := bulkErr.Error(); got != "bulk issue: 2 of 5 failed" {
+		t.Errorf("Error() = %q, want %q", got, "bulk issue: 2 of 5 failed")
+	}
+	var apiErr *APIError
+	if !errors.As(bulkErr, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("errors.As(BulkError, *APIError) = %v, want the 400", apiErr)
+	}
+	if !errors.As(bulkErr, &validationErr) {
+		t.Error("errors.As(BulkError, *ValidationError) = false, want true")
+	}
+}
+
+func TestAccessPassesBulkIssueRerun(t *testing.T) {
+	var mu sync.Mutex
+	issued := make(map[string]bool)
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		mu.Lock()
+		replayed := issued[key]
+		issued[key] = true
+		mu.Unlock()
+
+		if replayed {
+			w.Header().Set(idempotencyReplayedHeader, "true")
+		} else {
+			w.WriteHeader(http.StatusCreated)
+		}
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_` + key + `"}}`))
+	})
+
+	batch := func(keys ...string) []IssueAccessPassParams {
+		var params []IssueAccessPassParams
+		for _, key := range keys {
+			p := validIssueParams()
+			p.IdempotencyKey = key
+			params = append(params, p)
+		}
+		return params
+	}
+	if _, err := client.AccessPasses.BulkIssue(batch("row_1", "row_2")); err != nil {
+		t.Fatalf("first BulkIssue() error = %v", err)
+	}
+
+	result, err := client.AccessPasses.BulkIssue(batch("row_1", "row_2", "row_3"))
+	if err != nil {
+		t.Fatalf("second BulkIssue() error = %v", err)
+	}
+	for i, want := range []struct{ created, replayed bool }{{false, true}, {false, true}, {true, false}} {
+		item := result.Items[i]
+		if item.Created != want.created || item.Replayed != want.replayed {
+			t.Errorf("Items[%d] Created = %v, Replayed = %v, want %v, %v", i, item.Created, item.Replayed, want.created, want.replayed)
+		}
+	}
+	if len(result.Created()) != 1 || len(result.Replayed()) != 2 {
+		t.Errorf("Created() = %d items, Replayed() = %d items, want 1 and 2", len(result.Created()), len(result.Replayed()))
+	}
+}
+
+func TestAccessPassesBulkIssueCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := 0
+	client := newTestClient(t, &Config{BulkConcurrency: 1}, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	params := []IssueAccessPassParams{validIssueParams(), validIssueParams(), validIssueParams()}
+	result, err := client.AccessPasses.BulkIssueWithContext(ctx, params)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("BulkIssueWithContext() error = %v, want context.Canceled", err)
+	}
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+	if len(result.Items) != len(params) {
+		t.Fatalf("BulkIssueWithContext() returned %d items, want %d", len(result.Items), len(params))
+	}
+	for _, item := range result.Items[1:] {
+		if !errors.Is(item.Err, context.Canceled) {
+			t.Errorf("Items[%d].Err = %v, want context.Canceled", item.Index, item.Err)
+		}
+	}
+}
+
+func TestAccessPassesBulkRevoke(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+
+	client := newTestClient(t, &Config{BulkConcurrency: 2, MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/access-passes/"), "/revoke")
+		mu.Lock()
+		calls[id]++
+		mu.Unlock()
+
+		switch id {
+		case "pass_revoked":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"success": false, "error": {"code": "ACCESS_PASS_REVOKED", "message": "already revoked"}}`))
+		case "pass_missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"code": "ACCESS_PASS_NOT_FOUND", "message": "not found"}}`))
+		default:
+			w.Write([]byte(`{"success": true, "data": {"id": "` + id + `", "state": "revoked"}}`))
+		}
+	})
+
+	ids := []string{"pass_1", "pass_revoked", "pass_1", "pass_missing", "pass_2"}
+	result, err := client.AccessPasses.BulkRevoke(ids)
+	if err != nil {
+		t.Fatalf("BulkRevoke() error = %v", err)
+	}
+
+	wantIDs := []string{"pass_1", "pass_revoked", "pass_missing", "pass_2"}
+	if len(result.Items) != len(wantIDs) {
+		t.Fatalf("BulkRevoke() returned %d items, want %d", len(result.Items), len(wantIDs))
+	}
+	for i, item := range result.Items {
+		if item.ID != wantIDs[i] {
+			t.Errorf("Items[%d].ID = %q, want %q", i, item.ID, wantIDs[i])
+		}
+	}
+	if calls["pass_1"] != 1 {
+		t.Errorf("pass_1 revoked %d times, want 1", calls["pass_1"])
+	}
+	if !result.Items[1].Skipped || result.Items[1].Err != nil {
+		t.Errorf("Items[1] = %+v, want skipped without error", result.Items[1])
+	}
+	if !IsNotFound(result.Items[2].Err) {
+		t.Errorf("Items[2].Err = %v, want not found", result.Items[2].Err)
+	}
+	if got := result.Items[3].AccessPass; got == nil || got.State != AccessPassStateRevoked {
+		t.Errorf("Items[3].AccessPass = %+v, want revoked pass", got)
+	}
+	if got := len(result.Succeeded()); got != 2 {
+		t.Errorf("len(Succeeded()) = %d, want 2", got)
+	}
+	if got := len(result.Skipped()); got != 1 {
+		t.Errorf("len(Skipped()) = %d, want 1", got)
+	}
+	if got := len(result.Failed()); got != 1 {
+		t.Errorf("len(Failed()) = %d, want 1", got)
+	}
+
+	err = result.Err()
+	if err == nil || err.Error() != "bulk revoke: 1 of 4 failed" {
+		t.Errorf("Err() = %v, want bulk revoke: 1 of 4 failed", err)
+	}
+	if !IsNotFound(err) {
+		t.Errorf("IsNotFound(Err()) = false, want true")
+	}
+
+	result, err = client.AccessPasses.BulkRevoke([]string{"pass_1", "pass_revoked"})
+	if err != nil {
+		t.Fatalf("BulkRevoke() error = %v", err)
+	}
+	if err := result.Err(); err != nil {
+		t.Errorf("Err() with only skipped items = %v, want nil", err)
+	}
+}
+
+func TestAccessPassesBulkRevokeCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := 0
+	client := newTestClient(t, &Config{BulkConcurrency: 1}, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_1", "state": "revoked"}}`))
+	})
+
+	result, err := client.AccessPasses.BulkRevokeWithContext(ctx, []string{"pass_1", "pass_2", "pass_3"}, "department closed")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("BulkRevokeWithContext() error = %v, want context.Canceled", err)
+	}
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+	for _, item := range result.Items[1:] {
+		if !errors.Is(item.Err, context.Canceled) {
+			t.Errorf("Items[%q].Err = %v, want context.Canceled", item.ID, item.Err)
+		}
+	}
+}
+
+func TestAccessPassesGetMany(t *testing.T) {
+	var mu sync.Mutex
+	requested := make(map[string]int)
+	client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/v1/access-passes/")
+		mu.Lock()
+		requested[id]++
+		mu.Unlock()
+
+		switch id {
+		case "pass_missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+		case "pass_broken":
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"success": false, "error": "internal error"}`))
+		default:
+			w.Write([]byte(`{"success": true, "data": {"id": "` + id + `"}}`))
+		}
+	})
+
+	passes, err := client.AccessPasses.GetMany([]string{"pass_1", "pass_2", "pass_missing", "pass_1", "pass_broken"})
+
+	var getErr *GetManyError
+	if !errors.As(err, &getErr) {
+		t.Fatalf("GetMany() error = %v, want GetManyError", err)
+	}
+	if len(getErr.Errors) != 1 || getErr.Errors["pass_broken"] == nil {
+		t.Errorf("GetManyError.Errors = %v, want only pass_broken", getErr.Errors)
+	}
+	if !hasStatus(err, http.StatusInternalServerError) {
+		t.Errorf("GetManyError doesn't unwrap to the 500 APIError")
+	}
+	if len(passes) != 2 || passes["pass_1"] == nil || passes["pass_2"] == nil {
+		t.Errorf("GetMany() = %v, want pass_1 and pass_2", passes)
+	}
+	if _, ok := passes["pass_missing"]; ok {
+		t.Error("GetMany() included a missing ID")
+	}
+	if requested["pass_1"] != 1 {
+		t.Errorf("pass_1 requested %d times, want 1", requested["pass_1"])
+	}
+
+	passes, err = client.AccessPasses.GetMany([]string{"pass_1", "pass_missing"})
+	if err != nil {
+		t.Fatalf("GetMany() error = %v", err)
+	}
+	if len(passes) != 1 {
+		t.Errorf("GetMany() returned %d passes, want 1", len(passes))
+	}
+}
+
+func TestAccessPassesGetManyCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	requests := 0
+	client := newTestClient(t, &Config{BulkConcurrency: 1}, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		cancel()
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_1"}}`))
+	})
+
+	_, err := client.AccessPasses.GetManyWithContext(ctx, []string{"pass_1", "pass_2", "pass_3"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("GetManyWithContext() error = %v, want context.Canceled", err)
+	}
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+}
@@ -0,0 +1,167 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without sending the request while the circuit
+// breaker configured by Config.CircuitBreaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitBreakerConfig configures the circuit breaker that stops the client
+// sending requests to an API that keeps failing. Network errors and 5xx
+// responses count as failures; any other response counts as a success.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// circuit. Defaults to 5.
+	FailureThreshold int
+
+	// Window is how close together the failures must be: a failure more
+	// than Window after the first of the run starts a new run. Zero counts
+	// consecutive failures however far apart.
+	Window time.Duration
+
+	// Cooldown is how long the circuit stays open before a single probe
+	// request is let through. A successful probe closes the circuit; a
+	// failed one opens it for another Cooldown. Defaults to 30s.
+	Cooldown time.Duration
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// circuitBreaker tracks consecutive failures. A nil *circuitBreaker allows
+// every request.
+type circuitBreaker struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu           sync.Mutex
+	state        circuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+// newCircuitBreaker returns a circuit breaker for config, or nil when config
+// is nil
+func newCircuitBreaker(config *CircuitBreakerConfig) *circuitBreaker {
+	if config == nil {
+		return nil
+	}
+	b := &circuitBreaker{
+		threshold: config.FailureThreshold,
+		window:    config.Window,
+		cooldown:  config.Cooldown,
+		now:       time.Now,
+	}
+	if b.threshold <= 0 {
+		b.threshold = 5
+	}
+	if b.cooldown <= 0 {
+		b.cooldown = 30 * time.Second
+	}
+	return b
+}
+
+// allow reports whether a request may be sent, returning ErrCircuitOpen when
+// it may not. Once the cooldown has passed it lets a single probe through.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = circuitHalfOpen
+		b.probing = true
+		return nil
+	case circuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// circuitOutcome is how a request attempt affects the circuit
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+
+	// circuitIgnored is an attempt that says nothing about the API's health,
+	// e.g. one cancelled by the caller
+	circuitIgnored
+)
+
+// attemptOutcome classifies the result of a request attempt
+func attemptOutcome(ctx context.Context, resp *http.Response, err error) circuitOutcome {
+	if err != nil {
+		if ctx.Err() != nil {
+			return circuitIgnored
+		}
+		return circuitFailure
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return circuitFailure
+	}
+	return circuitSuccess
+}
+
+// record updates the circuit with the outcome of an allowed request
+func (b *circuitBreaker) record(outcome circuitOutcome) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		b.probing = false
+		switch outcome {
+		case circuitSuccess:
+			b.state = circuitClosed
+			b.failures = 0
+		case circuitFailure:
+			b.state = circuitOpen
+			b.openedAt = b.now()
+		}
+		return
+	}
+
+	switch outcome {
+	case circuitSuccess:
+		b.failures = 0
+	case circuitFailure:
+		now := b.now()
+		if b.failures == 0 || (b.window > 0 && now.Sub(b.firstFailure) > b.window) {
+			b.failures = 0
+			b.firstFailure = now
+		}
+		b.failures++
+		if b.state == circuitClosed && b.failures >= b.threshold {
+			b.state = circuitOpen
+			b.openedAt = now
+		}
+	}
+}
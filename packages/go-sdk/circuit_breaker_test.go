@@ -0,0 +1,166 @@
+package doorpasses
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	type step struct {
+		advance   time.Duration
+		wantAllow bool
+		outcome   circuitOutcome
+	}
+
+	tests := []struct {
+		name   string
+		config CircuitBreakerConfig
+		steps  []step
+	}{
+		{
+			name:   "opens after threshold",
+			config: CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute},
+			steps: []step{
+				{wantAllow: true, outcome: circuitFailure},
+				{wantAllow: true, outcome: circuitFailure},
+				{wantAllow: false},
+				{advance: 30 * time.Second, wantAllow: false},
+			},
+		},
+		{
+			name:   "success resets the count",
+			config: CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Minute},
+			steps: []step{
+				{wantAllow: true, outcome: circuitFailure},
+				{wantAllow: true, outcome: circuitSuccess},
+				{wantAllow: true, outcome: circuitFailure},
+				{wantAllow: true, outcome: circuitSuccess},
+			},
+		},
+		{
+			name:   "failures outside the window start a new run",
+			config: CircuitBreakerConfig{FailureThreshold: 2, Window: time.Second, Cooldown: time.Minute},
+			steps: []step{
+				{wantAllow: true, outcome: circuitFailure},
+				{advance: 2 * time.Second, wantAllow: true, outcome: circuitFailure},
+				{wantAllow: true, outcome: circuitFailure},
+				{wantAllow: false},
+			},
+		},
+		{
+			name:   "successful probe closes the circuit",
+			config: CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute},
+			steps: []step{
+				{wantAllow: true, outcome: circuitFailure},
+				{advance: time.Minute, wantAllow: true, outcome: circuitSuccess},
+				{wantAllow: true, outcome: circuitSuccess},
+			},
+		},
+		{
+			name:   "failed probe reopens the circuit",
+			config: CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute},
+			steps: []step{
+				{wantAllow: true, outcome: circuitFailure},
+				{advance: time.Minute, wantAllow: true, outcome: circuitFailure},
+				{advance: 30 * time.Second, wantAllow: false},
+				{advance: 30 * time.Second, wantAllow: true, outcome: circuitSuccess},
+			},
+		},
+		{
+			name:   "ignored probe lets another probe through",
+			config: CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute},
+			steps: []step{
+				{wantAllow: true, outcome: circuitFailure},
+				{advance: time.Minute, wantAllow: true, outcome: circuitIgnored},
+				{wantAllow: true, outcome: circuitSuccess},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			b := newCircuitBreaker(&tt.config)
+			b.now = func() time.Time { return now }
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				err := b.allow()
+				if allowed := err == nil; allowed != s.wantAllow {
+					t.Fatalf("step %d: allow() error = %v, want allowed %v", i, err, s.wantAllow)
+				}
+				if err != nil {
+					if !errors.Is(err, ErrCircuitOpen) {
+						t.Fatalf("step %d: allow() error = %v, want ErrCircuitOpen", i, err)
+					}
+					continue
+				}
+				b.record(s.outcome)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpenAllowsOneProbe(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newCircuitBreaker(&CircuitBreakerConfig{FailureThreshold: 1, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	b.record(circuitFailure)
+	now = now.Add(time.Minute)
+	if err := b.allow(); err != nil {
+		t.Fatalf("allow() probe error = %v", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("allow() during probe error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestConfigCircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, &Config{
+		MaxRetries:     5,
+		RetryBackoff:   func(int) time.Duration { return 0 },
+		CircuitBreaker: &CircuitBreakerConfig{FailureThreshold: 2, Cooldown: time.Hour},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"success": false, "error": "unavailable"}`))
+	})
+
+	// The circuit opens during the retries, cutting them short
+	_, err := client.Health()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Health() error = %v, want ErrCircuitOpen", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Health() error = %v, want it to wrap the last APIError", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	// Further requests fail fast without reaching the server
+	if _, err := client.AccessPasses.Get("pass_123"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Get() error = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestCircuitBreakerDisabledByDefault(t *testing.T) {
+	client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	for i := 0; i < 10; i++ {
+		if _, err := client.Health(); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Health() error = %v on request %d, want no circuit breaker", err, i+1)
+		}
+	}
+}
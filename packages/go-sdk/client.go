@@ -2,11 +2,29 @@
 package doorpasses
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultBaseURL is the production DoorPasses API
+const DefaultBaseURL = "https://api.doorpasses.io"
+
 // Client is the main DoorPasses SDK client
+//
+// A Client is safe for concurrent use by multiple goroutines and should be
+// shared rather than created per request. Its configuration is fixed when it
+// is created. The state that changes afterwards, namely the rate limit
+// returned by LastRateLimit, the cached result of HasEnterprise, the circuit
+// breaker, the retry budget, the ETag response cache and the coalesced Issue
+// calls, is each guarded by its own mutex.
 type Client struct {
 	http *HTTPClient
 
@@ -15,6 +33,13 @@ type Client struct {
 
 	// Console provides methods for managing card templates (Enterprise only)
 	Console *Console
+
+	// enterprise caches the result of HasEnterprise, nil until known.
+	// enterpriseGen counts the calls to InvalidateEnterprise, so a check
+	// that was in flight during one doesn't cache its stale result.
+	enterpriseMu  sync.Mutex
+	enterprise    *bool
+	enterpriseGen int
 }
 
 // NewClient creates a new DoorPasses client instance
@@ -40,12 +65,16 @@ func NewClient(accountID, sharedSecret string, config *Config) (*Client, error)
 		return nil, fmt.Errorf("accountId and sharedSecret are required")
 	}
 
-	baseURL := "https://api.doorpasses.io"
+	baseURL := DefaultBaseURL
 	timeout := 30 * time.Second
 
 	if config != nil {
 		if config.BaseURL != "" {
-			baseURL = config.BaseURL
+			normalized, err := normalizeBaseURL(config.BaseURL)
+			if err != nil {
+				return nil, err
+			}
+			baseURL = normalized
 		}
 		if config.Timeout > 0 {
 			timeout = config.Timeout
@@ -53,20 +82,421 @@ func NewClient(accountID, sharedSecret string, config *Config) (*Client, error)
 	}
 
 	httpClient := NewHTTPClient(accountID, sharedSecret, baseURL, timeout)
+	if config != nil {
+		if config.HTTPClient != nil {
+			httpClient.client = withRedirectPolicy(withFallbackTimeout(config.HTTPClient, timeout), config.FollowRedirects)
+			httpClient.transport = nil
+		} else if config.FollowRedirects {
+			httpClient.client.CheckRedirect = redirectPolicy(true)
+		}
+		httpClient.configureTransport(config)
+		httpClient.client = withMiddleware(httpClient.client, config.Middleware)
+		httpClient.userAgent = userAgent(config.UserAgent)
+		httpClient.compress = config.CompressRequests
+		if config.MaxResponseBytes != 0 {
+			httpClient.maxResponseBytes = config.MaxResponseBytes
+		}
+		if config.MaxDownloadBytes != 0 {
+			httpClient.maxDownloadBytes = config.MaxDownloadBytes
+		}
+		if config.Signer != nil {
+			httpClient.signer = config.Signer
+		} else if config.SignQueryParams {
+			httpClient.signer = SHA256Signer{AccountID: accountID, SharedSecret: sharedSecret, SignQuery: true}
+		}
+		if config.Marshal != nil {
+			httpClient.marshal = config.Marshal
+		}
+		if config.Unmarshal != nil {
+			httpClient.unmarshal = config.Unmarshal
+		}
+		httpClient.configureRetries(config)
+		httpClient.breaker = newCircuitBreaker(config.CircuitBreaker)
+		httpClient.limiter = newConcurrencyLimiter(config.MaxConcurrentRequests, config.FailFastOnConcurrencyLimit)
+		httpClient.responseCache = config.ResponseCache
+		httpClient.skipAccountCheck = config.DisableAccountCheck
+		httpClient.configureObservability(config)
+	}
+
+	accessPasses := newAccessPasses(httpClient)
+	if config != nil {
+		accessPasses.generateIdempotencyKeys = config.GenerateIdempotencyKeys
+		accessPasses.skipValidation = config.DisableClientValidation
+		accessPasses.normalizeInputs = config.NormalizeInputs
+		accessPasses.defaultStartImmediate = config.DefaultStartImmediate
+		if config.Dedupe {
+			accessPasses.dedupe = &issueGroup{}
+		}
+		if config.BulkConcurrency > 0 {
+			accessPasses.bulkConcurrency = config.BulkConcurrency
+			accessPasses.bulkAdaptiveMax = config.BulkConcurrency
+		}
+		accessPasses.bulkAdaptive = config.BulkAdaptiveConcurrency
+		accessPasses.fixedBulkIssue = config.DisableAdaptiveBulkConcurrency
+	}
 
-	return &Client{
+	client := &Client{
 		http:         httpClient,
-		AccessPasses: newAccessPasses(httpClient),
-		Console:      newConsole(httpClient),
-	}, nil
+		AccessPasses: accessPasses,
+		Console:      accessPasses.console,
+	}
+	if config != nil && config.VerifyCredentials {
+		if err := client.verifyCredentials(); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+	return client, nil
+}
+
+// verifyCredentials pings the API, wrapping a rejection of the credentials
+// with ErrInvalidCredentials
+func (c *Client) verifyCredentials() error {
+	err := c.Ping(context.Background(), withOperation(nil, "Client.VerifyCredentials")...)
+	if err == nil {
+		return nil
+	}
+	if IsUnauthorized(err) || hasStatus(err, http.StatusForbidden) {
+		return fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	return fmt.Errorf("failed to verify credentials: %w", err)
+}
+
+// normalizeBaseURL checks that baseURL is an absolute http or https URL and
+// strips trailing slashes, so request paths can be appended to it
+func normalizeBaseURL(baseURL string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(baseURL))
+	if err != nil {
+		return "", fmt.Errorf("invalid baseURL %q: %w", baseURL, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("invalid baseURL %q: scheme must be http or https, e.g. %q", baseURL, DefaultBaseURL)
+	}
+	if parsed.Host == "" {
+		return "", fmt.Errorf("invalid baseURL %q: host is required", baseURL)
+	}
+	if parsed.RawQuery != "" || parsed.Fragment != "" {
+		return "", fmt.Errorf("invalid baseURL %q: must not contain a query or fragment", baseURL)
+	}
+
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = ""
+	return parsed.String(), nil
+}
+
+// Close releases the client's idle connections. Afterwards every request
+// fails with ErrClientClosed; requests already in flight are not interrupted.
+// Close is safe to call more than once and always returns nil.
+//
+// When Config.HTTPClient was supplied, its connections belong to the caller
+// and are left open; close them through that http.Client instead.
+func (c *Client) Close() error {
+	c.http.close()
+	return nil
+}
+
+// Shutdown closes the client gracefully: new requests fail with
+// ErrClientClosed at once, while requests already in flight, including their
+// retries, are given until ctx is done to finish. It then releases the
+// client's idle connections like Close. When ctx is done first, Shutdown
+// returns ctx.Err() and the remaining requests carry on until their own
+// contexts end. Shutdown is safe to call more than once and together with
+// Close.
+func (c *Client) Shutdown(ctx context.Context) error {
+	return c.http.shutdown(ctx)
+}
+
+// LastRateLimit returns the rate limit reported by the most recent API
+// response. It is the zero value until a response carrying rate-limit
+// headers has been received. It is safe to call concurrently.
+func (c *Client) LastRateLimit() RateLimit {
+	return c.http.lastRateLimit()
 }
 
 // Health performs a health check to verify API connectivity
-func (c *Client) Health() (map[string]interface{}, error) {
+func (c *Client) Health(opts ...RequestOption) (map[string]interface{}, error) {
+	return c.HealthWithContext(context.Background(), opts...)
+}
+
+// HealthWithContext performs a health check, aborting if ctx is done
+func (c *Client) HealthWithContext(ctx context.Context, opts ...RequestOption) (map[string]interface{}, error) {
+	opts = withOperation(opts, "Client.Health")
 	var result map[string]interface{}
-	err := c.http.Get("/health", nil, &result)
+	err := c.http.GetWithContext(ctx, "/health", nil, &result, opts...)
 	if err != nil {
 		return nil, err
 	}
 	return result, nil
 }
+
+// Ping checks that the API is reachable and accepts the client's
+// credentials, without the cost of a full health check. It returns nil on
+// success, an APIError with a 401 or 403 status when the credentials are
+// rejected, or the network error when the API can't be reached. Retries
+// follow the client's settings.
+func (c *Client) Ping(ctx context.Context, opts ...RequestOption) error {
+	opts = withOperation(opts, "Client.Ping")
+	// The health endpoint isn't authenticated, so ask for a single access
+	// pass ID instead. Auth is checked before the query is validated, so any
+	// other client error still proves the credentials were accepted.
+	opts = append(opts[:len(opts):len(opts)], WithFields("id"))
+	err := c.http.GetWithContext(ctx, "/v1/access-passes", map[string]interface{}{"limit": 1}, nil, opts...)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests:
+		return err
+	case http.StatusNotFound:
+		// The route itself is missing, so BaseURL isn't the DoorPasses API
+		return err
+	}
+	if apiErr.StatusCode < 500 {
+		return nil
+	}
+	return err
+}
+
+// Do calls an API endpoint that the SDK doesn't wrap yet, with the same
+// signing, retries and error handling as the typed methods. path is relative
+// to BaseURL, e.g. "/v1/access-passes/pass_123/notes". For GET requests body
+// is signed as the sig_payload query parameter and must encode to a JSON
+// object; for other methods it is sent as the JSON request body. The data
+// field of a successful response is decoded into out, which may be nil.
+//
+// Do is an advanced escape hatch: it has none of the validation of the
+// typed methods, and its behaviour may change between minor releases.
+// Prefer a typed method when one exists.
+func (c *Client) Do(ctx context.Context, method, path string, body, out interface{}, opts ...RequestOption) error {
+	opts = withOperation(opts, "Client.Do")
+	if !strings.HasPrefix(path, "/") {
+		return fmt.Errorf("path must start with /, got %q", path)
+	}
+
+	method = strings.ToUpper(method)
+	if method != http.MethodGet {
+		return c.http.sendWithBody(ctx, method, path, body, out, opts)
+	}
+
+	sigPayload, err := toSigPayload(body)
+	if err != nil {
+		return err
+	}
+	return c.http.GetWithContext(ctx, path, sigPayload, out, opts...)
+}
+
+// toSigPayload converts the body of a GET request made with Do to the map
+// signed as its sig_payload
+func toSigPayload(body interface{}) (map[string]interface{}, error) {
+	switch body := body.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return body, nil
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	var sigPayload map[string]interface{}
+	if err := json.Unmarshal(data, &sigPayload); err != nil {
+		return nil, fmt.Errorf("GET payload must encode to a JSON object: %w", err)
+	}
+	return sigPayload, nil
+}
+
+// HasEnterprise reports whether the account is on the Enterprise tier and
+// can use Console, e.g. to hide template features for accounts that can't
+// use them. The first call makes a lightweight Console request and the
+// answer is cached until InvalidateEnterprise is called. Errors other than
+// the API refusing Console access are returned and not cached.
+func (c *Client) HasEnterprise(ctx context.Context, opts ...RequestOption) (bool, error) {
+	c.enterpriseMu.Lock()
+	cached, gen := c.enterprise, c.enterpriseGen
+	c.enterpriseMu.Unlock()
+	if cached != nil {
+		return *cached, nil
+	}
+
+	// The request is made without holding enterpriseMu, so a slow check
+	// doesn't block InvalidateEnterprise or callers with their own contexts
+	opts = withOperation(opts, "Client.HasEnterprise")
+	opts = append(opts[:len(opts):len(opts)], WithFields("id"))
+	_, err := c.Console.ListTemplatesPageWithContext(ctx, &ListCardTemplatesParams{Limit: 1}, opts...)
+	if err != nil && !errors.Is(err, ErrEnterpriseRequired) {
+		return false, err
+	}
+
+	hasEnterprise := err == nil
+	c.enterpriseMu.Lock()
+	defer c.enterpriseMu.Unlock()
+	if c.enterpriseGen == gen {
+		c.enterprise = &hasEnterprise
+	}
+	return hasEnterprise, nil
+}
+
+// InvalidateEnterprise forgets the cached result of HasEnterprise, e.g.
+// after the account was upgraded, so the next call checks again
+func (c *Client) InvalidateEnterprise() {
+	c.enterpriseMu.Lock()
+	defer c.enterpriseMu.Unlock()
+	c.enterprise = nil
+	c.enterpriseGen++
+}
+
+// HealthStatus is the typed result of HealthCheck
+type HealthStatus struct {
+	// Status is "healthy" when the API is up
+	Status string
+
+	// Degraded reports that the API is reachable but reports a status other
+	// than healthy, for itself or for one of its components
+	Degraded bool
+
+	// Components holds every component the API reports on, e.g.
+	// "database", ordered by name
+	Components []ComponentHealth
+
+	// Version is the API version
+	Version string
+
+	// Latency is the round-trip time of the check, measured by the client
+	Latency time.Duration
+
+	// Extra holds every other field of the response
+	Extra map[string]interface{}
+}
+
+// ComponentHealth is the health of one component of the API, e.g. its
+// database or a wallet provider
+type ComponentHealth struct {
+	// Name is the component's name as the API reports it
+	Name string
+
+	// Status is the component's reported status, e.g. "healthy" or
+	// "degraded", empty when the API didn't give one
+	Status string
+
+	// Details holds every other field the API reported for the component,
+	// e.g. a latency or message
+	Details map[string]interface{}
+}
+
+// Healthy reports whether the component's status means all is well. It is
+// false for a component without a status, which is unknown rather than
+// degraded.
+func (c ComponentHealth) Healthy() bool {
+	return isHealthy(c.Status)
+}
+
+// Component returns the component with the given name, if the API reported
+// on it
+func (s *HealthStatus) Component(name string) (ComponentHealth, bool) {
+	for _, component := range s.Components {
+		if component.Name == name {
+			return component, true
+		}
+	}
+	return ComponentHealth{}, false
+}
+
+// DegradedComponents returns the components that report a status other
+// than healthy. Like the top-level status, a component without a status
+// isn't counted as degraded.
+func (s *HealthStatus) DegradedComponents() []ComponentHealth {
+	var degraded []ComponentHealth
+	for _, component := range s.Components {
+		if component.Status != "" && !component.Healthy() {
+			degraded = append(degraded, component)
+		}
+	}
+	return degraded
+}
+
+// HealthCheck checks API connectivity, returning the API's status and the
+// latency of the check. Unlike Health its result is typed. The check is
+// never retried so probes stay fast. It fails with ErrAPIDown when the API
+// can't be reached or answers with an error; an API that answers but
+// reports problems is returned with Degraded set instead.
+func (c *Client) HealthCheck(ctx context.Context, opts ...RequestOption) (*HealthStatus, error) {
+	opts = withOperation(opts, "Client.HealthCheck")
+	opts = append(opts, withoutRetries())
+	start := time.Now()
+	result, err := c.HealthWithContext(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAPIDown, err)
+	}
+
+	status := &HealthStatus{
+		Latency: time.Since(start),
+		Extra:   make(map[string]interface{}),
+	}
+	for key, value := range result {
+		switch key {
+		case "status":
+			status.Status, _ = value.(string)
+		case "version":
+			status.Version, _ = value.(string)
+		case "components":
+			status.Components = healthComponents(value)
+		default:
+			status.Extra[key] = value
+		}
+	}
+
+	status.Degraded = status.Status != "" && !isHealthy(status.Status)
+	if len(status.DegradedComponents()) > 0 {
+		status.Degraded = true
+	}
+	return status, nil
+}
+
+// healthComponents reads the components of a health response, given either
+// as {"database": "healthy"} or {"database": {"status": "healthy", ...}}.
+// A component in any other form is kept with its value as the "value"
+// detail and no status.
+func healthComponents(value interface{}) []ComponentHealth {
+	raw, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	components := make([]ComponentHealth, 0, len(raw))
+	for name, component := range raw {
+		health := ComponentHealth{Name: name}
+		switch component := component.(type) {
+		case string:
+			health.Status = component
+		case map[string]interface{}:
+			for key, detail := range component {
+				if key == "status" {
+					health.Status, _ = detail.(string)
+					continue
+				}
+				if health.Details == nil {
+					health.Details = make(map[string]interface{})
+				}
+				health.Details[key] = detail
+			}
+		default:
+			health.Details = map[string]interface{}{"value": component}
+		}
+		components = append(components, health)
+	}
+	sort.Slice(components, func(i, j int) bool {
+		return components[i].Name < components[j].Name
+	})
+	return components
+}
+
+// isHealthy reports whether a reported health status means all is well
+func isHealthy(status string) bool {
+	switch strings.ToLower(status) {
+	case "healthy", "ok", "up", "pass":
+		return true
+	}
+	return false
+}
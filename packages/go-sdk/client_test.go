@@ -1,7 +1,18 @@
 package doorpasses
 
 import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -65,3 +76,831 @@ func TestNewClient(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+		wantErr bool
+	}{
+		{name: "unchanged", baseURL: "https://api.doorpasses.io", want: "https://api.doorpasses.io"},
+		{name: "trailing slash", baseURL: "https://api.doorpasses.io/", want: "https://api.doorpasses.io"},
+		{name: "path with trailing slashes", baseURL: "http://localhost:3000/api//", want: "http://localhost:3000/api"},
+		{name: "surrounding whitespace", baseURL: " https://api.doorpasses.io ", want: "https://api.doorpasses.io"},
+		{name: "missing scheme", baseURL: "api.doorpasses.io", wantErr: true},
+		{name: "host without scheme", baseURL: "localhost:3000", wantErr: true},
+		{name: "unsupported scheme", baseURL: "ftp://api.doorpasses.io", wantErr: true},
+		{name: "missing host", baseURL: "https://", wantErr: true},
+		{name: "query", baseURL: "https://api.doorpasses.io?x=1", wantErr: true},
+		{name: "malformed", baseURL: "https://api.doorpasses.io:port", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeBaseURL(tt.baseURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeBaseURL(%q) error = %v, wantErr %v", tt.baseURL, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeBaseURL(%q) = %q, want %q", tt.baseURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientBaseURL(t *testing.T) {
+	client, err := NewClient("test_account", "test_secret", nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.http.baseURL != DefaultBaseURL {
+		t.Errorf("baseURL = %q, want %q", client.http.baseURL, DefaultBaseURL)
+	}
+
+	if _, err := NewClient("test_account", "test_secret", &Config{BaseURL: "api.doorpasses.io"}); err == nil {
+		t.Error("NewClient() with a scheme-less BaseURL returned no error")
+	}
+}
+
+func TestNewClientWithHTTPClient(t *testing.T) {
+	var transportUsed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-ACCT-ID") != "test_account" || r.Header.Get("X-PAYLOAD-SIG") == "" {
+			t.Errorf("auth headers missing: %v", r.Header)
+		}
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		httpClient  *http.Client
+		wantTimeout time.Duration
+	}{
+		{
+			name:        "client without timeout uses configured timeout",
+			httpClient:  &http.Client{},
+			wantTimeout: 5 * time.Second,
+		},
+		{
+			name:        "client timeout takes precedence",
+			httpClient:  &http.Client{Timeout: time.Minute},
+			wantTimeout: time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transportUsed = false
+			tt.httpClient.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				transportUsed = true
+				return http.DefaultTransport.RoundTrip(req)
+			})
+
+			client, err := NewClient("test_account", "test_secret", &Config{
+				BaseURL:    server.URL,
+				Timeout:    5 * time.Second,
+				HTTPClient: tt.httpClient,
+			})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+
+			if got := client.http.client.Timeout; got != tt.wantTimeout {
+				t.Errorf("Timeout = %v, want %v", got, tt.wantTimeout)
+			}
+			if _, err := client.Health(); err != nil {
+				t.Fatalf("Health() error = %v", err)
+			}
+			if !transportUsed {
+				t.Error("supplied HTTP client was not used")
+			}
+		})
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClientUserAgent(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *Config
+		want   string
+	}{
+		{
+			name: "default",
+			want: "doorpasses-go/" + Version,
+		},
+		{
+			name:   "custom",
+			config: &Config{UserAgent: "acme-onboarding/2.1"},
+			want:   "doorpasses-go/" + Version + " acme-onboarding/2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+				w.Write([]byte(`{"status": "healthy"}`))
+			})
+
+			if _, err := client.Health(); err != nil {
+				t.Fatalf("Health() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("User-Agent = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClientConcurrentUse shares one client across goroutines; run with
+// -race to check for data races
+func TestClientConcurrentUse(t *testing.T) {
+	var requests atomic.Int64
+	client := newTestClient(t, &Config{
+		OnResponse: func(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+			requests.Add(1)
+		},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "50")
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+		case r.URL.Path == "/health":
+			w.Write([]byte(`{"status": "healthy"}`))
+		default:
+			w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+		}
+	})
+
+	const workers = 20
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.AccessPasses.Issue(validIssueParams()); err != nil {
+				t.Errorf("Issue() error = %v", err)
+			}
+			if _, err := client.AccessPasses.Get("pass_123"); err != nil {
+				t.Errorf("Get() error = %v", err)
+			}
+			if _, err := client.Health(); err != nil {
+				t.Errorf("Health() error = %v", err)
+			}
+			client.LastRateLimit()
+		}()
+	}
+	wg.Wait()
+
+	if got := requests.Load(); got != 3*workers {
+		t.Errorf("server received %d requests, want %d", got, 3*workers)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	})
+	if client.http.transport == nil {
+		t.Fatal("client does not own its transport")
+	}
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("second Close() error = %v", err)
+	}
+
+	if _, err := client.Health(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Health() after Close error = %v, want ErrClientClosed", err)
+	}
+	if _, err := client.AccessPasses.Get("pass_123"); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Get() after Close error = %v, want ErrClientClosed", err)
+	}
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+}
+
+func TestClientShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	})
+
+	inFlight := make(chan error, 1)
+	go func() {
+		_, err := client.Health()
+		inFlight <- err
+	}()
+	<-started
+
+	// A shutdown whose grace period ends first reports it
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() with a short grace period error = %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := client.Health(); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Health() during Shutdown error = %v, want ErrClientClosed", err)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- client.Shutdown(context.Background())
+	}()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown() returned %v before the in-flight request ended", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	if err := <-inFlight; err != nil {
+		t.Errorf("in-flight Health() error = %v, want it to finish", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+}
+
+func TestClientShutdownConcurrent(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Health(); err != nil && !errors.Is(err, ErrClientClosed) {
+				t.Errorf("Health() error = %v", err)
+			}
+		}()
+	}
+	if err := client.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	wg.Wait()
+	if client.http.inflight != 0 {
+		t.Errorf("%d requests still counted in flight", client.http.inflight)
+	}
+}
+
+func TestClientCloseWithHTTPClient(t *testing.T) {
+	client, err := NewClient("test_account", "test_secret", &Config{HTTPClient: &http.Client{}})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if client.http.transport != nil {
+		t.Error("client owns the transport of a caller-supplied http.Client")
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+func TestClientTransportTimeouts(t *testing.T) {
+	tests := []struct {
+		name             string
+		config           *Config
+		wantTLSHandshake time.Duration
+	}{
+		{name: "defaults", config: &Config{}, wantTLSHandshake: 10 * time.Second},
+		{name: "custom", config: &Config{DialTimeout: time.Second, TLSHandshakeTimeout: 2 * time.Second}, wantTLSHandshake: 2 * time.Second},
+		{name: "disabled", config: &Config{DialTimeout: -1, TLSHandshakeTimeout: -1}, wantTLSHandshake: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+			})
+			if got := client.http.transport.TLSHandshakeTimeout; got != tt.wantTLSHandshake {
+				t.Errorf("TLSHandshakeTimeout = %v, want %v", got, tt.wantTLSHandshake)
+			}
+			// The replacement dialer must still connect
+			if _, err := client.Health(); err != nil {
+				t.Errorf("Health() error = %v", err)
+			}
+		})
+	}
+
+	t.Run("ignored with HTTPClient", func(t *testing.T) {
+		transport := &http.Transport{TLSHandshakeTimeout: 5 * time.Second}
+		client, err := NewClient("test_account", "test_secret", &Config{
+			HTTPClient:          &http.Client{Transport: transport},
+			TLSHandshakeTimeout: time.Second,
+		})
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if transport.TLSHandshakeTimeout != 5*time.Second {
+			t.Errorf("caller's TLSHandshakeTimeout = %v, want unchanged 5s", transport.TLSHandshakeTimeout)
+		}
+		client.Close()
+	})
+}
+
+func TestClientConnectionPool(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      *Config
+		wantIdle    int
+		wantPerHost int
+		wantTimeout time.Duration
+	}{
+		{name: "defaults", config: &Config{}, wantIdle: 100, wantPerHost: 0, wantTimeout: 90 * time.Second},
+		{name: "custom", config: &Config{MaxIdleConns: 200, MaxIdleConnsPerHost: 50, IdleConnTimeout: time.Minute}, wantIdle: 200, wantPerHost: 50, wantTimeout: time.Minute},
+		{name: "unlimited", config: &Config{MaxIdleConns: -1, MaxIdleConnsPerHost: -1, IdleConnTimeout: -1}, wantIdle: 0, wantPerHost: math.MaxInt, wantTimeout: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+			})
+			transport := client.http.transport
+			if transport.MaxIdleConns != tt.wantIdle {
+				t.Errorf("MaxIdleConns = %d, want %d", transport.MaxIdleConns, tt.wantIdle)
+			}
+			if transport.MaxIdleConnsPerHost != tt.wantPerHost {
+				t.Errorf("MaxIdleConnsPerHost = %d, want %d", transport.MaxIdleConnsPerHost, tt.wantPerHost)
+			}
+			if transport.IdleConnTimeout != tt.wantTimeout {
+				t.Errorf("IdleConnTimeout = %v, want %v", transport.IdleConnTimeout, tt.wantTimeout)
+			}
+		})
+	}
+
+	t.Run("ignored with HTTPClient", func(t *testing.T) {
+		transport := &http.Transport{MaxIdleConnsPerHost: 3}
+		client, err := NewClient("test_account", "test_secret", &Config{
+			HTTPClient:          &http.Client{Transport: transport},
+			MaxIdleConnsPerHost: 50,
+		})
+		if err != nil {
+			t.Fatalf("NewClient() error = %v", err)
+		}
+		if transport.MaxIdleConnsPerHost != 3 {
+			t.Errorf("caller's MaxIdleConnsPerHost = %d, want unchanged 3", transport.MaxIdleConnsPerHost)
+		}
+		client.Close()
+	})
+}
+
+func TestClientHealthCheck(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			t.Errorf("path = %s, want /health", r.URL.Path)
+		}
+		time.Sleep(5 * time.Millisecond)
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy", "version": "1.0.0", "region": "MENA"}}`))
+	})
+
+	status, err := client.HealthCheck(context.Background())
+	if err != nil {
+		t.Fatalf("HealthCheck() error = %v", err)
+	}
+	if status.Status != "healthy" {
+		t.Errorf("Status = %q, want %q", status.Status, "healthy")
+	}
+	if status.Version != "1.0.0" {
+		t.Errorf("Version = %q, want %q", status.Version, "1.0.0")
+	}
+	if status.Latency < 5*time.Millisecond {
+		t.Errorf("Latency = %v, want at least 5ms", status.Latency)
+	}
+	if status.Extra["region"] != "MENA" {
+		t.Errorf("Extra[region] = %v, want MENA", status.Extra["region"])
+	}
+	if _, ok := status.Extra["status"]; ok {
+		t.Error("Extra contains a known field")
+	}
+}
+
+func TestClientHealthCheckDegraded(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantDegraded   bool
+		wantComponents []ComponentHealth
+		wantUnhealthy  []string
+	}{
+		{
+			name: "no components",
+			body: `{"status": "healthy"}`,
+		},
+		{
+			name: "healthy components",
+			body: `{"status": "healthy", "components": {"wallet": {"status": "ok"}, "database": "healthy"}}`,
+			wantComponents: []ComponentHealth{
+				{Name: "database", Status: "healthy"},
+				{Name: "wallet", Status: "ok"},
+			},
+		},
+		{
+			name:         "degraded component",
+			body:         `{"status": "healthy", "components": {"database": "healthy", "wallet": {"status": "degraded", "latencyMs": 2400, "message": "Apple Wallet slow"}}}`,
+			wantDegraded: true,
+			wantComponents: []ComponentHealth{
+				{Name: "database", Status: "healthy"},
+				{Name: "wallet", Status: "degraded", Details: map[string]interface{}{"latencyMs": float64(2400), "message": "Apple Wallet slow"}},
+			},
+			wantUnhealthy: []string{"wallet"},
+		},
+		{
+			name: "component in an unknown form",
+			body: `{"status": "healthy", "components": {"delivery": 3}}`,
+			wantComponents: []ComponentHealth{
+				{Name: "delivery", Details: map[string]interface{}{"value": float64(3)}},
+			},
+		},
+		{
+			name: "component without a status",
+			body: `{"status": "healthy", "components": {"database": {"latencyMs": 3}, "wallet": {"status": "ok"}}}`,
+			wantComponents: []ComponentHealth{
+				{Name: "database", Details: map[string]interface{}{"latencyMs": float64(3)}},
+				{Name: "wallet", Status: "ok"},
+			},
+		},
+		{
+			name:         "degraded status",
+			body:         `{"status": "degraded"}`,
+			wantDegraded: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"success": true, "data": ` + tt.body + `}`))
+			})
+
+			status, err := client.HealthCheck(context.Background())
+			if err != nil {
+				t.Fatalf("HealthCheck() error = %v", err)
+			}
+			if status.Degraded != tt.wantDegraded {
+				t.Errorf("Degraded = %v, want %v", status.Degraded, tt.wantDegraded)
+			}
+			if !reflect.DeepEqual(status.Components, tt.wantComponents) {
+				t.Errorf("Components = %+v, want %+v", status.Components, tt.wantComponents)
+			}
+			var unhealthy []string
+			for _, component := range status.DegradedComponents() {
+				unhealthy = append(unhealthy, component.Name)
+			}
+			if !reflect.DeepEqual(unhealthy, tt.wantUnhealthy) {
+				t.Errorf("DegradedComponents() = %v, want %v", unhealthy, tt.wantUnhealthy)
+			}
+			for _, want := range tt.wantComponents {
+				if got, ok := status.Component(want.Name); !ok || got.Status != want.Status {
+					t.Errorf("Component(%q) = %+v, %v, want status %q", want.Name, got, ok, want.Status)
+				}
+			}
+			if _, ok := status.Extra["components"]; ok {
+				t.Error("Extra contains components")
+			}
+		})
+	}
+}
+
+func TestClientHealthCheckDown(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, &Config{MaxRetries: 3, RetryBackoff: func(int) time.Duration { return 0 }}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := client.HealthCheck(context.Background())
+	if !errors.Is(err, ErrAPIDown) {
+		t.Errorf("HealthCheck() error = %v, want ErrAPIDown", err)
+	}
+	if !hasStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("HealthCheck() error = %v, want status 503", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+
+	unreachable, err := NewClient("acct", "secret", &Config{BaseURL: "http://127.0.0.1:1"})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := unreachable.HealthCheck(context.Background()); !errors.Is(err, ErrAPIDown) {
+		t.Errorf("HealthCheck() error = %v, want ErrAPIDown", err)
+	}
+}
+
+func TestClientHealthCheckCancelled(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent with a cancelled context")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.HealthCheck(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("HealthCheck() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestClientPing(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    bool
+		wantStatus int
+	}{
+		{name: "ok", statusCode: http.StatusOK, body: `{"success": true, "data": {"items": []}}`},
+		{
+			name:       "query rejected after auth",
+			statusCode: http.StatusBadRequest,
+			body:       `{"success": false, "error": {"code": "VALIDATION_ERROR", "message": "Template ID is required"}}`,
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"success": false, "error": {"code": "INVALID_SIGNATURE", "message": "Invalid signature"}}`,
+			wantErr:    true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "wrong base URL",
+			statusCode: http.StatusNotFound,
+			body:       `Not Found`,
+			wantErr:    true,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusServiceUnavailable,
+			body:       `{"success": false, "error": "unavailable"}`,
+			wantErr:    true,
+			wantStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/access-passes" {
+					t.Errorf("path = %s, want /v1/access-passes", r.URL.Path)
+				}
+				if r.Header.Get("X-PAYLOAD-SIG") == "" {
+					t.Error("request is not signed")
+				}
+				if got := r.URL.Query().Get("fields"); got != "id" {
+					t.Errorf("fields = %q, want id", got)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			})
+
+			err := client.Ping(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Ping() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !hasStatus(err, tt.wantStatus) {
+				t.Errorf("Ping() error = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestNewClientVerifyCredentials(t *testing.T) {
+	tests := []struct {
+		name         string
+		verify       bool
+		statusCode   int
+		wantRequests int32
+		wantErr      error
+	}{
+		{name: "accepted", verify: true, statusCode: http.StatusOK, wantRequests: 1},
+		{name: "rejected", verify: true, statusCode: http.StatusUnauthorized, wantRequests: 1, wantErr: ErrInvalidCredentials},
+		{name: "not verified", statusCode: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{"success": true, "data": {"items": []}}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient("test_account", "test_secret", &Config{BaseURL: server.URL, VerifyCredentials: tt.verify})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NewClient() error = %v, want %v", err, tt.wantErr)
+			}
+			if (client == nil) != (tt.wantErr != nil) {
+				t.Errorf("NewClient() client = %v, want one only on success", client)
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestClientPingNetworkError(t *testing.T) {
+	client, err := NewClient("test_account", "test_secret", &Config{
+		BaseURL:    "http://127.0.0.1:1",
+		MaxRetries: -1,
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	err = client.Ping(context.Background())
+	var apiErr *APIError
+	if err == nil || errors.As(err, &apiErr) {
+		t.Errorf("Ping() error = %v, want a network error", err)
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/access-passes/pass_123/notes":
+			encoded := r.URL.Query().Get("sig_payload")
+			if !verifySignature("test_secret", encoded, r.Header.Get("X-PAYLOAD-SIG")) {
+				t.Error("GET sig_payload signature is invalid")
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(encoded)
+			if string(decoded) != `{"limit":5}` {
+				t.Errorf("sig_payload = %s, want {\"limit\":5}", decoded)
+			}
+			w.Write([]byte(`{"success": true, "data": [{"text": "first"}]}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/access-passes/pass_123/notes":
+			body, _ := io.ReadAll(r.Body)
+			if !verifySignature("test_secret", base64.StdEncoding.EncodeToString(body), r.Header.Get("X-PAYLOAD-SIG")) {
+				t.Error("POST body signature is invalid")
+			}
+			if string(body) != `{"text":"hello"}` {
+				t.Errorf("body = %s, want {\"text\":\"hello\"}", body)
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"success": true, "data": {"text": "hello"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Route not found"}}`))
+		}
+	})
+
+	type note struct {
+		Text string `json:"text"`
+	}
+	ctx := context.Background()
+
+	var notes []note
+	if err := client.Do(ctx, "get", "/v1/access-passes/pass_123/notes", struct {
+		Limit int `json:"limit"`
+	}{5}, &notes); err != nil {
+		t.Fatalf("Do(GET) error = %v", err)
+	}
+	if len(notes) != 1 || notes[0].Text != "first" {
+		t.Errorf("Do(GET) out = %+v", notes)
+	}
+
+	var created note
+	if err := client.Do(ctx, http.MethodPost, "/v1/access-passes/pass_123/notes", note{Text: "hello"}, &created); err != nil {
+		t.Fatalf("Do(POST) error = %v", err)
+	}
+	if created.Text != "hello" {
+		t.Errorf("Do(POST) out = %+v", created)
+	}
+
+	if err := client.Do(ctx, http.MethodGet, "/v1/unknown", nil, nil); !IsNotFound(err) {
+		t.Errorf("Do(unknown) error = %v, want not found", err)
+	}
+	if err := client.Do(ctx, http.MethodGet, "/v1/unknown", []string{"not", "an", "object"}, nil); err == nil {
+		t.Error("Do(GET non-object) error = nil, want error")
+	}
+	if err := client.Do(ctx, http.MethodGet, "v1/access-passes", nil, nil); err == nil {
+		t.Error("Do(relative path) error = nil, want error")
+	}
+}
+
+func TestClientHasEnterprise(t *testing.T) {
+	var requests atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusForbidden)
+	client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v1/console/card-templates" {
+			t.Errorf("Path = %q, want /v1/console/card-templates", r.URL.Path)
+		}
+		switch code := int(status.Load()); code {
+		case http.StatusOK:
+			w.Write([]byte(`{"success": true, "data": {"items": []}}`))
+		default:
+			w.WriteHeader(code)
+			w.Write([]byte(`{"success": false, "error": {"code": "FORBIDDEN", "message": "Enterprise tier required"}}`))
+		}
+	})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		got, err := client.HasEnterprise(ctx)
+		if err != nil || got {
+			t.Fatalf("HasEnterprise() = %v, %v, want false, nil", got, err)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("sent %d requests, want 1 with the cached result", got)
+	}
+
+	// After an upgrade, the cached answer must be invalidated
+	status.Store(http.StatusOK)
+	if got, _ := client.HasEnterprise(ctx); got {
+		t.Error("HasEnterprise() = true before InvalidateEnterprise, want cached false")
+	}
+	client.InvalidateEnterprise()
+	if got, err := client.HasEnterprise(ctx); err != nil || !got {
+		t.Errorf("HasEnterprise() after InvalidateEnterprise = %v, %v, want true, nil", got, err)
+	}
+
+	// Other failures are returned and not cached
+	client.InvalidateEnterprise()
+	status.Store(http.StatusInternalServerError)
+	if _, err := client.HasEnterprise(ctx); err == nil {
+		t.Error("HasEnterprise() on a 500 error = nil, want error")
+	}
+	status.Store(http.StatusOK)
+	if got, err := client.HasEnterprise(ctx); err != nil || !got {
+		t.Errorf("HasEnterprise() after a 500 = %v, %v, want true, nil", got, err)
+	}
+}
+
+func TestClientHasEnterpriseInvalidatedDuringCheck(t *testing.T) {
+	var requests atomic.Int32
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			close(started)
+			<-unblock
+		}
+		w.Write([]byte(`{"success": true, "data": {"items": []}}`))
+	})
+	ctx := context.Background()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if got, err := client.HasEnterprise(ctx); err != nil || !got {
+			t.Errorf("HasEnterprise() = %v, %v, want true, nil", got, err)
+		}
+	}()
+	<-started
+
+	// The check in flight must not hold up InvalidateEnterprise
+	invalidated := make(chan struct{})
+	go func() {
+		client.InvalidateEnterprise()
+		close(invalidated)
+	}()
+	select {
+	case <-invalidated:
+	case <-time.After(time.Second):
+		t.Fatal("InvalidateEnterprise() blocked on the HasEnterprise request in flight")
+	}
+	close(unblock)
+	<-done
+
+	// The result of a check started before InvalidateEnterprise isn't cached
+	if _, err := client.HasEnterprise(ctx); err != nil {
+		t.Fatalf("HasEnterprise() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("sent %d requests, want 2 with the stale result discarded", got)
+	}
+}
@@ -0,0 +1,49 @@
+package doorpasses
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// minCompressSize is the smallest request body compressed when
+// Config.CompressRequests is set; smaller bodies aren't worth the overhead
+const minCompressSize = 1024
+
+// gzipBody compresses a request body
+func gzipBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// responseBody returns a reader over the decoded response body. A body
+// marked as gzip-encoded is decompressed, unless it turns out not to be
+// gzip data, in which case it is read as-is.
+func responseBody(resp *http.Response) (io.Reader, error) {
+	if !strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		return resp.Body, nil
+	}
+
+	br := bufio.NewReader(resp.Body)
+	magic, err := br.Peek(2)
+	if err != nil || magic[0] != 0x1f || magic[1] != 0x8b {
+		return br, nil
+	}
+
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	return zr, nil
+}
@@ -0,0 +1,164 @@
+package doorpasses
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompressRequests(t *testing.T) {
+	tests := []struct {
+		name         string
+		title        string
+		wantEncoding string
+	}{
+		{
+			name:  "small body is sent as-is",
+			title: "Engineer",
+		},
+		{
+			name:         "large body is compressed",
+			title:        strings.Repeat("Engineer ", 200),
+			wantEncoding: "gzip",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{CompressRequests: true}, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Content-Encoding"); got != tt.wantEncoding {
+					t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+				}
+
+				var body io.Reader = r.Body
+				if tt.wantEncoding == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("gzip.NewReader() error = %v", err)
+						return
+					}
+					body = zr
+				}
+				payload, _ := io.ReadAll(body)
+
+				// The signature must match the uncompressed JSON payload
+				want := createSignature("test_secret", base64.StdEncoding.EncodeToString(payload))
+				if got := r.Header.Get("X-PAYLOAD-SIG"); got != want {
+					t.Errorf("X-PAYLOAD-SIG = %q, want %q", got, want)
+				}
+
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			params := validIssueParams()
+			params.Title = tt.title
+			if _, err := client.AccessPasses.Issue(params); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestGzipResponses(t *testing.T) {
+	gzipped := func(data string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(data))
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{
+			name:     "gzip response is decompressed",
+			encoding: "gzip",
+			body:     gzipped(`{"success": true, "data": {"id": "pass_123"}}`),
+		},
+		{
+			name: "plain response",
+			body: []byte(`{"success": true, "data": {"id": "pass_123"}}`),
+		},
+		{
+			name:     "response mislabelled as gzip",
+			encoding: "gzip",
+			body:     []byte(`{"success": true, "data": {"id": "pass_123"}}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+					t.Errorf("Accept-Encoding = %q, want gzip", got)
+				}
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(tt.body)
+			})
+
+			accessPass, err := client.AccessPasses.Get("pass_123")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if accessPass.ID != "pass_123" {
+				t.Errorf("ID = %q, want pass_123", accessPass.ID)
+			}
+		})
+	}
+}
+
+func TestGzipApplePassDownload(t *testing.T) {
+	pkpass := "PK\x03\x04pass-contents"
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{
+			name: "uncompressed pkpass is untouched",
+			body: []byte(pkpass),
+		},
+		{
+			name:     "gzip-encoded pkpass is decompressed",
+			encoding: "gzip",
+			body: func() []byte {
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				zw.Write([]byte(pkpass))
+				zw.Close()
+				return buf.Bytes()
+			}(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", ApplePassContentType)
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				w.Write(tt.body)
+			})
+
+			got, _, err := client.AccessPasses.DownloadApplePass("pass_123")
+			if err != nil {
+				t.Fatalf("DownloadApplePass() error = %v", err)
+			}
+			if string(got) != pkpass {
+				t.Errorf("DownloadApplePass() = %q, want %q", got, pkpass)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package doorpasses
+
+import "context"
+
+// concurrencyLimiter bounds the number of calls in flight across a client. A
+// nil *concurrencyLimiter allows every call.
+type concurrencyLimiter struct {
+	slots    chan struct{}
+	failFast bool
+}
+
+// newConcurrencyLimiter returns a limiter allowing limit calls at once, or nil
+// when limit is not positive
+func newConcurrencyLimiter(limit int, failFast bool) *concurrencyLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &concurrencyLimiter{slots: make(chan struct{}, limit), failFast: failFast}
+}
+
+// acquire takes a slot, waiting for one to free up until ctx is done. With
+// failFast it fails with ErrTooManyConcurrentRequests instead of waiting.
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if l.failFast {
+		select {
+		case l.slots <- struct{}{}:
+			return nil
+		default:
+			return ErrTooManyConcurrentRequests
+		}
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees the slot taken by acquire
+func (l *concurrencyLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
@@ -0,0 +1,89 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrentRequests(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	client := newTestClient(t, &Config{MaxConcurrentRequests: 2}, func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			old := peak.Load()
+			if current <= old || peak.CompareAndSwap(old, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.HealthWithContext(context.Background()); err != nil {
+				t.Errorf("HealthWithContext() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got != 2 {
+		t.Errorf("peak in-flight requests = %d, want 2", got)
+	}
+}
+
+func TestMaxConcurrentRequestsFull(t *testing.T) {
+	tests := []struct {
+		name     string
+		failFast bool
+		wantErr  error
+	}{
+		{name: "fail fast", failFast: true, wantErr: ErrTooManyConcurrentRequests},
+		{name: "wait until cancelled", wantErr: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entered := make(chan struct{})
+			unblock := make(chan struct{})
+			var requests atomic.Int32
+			config := &Config{MaxConcurrentRequests: 1, FailFastOnConcurrencyLimit: tt.failFast}
+			client := newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == 1 {
+					close(entered)
+				}
+				<-unblock
+				w.Write([]byte(`{"success": true, "data": {}}`))
+			})
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				client.HealthWithContext(context.Background())
+			}()
+			<-entered
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			if _, err := client.HealthWithContext(ctx); !errors.Is(err, tt.wantErr) {
+				t.Errorf("HealthWithContext() error = %v, want %v", err, tt.wantErr)
+			}
+
+			close(unblock)
+			<-done
+			if got := requests.Load(); got != 1 {
+				t.Errorf("server received %d requests, want 1", got)
+			}
+		})
+	}
+}
@@ -1,12 +1,50 @@
 package doorpasses
 
-import "fmt"
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxTemplateImageBytes is the largest image that fits, base64-encoded, in
+// the API's 10MB limit on request bodies
+const maxTemplateImageBytes = 10 << 20 / 4 * 3
 
 // Console provides methods for managing card templates (Enterprise only)
 type Console struct {
 	http *HTTPClient
+
+	// passes lists the passes of a template for ExportPasses
+	passes *AccessPasses
+
+	// templateRefs caches the template ID ResolveTemplate found for each
+	// external reference
+	templateRefs sync.Map
+
+	// templateSchemas caches the templates ValidateIssueParams fetched, by
+	// ID, for templateSchemaTTL
+	templateSchemas sync.Map
 }
 
+// templateSchemaTTL is how long ValidateIssueParams reuses a fetched template
+const templateSchemaTTL = time.Minute
+
+// cachedTemplate is a template cached by ValidateIssueParams
+type cachedTemplate struct {
+	template *CardTemplate
+	fetched  time.Time
+}
+
+// displayFields are the access pass attributes a card template can show.
+// Setting one the template doesn't show is reported by ValidateIssueParams.
+var displayFields = []string{"employeeId", "email", "phoneNumber", "classification", "employeePhoto", "title"}
+
 // newConsole creates a new Console resource
 func newConsole(httpClient *HTTPClient) *Console {
 	return &Console{
@@ -14,20 +52,93 @@ func newConsole(httpClient *HTTPClient) *Console {
 	}
 }
 
+// ResolveTemplate returns the ID of the card template whose ExternalRef is
+// ref. Resolved references are cached for the life of the client.
+// Requires Enterprise tier
+func (c *Console) ResolveTemplate(ref string, opts ...RequestOption) (string, error) {
+	return c.ResolveTemplateWithContext(context.Background(), ref, opts...)
+}
+
+// ResolveTemplateWithContext returns the ID of the card template whose
+// ExternalRef is ref, aborting if ctx is done. It returns an error wrapping
+// ErrTemplateRefNotFound when no template matches.
+// Requires Enterprise tier
+func (c *Console) ResolveTemplateWithContext(ctx context.Context, ref string, opts ...RequestOption) (string, error) {
+	opts = withOperation(opts, "Console.ResolveTemplate")
+	if ref == "" {
+		return "", fmt.Errorf("ref is required")
+	}
+	if id, ok := c.templateRefs.Load(ref); ok {
+		return id.(string), nil
+	}
+
+	params := &ListCardTemplatesParams{}
+	for {
+		page, err := c.ListTemplatesPageWithContext(ctx, params, opts...)
+		if err != nil {
+			return "", err
+		}
+		for _, template := range page.Items {
+			if template.ExternalRef == ref {
+				c.templateRefs.Store(ref, template.ID)
+				return template.ID, nil
+			}
+		}
+		if !page.HasMore || page.NextCursor == "" {
+			return "", fmt.Errorf("%w: %q", ErrTemplateRefNotFound, ref)
+		}
+		params.Cursor = page.NextCursor
+	}
+}
+
 // CreateTemplate creates a new card template
 // Requires Enterprise tier
-func (c *Console) CreateTemplate(params CreateCardTemplateParams) (*CardTemplate, error) {
+func (c *Console) CreateTemplate(params CreateCardTemplateParams, opts ...RequestOption) (*CardTemplate, error) {
+	return c.CreateTemplateWithContext(context.Background(), params, opts...)
+}
+
+// CreateTemplateWithContext creates a new card template, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) CreateTemplateWithContext(ctx context.Context, params CreateCardTemplateParams, opts ...RequestOption) (*CardTemplate, error) {
+	opts = withOperation(opts, "Console.CreateTemplate")
 	var result CardTemplate
-	err := c.http.Post("/v1/console/card-templates", params, &result)
+	err := c.http.PostWithContext(ctx, "/v1/console/card-templates", params, &result, opts...)
 	if err != nil {
-		return nil, err
+		return nil, enterpriseError(err)
 	}
 	return &result, nil
 }
 
+// EncodeTemplateImage reads an image from r and base64-encodes it for the
+// image fields of CardTemplateDesign. The API only accepts images inlined in
+// the JSON request body, so they can't be streamed; encoding as r is read
+// avoids holding a second, raw copy of the image in memory.
+func EncodeTemplateImage(r io.Reader) (string, error) {
+	var encoded strings.Builder
+	encoder := base64.NewEncoder(base64.StdEncoding, &encoded)
+	n, err := io.Copy(encoder, io.LimitReader(r, maxTemplateImageBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read image: %w", err)
+	}
+	if n > maxTemplateImageBytes {
+		return "", fmt.Errorf("image exceeds the %d byte limit", maxTemplateImageBytes)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode image: %w", err)
+	}
+	return encoded.String(), nil
+}
+
 // ReadTemplate retrieves a card template by ID
 // Requires Enterprise tier
-func (c *Console) ReadTemplate(cardTemplateID string) (*CardTemplate, error) {
+func (c *Console) ReadTemplate(cardTemplateID string, opts ...RequestOption) (*CardTemplate, error) {
+	return c.ReadTemplateWithContext(context.Background(), cardTemplateID, opts...)
+}
+
+// ReadTemplateWithContext retrieves a card template by ID, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) ReadTemplateWithContext(ctx context.Context, cardTemplateID string, opts ...RequestOption) (*CardTemplate, error) {
+	opts = withOperation(opts, "Console.ReadTemplate")
 	if cardTemplateID == "" {
 		return nil, fmt.Errorf("cardTemplateId is required")
 	}
@@ -37,46 +148,143 @@ func (c *Console) ReadTemplate(cardTemplateID string) (*CardTemplate, error) {
 	}
 
 	var result CardTemplate
-	err := c.http.Get(fmt.Sprintf("/v1/console/card-templates/%s", cardTemplateID), sigPayload, &result)
+	err := c.http.GetWithContext(ctx, fmt.Sprintf("/v1/console/card-templates/%s", cardTemplateID), sigPayload, &result, opts...)
+	if err != nil {
+		return nil, enterpriseError(err)
+	}
+	return &result, nil
+}
+
+// GetTemplate retrieves a card template by ID. It is equivalent to
+// ReadTemplate.
+// Requires Enterprise tier
+func (c *Console) GetTemplate(cardTemplateID string, opts ...RequestOption) (*CardTemplate, error) {
+	return c.ReadTemplateWithContext(context.Background(), cardTemplateID, opts...)
+}
+
+// GetTemplateWithContext retrieves a card template by ID, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) GetTemplateWithContext(ctx context.Context, cardTemplateID string, opts ...RequestOption) (*CardTemplate, error) {
+	opts = withOperation(opts, "Console.GetTemplate")
+	return c.ReadTemplateWithContext(ctx, cardTemplateID, opts...)
+}
+
+// ListTemplates retrieves card templates with optional filtering. Only the
+// page selected by params.Cursor is returned; use ListTemplatesPage to read
+// the cursor for the next page.
+// Requires Enterprise tier
+func (c *Console) ListTemplates(params *ListCardTemplatesParams, opts ...RequestOption) ([]CardTemplate, error) {
+	return c.ListTemplatesWithContext(context.Background(), params, opts...)
+}
+
+// ListTemplatesWithContext retrieves card templates with optional filtering, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) ListTemplatesWithContext(ctx context.Context, params *ListCardTemplatesParams, opts ...RequestOption) ([]CardTemplate, error) {
+	opts = withOperation(opts, "Console.ListTemplates")
+	page, err := c.ListTemplatesPageWithContext(ctx, params, opts...)
 	if err != nil {
 		return nil, err
 	}
+	return page.Items, nil
+}
+
+// ListTemplatesPage retrieves a single page of card templates. Pass the
+// returned NextCursor back in params.Cursor to fetch the following page.
+// Requires Enterprise tier
+func (c *Console) ListTemplatesPage(params *ListCardTemplatesParams, opts ...RequestOption) (*CardTemplatePage, error) {
+	return c.ListTemplatesPageWithContext(context.Background(), params, opts...)
+}
+
+// ListTemplatesPageWithContext retrieves a single page of card templates, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) ListTemplatesPageWithContext(ctx context.Context, params *ListCardTemplatesParams, opts ...RequestOption) (*CardTemplatePage, error) {
+	opts = withOperation(opts, "Console.ListTemplatesPage")
+	var result CardTemplatePage
+	err := c.http.GetWithContext(ctx, "/v1/console/card-templates", params.sigPayload(), &result, opts...)
+	if err != nil {
+		return nil, enterpriseError(err)
+	}
 	return &result, nil
 }
 
 // UpdateTemplate updates an existing card template
 // Requires Enterprise tier
-func (c *Console) UpdateTemplate(params UpdateCardTemplateParams) (*CardTemplate, error) {
+func (c *Console) UpdateTemplate(params UpdateCardTemplateParams, opts ...RequestOption) (*CardTemplate, error) {
+	return c.UpdateTemplateWithContext(context.Background(), params, opts...)
+}
+
+// UpdateTemplateWithContext updates an existing card template, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) UpdateTemplateWithContext(ctx context.Context, params UpdateCardTemplateParams, opts ...RequestOption) (*CardTemplate, error) {
+	opts = withOperation(opts, "Console.UpdateTemplate")
 	if params.CardTemplateID == "" {
 		return nil, fmt.Errorf("cardTemplateId is required")
 	}
 
 	var result CardTemplate
-	err := c.http.Patch(fmt.Sprintf("/v1/console/card-templates/%s", params.CardTemplateID), params, &result)
+	err := c.http.PatchWithContext(ctx, fmt.Sprintf("/v1/console/card-templates/%s", params.CardTemplateID), params, &result, opts...)
 	if err != nil {
-		return nil, err
+		return nil, enterpriseError(err)
 	}
+	c.forgetTemplate(params.CardTemplateID)
 	return &result, nil
 }
 
 // PublishTemplate publishes a card template
 // Requires Enterprise tier
-func (c *Console) PublishTemplate(cardTemplateID string) (*SuccessResponse, error) {
+func (c *Console) PublishTemplate(cardTemplateID string, opts ...RequestOption) (*SuccessResponse, error) {
+	return c.PublishTemplateWithContext(context.Background(), cardTemplateID, opts...)
+}
+
+// PublishTemplateWithContext publishes a card template, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) PublishTemplateWithContext(ctx context.Context, cardTemplateID string, opts ...RequestOption) (*SuccessResponse, error) {
+	opts = withOperation(opts, "Console.PublishTemplate")
 	if cardTemplateID == "" {
 		return nil, fmt.Errorf("cardTemplateId is required")
 	}
 
 	var result SuccessResponse
-	err := c.http.Post(fmt.Sprintf("/v1/console/card-templates/%s/publish", cardTemplateID), nil, &result)
+	err := c.http.PostWithContext(ctx, fmt.Sprintf("/v1/console/card-templates/%s/publish", cardTemplateID), nil, &result, opts...)
 	if err != nil {
-		return nil, err
+		return nil, enterpriseError(err)
+	}
+	return &result, nil
+}
+
+// DeleteTemplate permanently deletes a card template
+// Requires Enterprise tier
+func (c *Console) DeleteTemplate(cardTemplateID string, opts ...RequestOption) (*SuccessResponse, error) {
+	return c.DeleteTemplateWithContext(context.Background(), cardTemplateID, opts...)
+}
+
+// DeleteTemplateWithContext permanently deletes a card template, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) DeleteTemplateWithContext(ctx context.Context, cardTemplateID string, opts ...RequestOption) (*SuccessResponse, error) {
+	opts = withOperation(opts, "Console.DeleteTemplate")
+	if cardTemplateID == "" {
+		return nil, fmt.Errorf("cardTemplateId is required")
 	}
+
+	var result SuccessResponse
+	err := c.http.PostWithContext(ctx, fmt.Sprintf("/v1/console/card-templates/%s/delete", cardTemplateID), nil, &result, opts...)
+	if err != nil {
+		return nil, enterpriseError(err)
+	}
+	c.forgetTemplate(cardTemplateID)
 	return &result, nil
 }
 
 // EventLog retrieves event logs for a card template
 // Requires Enterprise tier
-func (c *Console) EventLog(params ReadEventLogParams) ([]EventLogEntry, error) {
+func (c *Console) EventLog(params ReadEventLogParams, opts ...RequestOption) ([]EventLogEntry, error) {
+	return c.EventLogWithContext(context.Background(), params, opts...)
+}
+
+// EventLogWithContext retrieves event logs for a card template, aborting if ctx is done
+// Requires Enterprise tier
+func (c *Console) EventLogWithContext(ctx context.Context, params ReadEventLogParams, opts ...RequestOption) ([]EventLogEntry, error) {
+	opts = withOperation(opts, "Console.EventLog")
 	if params.CardTemplateID == "" {
 		return nil, fmt.Errorf("cardTemplateId is required")
 	}
@@ -101,9 +309,114 @@ func (c *Console) EventLog(params ReadEventLogParams) ([]EventLogEntry, error) {
 	}
 
 	var result []EventLogEntry
-	err := c.http.Get(fmt.Sprintf("/v1/console/card-templates/%s/logs", params.CardTemplateID), sigPayload, &result)
+	err := c.http.GetWithContext(ctx, fmt.Sprintf("/v1/console/card-templates/%s/logs", params.CardTemplateID), sigPayload, &result, opts...)
 	if err != nil {
-		return nil, err
+		return nil, enterpriseError(err)
 	}
 	return result, nil
 }
+
+// ValidateIssueParams checks params against the card template before
+// issuing, so a mismatch is caught without a failed issuance. Along with
+// the checks of IssueAccessPassParams.Validate, it reports the fields the
+// template shows that params leave empty, and the fields params set that
+// the template doesn't show, in a *ValidationError. Templates are cached
+// for a minute, so validating a bulk run fetches each template once.
+// Requires Enterprise tier
+func (c *Console) ValidateIssueParams(cardTemplateID string, params IssueAccessPassParams, opts ...RequestOption) error {
+	return c.ValidateIssueParamsWithContext(context.Background(), cardTemplateID, params, opts...)
+}
+
+// ValidateIssueParamsWithContext checks params against the card template,
+// aborting if ctx is done
+func (c *Console) ValidateIssueParamsWithContext(ctx context.Context, cardTemplateID string, params IssueAccessPassParams, opts ...RequestOption) error {
+	opts = withOperation(opts, "Console.ValidateIssueParams")
+	template, err := c.templateSchema(ctx, cardTemplateID, opts)
+	if err != nil {
+		return err
+	}
+
+	params.CardTemplateID, params.CardTemplateRef = cardTemplateID, ""
+	errs := &ValidationError{}
+	var validationErr *ValidationError
+	if err := params.Validate(); err != nil && errors.As(err, &validationErr) {
+		errs.Fields = append(errs.Fields, validationErr.Fields...)
+	}
+	params, err = params.withFormattedDates()
+	if err != nil {
+		return err
+	}
+
+	values, err := paramValues(params)
+	if err != nil {
+		return err
+	}
+	shown := make(map[string]bool, len(template.Fields))
+	for _, field := range template.Fields {
+		shown[field.Key] = true
+		if values[field.Key] == nil && params.Metadata[field.Key] == nil {
+			errs.add(field.Key, FieldErrorRequired, fmt.Sprintf("is shown by card template %s but not set", cardTemplateID))
+		}
+	}
+	// A template listing no fields shows the defaults, so anything goes
+	if len(template.Fields) > 0 {
+		for _, key := range displayFields {
+			if values[key] != nil && !shown[key] {
+				errs.add(key, FieldErrorUnexpected, fmt.Sprintf("is not shown by card template %s", cardTemplateID))
+			}
+		}
+	}
+
+	return errs.errOrNil()
+}
+
+// templateSchema returns the card template, from the cache when it was
+// fetched less than templateSchemaTTL ago
+func (c *Console) templateSchema(ctx context.Context, cardTemplateID string, opts []RequestOption) (*CardTemplate, error) {
+	if cached, ok := c.templateSchemas.Load(cardTemplateID); ok {
+		if entry := cached.(cachedTemplate); time.Since(entry.fetched) < templateSchemaTTL {
+			return entry.template, nil
+		}
+	}
+	template, err := c.GetTemplateWithContext(ctx, cardTemplateID, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.templateSchemas.Store(cardTemplateID, cachedTemplate{template: template, fetched: time.Now()})
+	return template, nil
+}
+
+// paramValues returns the fields params sends, by JSON name
+func paramValues(params IssueAccessPassParams) (map[string]interface{}, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal params: %w", err)
+	}
+	return values, nil
+}
+
+// forgetTemplate drops the cached template and references that resolve to
+// cardTemplateID, since an updated or deleted template may no longer carry
+// them
+func (c *Console) forgetTemplate(cardTemplateID string) {
+	c.templateSchemas.Delete(cardTemplateID)
+	c.templateRefs.Range(func(ref, id interface{}) bool {
+		if id == cardTemplateID {
+			c.templateRefs.Delete(ref)
+		}
+		return true
+	})
+}
+
+// enterpriseError wraps err with ErrEnterpriseRequired when the API rejected
+// the request because the account lacks the Enterprise tier
+func enterpriseError(err error) error {
+	if isEnterpriseRequired(err) {
+		return fmt.Errorf("%w: %w", ErrEnterpriseRequired, err)
+	}
+	return err
+}
@@ -0,0 +1,321 @@
+package doorpasses
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+)
+
+func TestConsoleListTemplatesPage(t *testing.T) {
+	var gotPayload map[string]interface{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/console/card-templates" {
+			t.Errorf("request = %s %s, want GET /v1/console/card-templates", r.Method, r.URL.Path)
+		}
+		decoded, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		json.Unmarshal(decoded, &gotPayload)
+		w.Write([]byte(`{"success": true, "data": {
+			"items": [{"id": "template_1", "name": "Employee Badge", "fields": [{"key": "fullName", "label": "Name", "position": "front"}]}],
+			"nextCursor": "cursor_2",
+			"hasMore": true
+		}}`))
+	})
+
+	page, err := client.Console.ListTemplatesPage(&ListCardTemplatesParams{Platform: PlatformApple, Limit: 10, Cursor: "cursor_1"})
+	if err != nil {
+		t.Fatalf("ListTemplatesPage() error = %v", err)
+	}
+
+	if gotPayload["platform"] != string(PlatformApple) || gotPayload["limit"] != float64(10) || gotPayload["cursor"] != "cursor_1" {
+		t.Errorf("sig_payload = %v", gotPayload)
+	}
+	if len(page.Items) != 1 || page.Items[0].ID != "template_1" {
+		t.Fatalf("Items = %+v", page.Items)
+	}
+	if fields := page.Items[0].Fields; len(fields) != 1 || fields[0].Position != FieldPositionFront {
+		t.Errorf("Fields = %+v", fields)
+	}
+	if page.NextCursor != "cursor_2" || !page.HasMore {
+		t.Errorf("NextCursor = %q, HasMore = %v", page.NextCursor, page.HasMore)
+	}
+}
+
+func TestConsoleListTemplatesFilters(t *testing.T) {
+	var gotPayload map[string]interface{}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		decoded, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		json.Unmarshal(decoded, &gotPayload)
+		w.Write([]byte(`{"success": true, "data": {"items": [
+			{"id": "template_1", "platform": "apple", "status": "PUBLISHED", "supportedWallets": ["apple", "google"]}
+		]}}`))
+	})
+
+	templates, err := client.Console.ListTemplates(&ListCardTemplatesParams{Status: TemplateStatusPublished, WalletType: WalletTypeBoth})
+	if err != nil {
+		t.Fatalf("ListTemplates() error = %v", err)
+	}
+
+	if gotPayload["status"] != "PUBLISHED" || gotPayload["wallet_type"] != "both" {
+		t.Errorf("sig_payload = %v, want status and wallet_type", gotPayload)
+	}
+	if len(templates) != 1 {
+		t.Fatalf("templates = %+v", templates)
+	}
+	template := templates[0]
+	if !template.Usable() || template.Status != TemplateStatusPublished {
+		t.Errorf("Status = %q, want usable PUBLISHED", template.Status)
+	}
+	if !template.SupportsWallet(PlatformGoogle) {
+		t.Errorf("SupportedWallets = %v, want google included", template.SupportedWallets)
+	}
+}
+
+func TestConsoleResolveTemplate(t *testing.T) {
+	var lists atomic.Int32
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/v1/console/card-templates" {
+			t.Errorf("request = %s %s, want GET /v1/console/card-templates", r.Method, r.URL.Path)
+		}
+		lists.Add(1)
+		var payload map[string]interface{}
+		decoded, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		json.Unmarshal(decoded, &payload)
+		if payload["cursor"] == nil {
+			w.Write([]byte(`{"success": true, "data": {"items": [{"id": "template_1", "externalRef": "visitor"}], "nextCursor": "cursor_2", "hasMore": true}}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "data": {"items": [{"id": "template_2", "externalRef": "employee-badge"}], "hasMore": false}}`))
+	})
+
+	id, err := client.Console.ResolveTemplate("employee-badge")
+	if err != nil {
+		t.Fatalf("ResolveTemplate() error = %v", err)
+	}
+	if id != "template_2" {
+		t.Errorf("ResolveTemplate() = %q, want template_2", id)
+	}
+	if got := lists.Load(); got != 2 {
+		t.Errorf("listed %d pages, want 2", got)
+	}
+
+	// A second lookup is served from the cache
+	if id, err := client.Console.ResolveTemplate("employee-badge"); err != nil || id != "template_2" {
+		t.Errorf("cached ResolveTemplate() = %q, %v", id, err)
+	}
+	if got := lists.Load(); got != 2 {
+		t.Errorf("listed %d pages after cached lookup, want 2", got)
+	}
+
+	_, err = client.Console.ResolveTemplate("contractor")
+	if !errors.Is(err, ErrTemplateRefNotFound) {
+		t.Errorf("ResolveTemplate(unknown) error = %v, want ErrTemplateRefNotFound", err)
+	}
+
+	if _, err := client.Console.ResolveTemplate(""); err == nil {
+		t.Error("ResolveTemplate(\"\") error = nil, want error")
+	}
+}
+
+func TestConsoleValidateIssueParams(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v1/console/card-templates/template_123" {
+			t.Errorf("path = %s, want /v1/console/card-templates/template_123", r.URL.Path)
+		}
+		w.Write([]byte(`{"success": true, "data": {"id": "template_123", "fields": [
+			{"key": "fullName", "label": "Name"},
+			{"key": "email", "label": "Email"},
+			{"key": "employeeId", "label": "Employee ID"},
+			{"key": "department", "label": "Department"}
+		]}}`))
+	})
+
+	matching := func() IssueAccessPassParams {
+		params := validIssueParams()
+		params.CardTemplateID = ""
+		params.EmployeeID = "emp_1"
+		params.Metadata = map[string]interface{}{"department": "Engineering"}
+		return params
+	}
+
+	tests := []struct {
+		name       string
+		params     func() IssueAccessPassParams
+		wantFields []FieldError
+	}{
+		{
+			name:   "matching",
+			params: matching,
+		},
+		{
+			name: "missing field",
+			params: func() IssueAccessPassParams {
+				params := matching()
+				params.EmployeeID = ""
+				delete(params.Metadata, "department")
+				return params
+			},
+			wantFields: []FieldError{
+				{Field: "employeeId", Code: FieldErrorRequired, Message: "is shown by card template template_123 but not set"},
+				{Field: "department", Code: FieldErrorRequired, Message: "is shown by card template template_123 but not set"},
+			},
+		},
+		{
+			name: "extra field",
+			params: func() IssueAccessPassParams {
+				params := matching()
+				params.Title = "Engineer"
+				return params
+			},
+			wantFields: []FieldError{
+				{Field: "title", Code: FieldErrorUnexpected, Message: "is not shown by card template template_123"},
+			},
+		},
+		{
+			name: "invalid params",
+			params: func() IssueAccessPassParams {
+				params := matching()
+				params.Email = "not-an-email"
+				return params
+			},
+			wantFields: []FieldError{
+				{Field: "email", Code: FieldErrorInvalidFormat, Message: "is not a valid email address"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := client.Console.ValidateIssueParams("template_123", tt.params())
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Errorf("ValidateIssueParams() error = %v", err)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidateIssueParams() error = %v, want a *ValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tt.wantFields) {
+				t.Errorf("Fields = %+v, want %+v", validationErr.Fields, tt.wantFields)
+			}
+		})
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server received %d requests, want the template fetched once", got)
+	}
+}
+
+func TestConsoleDeleteTemplate(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/console/card-templates/template_1/delete" {
+			t.Errorf("request = %s %s, want POST /v1/console/card-templates/template_1/delete", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"success": true, "message": "Card template deleted"}`))
+	})
+
+	result, err := client.Console.DeleteTemplate("template_1")
+	if err != nil {
+		t.Fatalf("DeleteTemplate() error = %v", err)
+	}
+	if !result.Success {
+		t.Errorf("Success = false, want true")
+	}
+
+	if _, err := client.Console.DeleteTemplate(""); err == nil {
+		t.Errorf("DeleteTemplate(\"\") error = nil, want error")
+	}
+}
+
+func TestConsoleEnterpriseRequired(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		want       bool
+	}{
+		{
+			name:       "enterprise tier required",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"success": false, "error": {"code": "UNAUTHORIZED", "message": "Enterprise tier required"}}`,
+			want:       true,
+		},
+		{
+			name:       "forbidden",
+			statusCode: http.StatusForbidden,
+			body:       `{"success": false, "error": {"code": "FORBIDDEN", "message": "Forbidden"}}`,
+			want:       true,
+		},
+		{
+			name:       "invalid signature",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"success": false, "error": {"code": "UNAUTHORIZED", "message": "Invalid signature"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			})
+
+			_, err := client.Console.GetTemplate("template_1")
+			if got := errors.Is(err, ErrEnterpriseRequired); got != tt.want {
+				t.Errorf("errors.Is(err, ErrEnterpriseRequired) = %v, want %v (err = %v)", got, tt.want, err)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.statusCode {
+				t.Errorf("GetTemplate() error = %v, want APIError with status %d", err, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestEncodeTemplateImage(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage-data")
+
+	tests := []struct {
+		name    string
+		r       io.Reader
+		want    string
+		wantErr bool
+	}{
+		{name: "image", r: bytes.NewReader(png), want: base64.StdEncoding.EncodeToString(png)},
+		{name: "empty", r: bytes.NewReader(nil), want: ""},
+		{name: "too large", r: io.LimitReader(zeroReader{}, maxTemplateImageBytes+1), wantErr: true},
+		{name: "read error", r: iotest.ErrReader(errors.New("disk failure")), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EncodeTemplateImage(tt.r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncodeTemplateImage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EncodeTemplateImage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// zeroReader reads an endless stream of zero bytes
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+	}
+	return len(p), nil
+}
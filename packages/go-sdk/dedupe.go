@@ -0,0 +1,85 @@
+package doorpasses
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// issueGroup coalesces concurrent issue calls that share an idempotency key,
+// in the manner of golang.org/x/sync/singleflight
+type issueGroup struct {
+	mu    sync.Mutex
+	calls map[string]*issueCall
+}
+
+// issueCall is an issue request in flight
+type issueCall struct {
+	// fingerprint is the JSON of the params, which every coalesced call
+	// must match
+	fingerprint string
+
+	// done is closed once pass and err are set
+	done chan struct{}
+	pass *AccessPass
+	err  error
+}
+
+// do calls issue unless a call with the same idempotency key is in
+// flight, in which case it waits for that call and returns a copy of its
+// pass marked Replayed. A waiting caller stops waiting when its ctx is done.
+func (g *issueGroup) do(ctx context.Context, params IssueAccessPassParams, issue func() (*AccessPass, error)) (*AccessPass, error) {
+	fingerprint, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal params: %w", err)
+	}
+	key := params.IdempotencyKey
+
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		if call.fingerprint != string(fingerprint) {
+			return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+		}
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+		shared := *call.pass
+		shared.Replayed, shared.NewlyCreated = true, false
+		return &shared, nil
+	}
+
+	if g.calls == nil {
+		g.calls = make(map[string]*issueCall)
+	}
+	call := &issueCall{
+		fingerprint: string(fingerprint),
+		done:        make(chan struct{}),
+		// Seen by waiting callers only if issue panics
+		err: fmt.Errorf("coalesced issue call for %s did not complete", key),
+	}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+
+	call.pass, call.err = issue()
+	if call.err != nil {
+		return nil, call.err
+	}
+	// Waiting callers copy call.pass, so the caller must not get it to
+	// write to
+	result := *call.pass
+	return &result, nil
+}
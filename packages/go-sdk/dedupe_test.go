@@ -0,0 +1,109 @@
+package doorpasses
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIssueDedupe(t *testing.T) {
+	tests := []struct {
+		name         string
+		dedupe       bool
+		wantRequests int32
+		wantReplayed int
+	}{
+		{name: "coalesced", dedupe: true, wantRequests: 1, wantReplayed: 4},
+		{name: "disabled", wantRequests: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entered := make(chan struct{})
+			unblock := make(chan struct{})
+			var requests atomic.Int32
+			client := newTestClient(t, &Config{Dedupe: tt.dedupe}, func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == 1 {
+					close(entered)
+				}
+				<-unblock
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			params := validIssueParams()
+			params.IdempotencyKey = "event_42"
+
+			var mu sync.Mutex
+			var replayed int
+			var wg sync.WaitGroup
+			issue := func() {
+				defer wg.Done()
+				accessPass, err := client.AccessPasses.Issue(params)
+				if err != nil {
+					t.Errorf("Issue() error = %v", err)
+					return
+				}
+				if accessPass.ID != "pass_123" {
+					t.Errorf("ID = %q, want pass_123", accessPass.ID)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				if accessPass.Replayed {
+					replayed++
+				}
+			}
+
+			wg.Add(1)
+			go issue()
+			<-entered
+			for i := 0; i < 4; i++ {
+				wg.Add(1)
+				go issue()
+			}
+			// Give the others time to join the call in flight
+			time.Sleep(50 * time.Millisecond)
+			close(unblock)
+			wg.Wait()
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if replayed != tt.wantReplayed {
+				t.Errorf("replayed results = %d, want %d", replayed, tt.wantReplayed)
+			}
+		})
+	}
+}
+
+func TestIssueDedupeDifferentParams(t *testing.T) {
+	entered := make(chan struct{})
+	unblock := make(chan struct{})
+	client := newTestClient(t, &Config{Dedupe: true}, func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-unblock
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	params := validIssueParams()
+	params.IdempotencyKey = "event_42"
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := client.AccessPasses.Issue(params); err != nil {
+			t.Errorf("Issue() error = %v", err)
+		}
+	}()
+	<-entered
+
+	other := params
+	other.FullName = "Jane Doe"
+	if _, err := client.AccessPasses.Issue(other); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Issue() error = %v, want ErrIdempotencyKeyReused", err)
+	}
+	close(unblock)
+	<-done
+}
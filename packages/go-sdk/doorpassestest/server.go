@@ -0,0 +1,523 @@
+// Package doorpassestest provides a fake DoorPasses API server for testing
+// code that uses the SDK
+//
+// Example:
+//
+//	server := doorpassestest.New()
+//	defer server.Close()
+//
+//	server.Enqueue(doorpassestest.RateLimited(time.Second))
+//	client := server.Client(nil)
+//
+//	accessPass, err := client.AccessPasses.Get("pass_123")
+package doorpassestest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	doorpasses "github.com/mohammedzamakhan/doorpasses/packages/go-sdk"
+)
+
+// Credentials accepted by the server, used by Server.Client
+const (
+	AccountID    = "test_account"
+	SharedSecret = "test_secret"
+)
+
+// Response is a canned response returned by the server
+type Response struct {
+	StatusCode int
+	Header     http.Header
+
+	// Body is encoded as JSON unless it is a []byte
+	Body interface{}
+
+	// NetworkError drops the connection without responding
+	NetworkError bool
+}
+
+// Success returns a 200 response wrapping data in the API's envelope
+func Success(data interface{}) Response {
+	return Response{
+		StatusCode: http.StatusOK,
+		Body:       map[string]interface{}{"success": true, "data": data},
+	}
+}
+
+// Error returns an error response in the API's envelope
+func Error(statusCode int, code, message string) Response {
+	return Response{
+		StatusCode: statusCode,
+		Body: map[string]interface{}{
+			"success": false,
+			"error":   map[string]interface{}{"code": code, "message": message},
+		},
+	}
+}
+
+// RateLimited returns a 429 response asking the client to wait retryAfter
+func RateLimited(retryAfter time.Duration) Response {
+	resp := Error(http.StatusTooManyRequests, doorpasses.ErrorCodeRateLimitExceeded, "Too many requests")
+	resp.Header = http.Header{"Retry-After": {strconv.Itoa(int(retryAfter.Seconds()))}}
+	return resp
+}
+
+// ServerError returns a 5xx response
+func ServerError(statusCode int) Response {
+	return Error(statusCode, doorpasses.ErrorCodeInternal, http.StatusText(statusCode))
+}
+
+// NetworkError returns a response that drops the connection
+func NetworkError() Response {
+	return Response{NetworkError: true}
+}
+
+// Request is a request received by the server
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+
+	// Body is the decompressed request body
+	Body []byte
+
+	// SigPayload is the decoded sig_payload query parameter of a GET request
+	SigPayload map[string]interface{}
+}
+
+// Server is a fake DoorPasses API. Requests are answered from the queue of
+// canned responses first; once it is empty, an in-memory store of access
+// passes serves the access pass endpoints and GET /health.
+//
+// A Server is safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	queue    []Response
+	requests []Request
+	passes   map[string]doorpasses.AccessPass
+	nextID   int
+}
+
+// New starts a fake DoorPasses API server. Call Close when done.
+func New() *Server {
+	s := &Server{passes: make(map[string]doorpasses.AccessPass)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Client returns a client pointed at the server, using the server's
+// credentials. The optional config is copied and its BaseURL replaced.
+func (s *Server) Client(config *doorpasses.Config) *doorpasses.Client {
+	var c doorpasses.Config
+	if config != nil {
+		c = *config
+	}
+	c.BaseURL = s.URL
+
+	client, err := doorpasses.NewClient(AccountID, SharedSecret, &c)
+	if err != nil {
+		panic(fmt.Sprintf("doorpassestest: %v", err))
+	}
+	return client
+}
+
+// Enqueue adds canned responses, returned in order by the next requests
+func (s *Server) Enqueue(responses ...Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, responses...)
+}
+
+// AddAccessPass stores an access pass so it can be fetched and changed
+func (s *Server) AddAccessPass(accessPass doorpasses.AccessPass) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.passes[accessPass.ID] = accessPass
+}
+
+// Requests returns every request received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// LastRequest returns the most recent request, or false if none was received
+func (s *Server) LastRequest() (Request, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.requests) == 0 {
+		return Request{}, false
+	}
+	return s.requests[len(s.requests)-1], true
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	req, err := readRequest(r)
+	if err != nil {
+		writeResponse(w, Error(http.StatusBadRequest, doorpasses.ErrorCodeBadRequest, err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var resp Response
+	queued := len(s.queue) > 0
+	if queued {
+		resp, s.queue = s.queue[0], s.queue[1:]
+	}
+	s.mu.Unlock()
+
+	if !queued {
+		if !validSignature(r, req) {
+			resp = Error(http.StatusUnauthorized, doorpasses.ErrorCodeUnauthorized, "Invalid signature")
+		} else {
+			resp = s.route(req)
+		}
+	}
+
+	if resp.NetworkError {
+		dropConnection(w)
+		return
+	}
+	writeResponse(w, resp)
+}
+
+// route serves a request from the in-memory store
+func (s *Server) route(req Request) Response {
+	segments := strings.Split(strings.Trim(req.Path, "/"), "/")
+
+	switch {
+	case req.Method == http.MethodGet && req.Path == "/health":
+		return Response{StatusCode: http.StatusOK, Body: map[string]interface{}{"status": "healthy"}}
+
+	case len(segments) < 2 || segments[0] != "v1" || segments[1] != "access-passes":
+		return Error(http.StatusNotFound, doorpasses.ErrorCodeNotFound, "Route not found")
+
+	case len(segments) == 2 && req.Method == http.MethodPost:
+		return s.issue(req)
+
+	case len(segments) == 2 && req.Method == http.MethodGet:
+		return s.list(req)
+
+	case len(segments) == 3 && req.Method == http.MethodGet:
+		return s.withPass(segments[2], func(p *doorpasses.AccessPass) Response {
+			return Success(p)
+		})
+
+	case len(segments) == 3 && req.Method == http.MethodPatch:
+		return s.withPass(segments[2], func(p *doorpasses.AccessPass) Response {
+			if since, err := http.ParseTime(req.Header.Get("If-Unmodified-Since")); err == nil && p.Updated.After(since) {
+				return Error(http.StatusPreconditionFailed, doorpasses.ErrorCodePreconditionFailed, "Access pass was modified")
+			}
+			if err := json.Unmarshal(req.Body, p); err != nil {
+				return Error(http.StatusBadRequest, doorpasses.ErrorCodeValidation, err.Error())
+			}
+			p.ID = segments[2]
+			p.Updated = time.Now().UTC().Truncate(time.Second)
+			p.UpdatedAt = p.Updated.Format(time.RFC3339)
+			return Success(p)
+		})
+
+	case len(segments) == 4 && req.Method == http.MethodPost && segments[3] == "rotate-credential":
+		return s.rotateCredential(segments[2], req)
+
+	case len(segments) == 4 && req.Method == http.MethodPost:
+		return s.action(segments[2], segments[3])
+	}
+
+	return Error(http.StatusNotFound, doorpasses.ErrorCodeNotFound, "Route not found")
+}
+
+func (s *Server) issue(req Request) Response {
+	var params doorpasses.IssueAccessPassParams
+	if err := json.Unmarshal(req.Body, &params); err != nil {
+		return Error(http.StatusBadRequest, doorpasses.ErrorCodeValidation, err.Error())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dryRun := req.Header.Get("X-Dry-Run") == "true"
+	id := "pass_preview"
+	switch {
+	case params.ID != "":
+		if _, taken := s.passes[params.ID]; taken {
+			return Error(http.StatusConflict, doorpasses.ErrorCodeAccessPassIDTaken, "Access pass ID already exists")
+		}
+		id = params.ID
+	case !dryRun:
+		s.nextID++
+		id = fmt.Sprintf("pass_%d", s.nextID)
+	}
+	accessPass := doorpasses.AccessPass{
+		ID:             id,
+		CardTemplateID: params.CardTemplateID,
+		EmployeeID:     params.EmployeeID,
+		CardNumber:     params.CardNumber,
+		FullName:       params.FullName,
+		Email:          params.Email,
+		PhoneNumber:    params.PhoneNumber,
+		StartDate:      params.StartDate,
+		ExpirationDate: params.ExpirationDate,
+		Title:          params.Title,
+		State:          doorpasses.AccessPassStatePending,
+		Metadata:       params.Metadata,
+		DryRun:         dryRun,
+	}
+	if !dryRun {
+		s.passes[accessPass.ID] = accessPass
+	}
+
+	resp := Success(accessPass)
+	resp.StatusCode = http.StatusCreated
+	return resp
+}
+
+// passStates are the states the API sends and accepts as a list filter
+var passStates = map[string]bool{
+	"PENDING": true, "ACTIVE": true, "SUSPENDED": true,
+	"UNLINKED": true, "DELETED": true, "EXPIRED": true,
+}
+
+// list serves a page of access passes the way the API does: newest first,
+// filtered by template and uppercase state, with a pagination object
+func (s *Server) list(req Request) Response {
+	templateID, _ := req.SigPayload["template_id"].(string)
+	state, _ := req.SigPayload["state"].(string)
+	if state != "" && !passStates[state] {
+		return Error(http.StatusBadRequest, doorpasses.ErrorCodeValidation, fmt.Sprintf("Invalid state %q", state))
+	}
+	includeArchived, _ := req.SigPayload["include_archived"].(bool)
+	metadata, _ := req.SigPayload["metadata"].(map[string]interface{})
+	page, limit := 1, 50
+	if n, ok := req.SigPayload["page"].(float64); ok && n >= 1 {
+		page = int(n)
+	}
+	if n, ok := req.SigPayload["limit"].(float64); ok && n >= 1 {
+		limit = int(n)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]doorpasses.AccessPass, 0, len(s.passes))
+	for _, p := range s.passes {
+		if p.Archived && !includeArchived {
+			continue
+		}
+		if templateID != "" && p.CardTemplateID != templateID {
+			continue
+		}
+		if state != "" && strings.ToUpper(string(p.State)) != state {
+			continue
+		}
+		if !hasMetadata(p, metadata) {
+			continue
+		}
+		items = append(items, p)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].Created.Equal(items[j].Created) {
+			return items[i].Created.After(items[j].Created)
+		}
+		return items[i].ID < items[j].ID
+	})
+
+	total := len(items)
+	start := min((page-1)*limit, total)
+	return Success(map[string]interface{}{
+		"items": items[start:min(start+limit, total)],
+		"pagination": map[string]interface{}{
+			"page":       page,
+			"limit":      limit,
+			"total":      total,
+			"totalPages": (total + limit - 1) / limit,
+		},
+	})
+}
+
+// hasMetadata reports whether the metadata of p sets every key of want to
+// the same value
+func hasMetadata(p doorpasses.AccessPass, want map[string]interface{}) bool {
+	for key, value := range want {
+		if got, ok := p.Metadata[key].(string); !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) action(id, action string) Response {
+	// The API has no revoked state, so a revoked pass is deleted
+	states := map[string]doorpasses.AccessPassState{
+		"suspend": doorpasses.AccessPassStateSuspended,
+		"resume":  doorpasses.AccessPassStateActive,
+		"unlink":  doorpasses.AccessPassStateUnlinked,
+		"delete":  doorpasses.AccessPassStateDeleted,
+		"revoke":  doorpasses.AccessPassStateDeleted,
+		"expire":  doorpasses.AccessPassStateExpired,
+	}
+	state, ok := states[action]
+	if !ok {
+		return Error(http.StatusNotFound, doorpasses.ErrorCodeNotFound, "Route not found")
+	}
+
+	return s.withPass(id, func(p *doorpasses.AccessPass) Response {
+		if action == "revoke" && isRevoked(p) {
+			return Error(http.StatusConflict, doorpasses.ErrorCodePassRevoked, "Access pass is already revoked")
+		}
+		if action == "expire" {
+			switch p.State {
+			case doorpasses.AccessPassStateExpired:
+				return Error(http.StatusConflict, doorpasses.ErrorCodePassExpired, "Access pass has already expired")
+			case doorpasses.AccessPassStateRevoked, doorpasses.AccessPassStateDeleted:
+				return Error(http.StatusConflict, doorpasses.ErrorCodePassRevoked, "Access pass is revoked")
+			}
+			p.ExpirationDate = time.Now().UTC().Format(time.RFC3339)
+		}
+		p.State = state
+		return Success(p)
+	})
+}
+
+func (s *Server) rotateCredential(id string, req Request) Response {
+	var params struct {
+		CardNumber string `json:"cardNumber"`
+	}
+	if err := json.Unmarshal(req.Body, &params); err != nil || params.CardNumber == "" {
+		return Error(http.StatusBadRequest, doorpasses.ErrorCodeValidation, "cardNumber is required")
+	}
+
+	return s.withPass(id, func(p *doorpasses.AccessPass) Response {
+		if isRevoked(p) {
+			return Error(http.StatusConflict, doorpasses.ErrorCodePassRevoked, "Access pass is revoked")
+		}
+		p.CardNumber = params.CardNumber
+		p.CredentialRotatedAt = time.Now().UTC().Format(time.RFC3339)
+		return Success(p)
+	})
+}
+
+// isRevoked reports whether p was revoked, which the API records as
+// deleted
+func isRevoked(p *doorpasses.AccessPass) bool {
+	return p.State == doorpasses.AccessPassStateRevoked || p.State == doorpasses.AccessPassStateDeleted
+}
+
+// withPass calls fn with the stored access pass and saves its changes
+func (s *Server) withPass(id string, fn func(p *doorpasses.AccessPass) Response) Response {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	accessPass, ok := s.passes[id]
+	if !ok {
+		return Error(http.StatusNotFound, doorpasses.ErrorCodeAccessPassNotFound, "Access pass not found")
+	}
+	resp := fn(&accessPass)
+	s.passes[id] = accessPass
+	return resp
+}
+
+// readRequest records a request, decompressing its body and decoding its
+// sig_payload
+func readRequest(r *http.Request) (Request, error) {
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return Request{}, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		body = zr
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return Request{}, err
+	}
+
+	req := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Header: r.Header.Clone(),
+		Body:   data,
+	}
+	if encoded := r.URL.Query().Get("sig_payload"); encoded != "" {
+		if decoded, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+			json.Unmarshal(decoded, &req.SigPayload)
+		}
+	}
+	return req, nil
+}
+
+// validSignature checks the request was signed with SharedSecret
+func validSignature(r *http.Request, req Request) bool {
+	if r.Header.Get("X-ACCT-ID") != AccountID {
+		return false
+	}
+
+	var encoded string
+	switch {
+	case r.Method == http.MethodGet:
+		encoded = r.URL.Query().Get("sig_payload")
+	case len(req.Body) > 0:
+		encoded = base64.StdEncoding.EncodeToString(req.Body)
+	}
+	if encoded == "" {
+		encoded = base64.StdEncoding.EncodeToString([]byte(`{"id":"0"}`))
+	}
+
+	sum := sha256.Sum256([]byte(SharedSecret + encoded))
+	return r.Header.Get("X-PAYLOAD-SIG") == fmt.Sprintf("%x", sum)
+}
+
+func writeResponse(w http.ResponseWriter, resp Response) {
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+
+	body, ok := resp.Body.([]byte)
+	if !ok && resp.Body != nil {
+		var err error
+		if body, err = json.Marshal(resp.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+	}
+
+	statusCode := resp.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
+	io.Copy(w, bytes.NewReader(body))
+}
+
+// dropConnection closes the connection without writing a response
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("doorpassestest: response writer does not support hijacking")
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(fmt.Sprintf("doorpassestest: %v", err))
+	}
+	conn.Close()
+}
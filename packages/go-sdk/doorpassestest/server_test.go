@@ -0,0 +1,357 @@
+package doorpassestest
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	doorpasses "github.com/mohammedzamakhan/doorpasses/packages/go-sdk"
+)
+
+func TestServerAccessPasses(t *testing.T) {
+	server := New()
+	defer server.Close()
+	client := server.Client(nil)
+
+	accessPass, err := client.AccessPasses.Issue(doorpasses.IssueAccessPassParams{
+		CardTemplateID: "template_123",
+		CardNumber:     "12345",
+		FullName:       "John Doe",
+		StartDate:      "2025-01-01T00:00:00Z",
+		ExpirationDate: "2026-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	got, err := client.AccessPasses.Get(accessPass.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.FullName != "John Doe" {
+		t.Errorf("Get() FullName = %q, want %q", got.FullName, "John Doe")
+	}
+
+	if _, err := client.AccessPasses.Suspend(accessPass.ID); err != nil {
+		t.Fatalf("Suspend() error = %v", err)
+	}
+	got, err = client.AccessPasses.Get(accessPass.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.State != doorpasses.AccessPassStateSuspended {
+		t.Errorf("State = %q, want %q", got.State, doorpasses.AccessPassStateSuspended)
+	}
+	if _, err := client.AccessPasses.SuspendPass(accessPass.ID); !errors.Is(err, doorpasses.ErrPassAlreadySuspended) {
+		t.Errorf("SuspendPass() error = %v, want ErrPassAlreadySuspended", err)
+	}
+
+	rotated, err := client.AccessPasses.RotateCredential(accessPass.ID, "67890")
+	if err != nil {
+		t.Fatalf("RotateCredential() error = %v", err)
+	}
+	if rotated.CardNumber != "67890" || rotated.CredentialRotated.IsZero() {
+		t.Errorf("RotateCredential() CardNumber = %q, CredentialRotated = %v", rotated.CardNumber, rotated.CredentialRotated)
+	}
+
+	expired, err := client.AccessPasses.ExpireNow(accessPass.ID)
+	if err != nil {
+		t.Fatalf("ExpireNow() error = %v", err)
+	}
+	if expired.State != doorpasses.AccessPassStateExpired {
+		t.Errorf("ExpireNow() State = %q, want %q", expired.State, doorpasses.AccessPassStateExpired)
+	}
+	if _, err := client.AccessPasses.ExpireNow(accessPass.ID); !errors.Is(err, doorpasses.ErrPassExpired) {
+		t.Errorf("second ExpireNow() error = %v, want ErrPassExpired", err)
+	}
+
+	if _, err := client.AccessPasses.Get("missing"); !doorpasses.IsNotFound(err) {
+		t.Errorf("Get(missing) error = %v, want not found", err)
+	}
+
+	req, ok := server.LastRequest()
+	if !ok {
+		t.Fatal("LastRequest() returned no request")
+	}
+	if req.Method != http.MethodGet || req.Path != "/v1/access-passes/missing" {
+		t.Errorf("LastRequest() = %s %s, want GET /v1/access-passes/missing", req.Method, req.Path)
+	}
+	if got := len(server.Requests()); got != 9 {
+		t.Errorf("len(Requests()) = %d, want 9", got)
+	}
+}
+
+func TestServerEnqueue(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []Response
+		wantErr   bool
+		wantCalls int
+	}{
+		{
+			name:      "canned success",
+			responses: []Response{Success(doorpasses.AccessPass{ID: "pass_123"})},
+			wantCalls: 1,
+		},
+		{
+			name:      "rate limit is retried",
+			responses: []Response{RateLimited(0), Success(doorpasses.AccessPass{ID: "pass_123"})},
+			wantCalls: 2,
+		},
+		{
+			name:      "server error is retried",
+			responses: []Response{ServerError(http.StatusServiceUnavailable), Success(doorpasses.AccessPass{ID: "pass_123"})},
+			wantCalls: 2,
+		},
+		{
+			name:      "network error is retried",
+			responses: []Response{NetworkError(), Success(doorpasses.AccessPass{ID: "pass_123"})},
+			wantCalls: 2,
+		},
+		{
+			name:      "retries exhausted",
+			responses: []Response{ServerError(http.StatusBadGateway), ServerError(http.StatusBadGateway)},
+			wantErr:   true,
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := New()
+			defer server.Close()
+			server.Enqueue(tt.responses...)
+
+			client := server.Client(&doorpasses.Config{
+				MaxRetries:   1,
+				RetryBackoff: func(int) time.Duration { return 0 },
+			})
+
+			accessPass, err := client.AccessPasses.Get("pass_123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && accessPass.ID != "pass_123" {
+				t.Errorf("Get() ID = %q, want %q", accessPass.ID, "pass_123")
+			}
+			if got := len(server.Requests()); got != tt.wantCalls {
+				t.Errorf("len(Requests()) = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestServerRejectsInvalidSignature(t *testing.T) {
+	server := New()
+	defer server.Close()
+
+	client, err := doorpasses.NewClient(AccountID, "wrong_secret", &doorpasses.Config{BaseURL: server.URL})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	_, err = client.AccessPasses.Get("pass_123")
+	var apiErr *doorpasses.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Get() error = %v, want 401", err)
+	}
+}
+
+func TestServerDryRun(t *testing.T) {
+	server := New()
+	defer server.Close()
+	client := server.Client(nil)
+
+	accessPass, err := client.AccessPasses.Issue(doorpasses.IssueAccessPassParams{
+		CardTemplateID: "template_123",
+		CardNumber:     "12345",
+		FullName:       "John Doe",
+		StartDate:      "2025-01-01T00:00:00Z",
+		ExpirationDate: "2026-01-01T00:00:00Z",
+	}, doorpasses.WithDryRun())
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if !accessPass.DryRun {
+		t.Error("Issue() result is not marked as a dry run")
+	}
+
+	list, err := client.AccessPasses.List(nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 0 {
+		t.Errorf("List() returned %d passes after a dry run, want 0", len(list))
+	}
+}
+
+func TestServerListArchived(t *testing.T) {
+	server := New()
+	defer server.Close()
+	client := server.Client(nil)
+
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_active", State: doorpasses.AccessPassStateActive})
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_archived", State: doorpasses.AccessPassStateRevoked, Archived: true})
+
+	list, err := client.AccessPasses.List(nil)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "pass_active" {
+		t.Errorf("List() = %+v, want only pass_active", list)
+	}
+
+	list, err = client.AccessPasses.List(&doorpasses.ListAccessPassesParams{IncludeArchived: true})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 2 {
+		t.Errorf("List() returned %d passes with IncludeArchived, want 2", len(list))
+	}
+}
+
+// bodyRecorder is a transport keeping the last response body it read
+type bodyRecorder struct {
+	body []byte
+}
+
+func (b *bodyRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	b.body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(b.body))
+	return resp, nil
+}
+
+func TestServerListWireFormat(t *testing.T) {
+	server := New()
+	defer server.Close()
+	recorder := &bodyRecorder{}
+	client := server.Client(&doorpasses.Config{HTTPClient: &http.Client{Transport: recorder}})
+
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_a", State: doorpasses.AccessPassStateActive})
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_b", State: doorpasses.AccessPassStateSuspended})
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_c", State: doorpasses.AccessPassStateActive})
+
+	page, err := client.AccessPasses.ListPage(&doorpasses.ListAccessPassesParams{State: doorpasses.AccessPassStateActive, Limit: 1})
+	if err != nil {
+		t.Fatalf("ListPage() error = %v", err)
+	}
+	if len(page.Items) != 1 || page.Items[0].State != doorpasses.AccessPassStateActive {
+		t.Errorf("Items = %+v, want one active pass", page.Items)
+	}
+	if !page.HasTotal || page.TotalCount != 2 {
+		t.Errorf("TotalCount, HasTotal = %d, %v, want 2, true", page.TotalCount, page.HasTotal)
+	}
+	// The API sends uppercase states and a pagination object, not hasMore
+	body := string(recorder.body)
+	for _, want := range []string{`"state":"ACTIVE"`, `"pagination":{"limit":1,"page":1,"total":2,"totalPages":2}`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body = %s, want it to contain %s", body, want)
+		}
+	}
+	if strings.Contains(body, "hasMore") {
+		t.Errorf("body = %s, want no hasMore", body)
+	}
+
+	_, err = client.AccessPasses.ListPage(&doorpasses.ListAccessPassesParams{State: "archived"})
+	if !doorpasses.IsValidation(err) {
+		t.Errorf("ListPage() with an unknown state error = %v, want a validation error", err)
+	}
+}
+
+func TestServerListMetadata(t *testing.T) {
+	server := New()
+	defer server.Close()
+	client := server.Client(nil)
+
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_a", Metadata: map[string]interface{}{"building": "A", "cohort": "2025"}})
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_b", Metadata: map[string]interface{}{"building": "B", "cohort": "2025"}})
+	server.AddAccessPass(doorpasses.AccessPass{ID: "pass_none"})
+
+	list, err := client.AccessPasses.List(&doorpasses.ListAccessPassesParams{
+		Metadata: map[string]string{"building": "A", "cohort": "2025"},
+	})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "pass_a" {
+		t.Errorf("List() = %+v, want only pass_a", list)
+	}
+}
+
+func TestServerIssueWithID(t *testing.T) {
+	server := New()
+	defer server.Close()
+	client := server.Client(nil)
+
+	params := doorpasses.IssueAccessPassParams{
+		ID:             "emp_42",
+		CardTemplateID: "template_123",
+		CardNumber:     "12345",
+		FullName:       "John Doe",
+		StartDate:      "2025-11-01T00:00:00Z",
+		ExpirationDate: "2026-11-01T00:00:00Z",
+	}
+	accessPass, err := client.AccessPasses.Issue(params)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if accessPass.ID != "emp_42" {
+		t.Errorf("ID = %q, want emp_42", accessPass.ID)
+	}
+
+	params.CardNumber = "67890"
+	_, err = client.AccessPasses.Issue(params)
+	if !errors.Is(err, doorpasses.ErrPassIDTaken) {
+		t.Fatalf("Issue() with a taken ID error = %v, want ErrPassIDTaken", err)
+	}
+	var apiErr *doorpasses.APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != doorpasses.ErrorCodeAccessPassIDTaken {
+		t.Errorf("Issue() error = %v, want an APIError with code %s", err, doorpasses.ErrorCodeAccessPassIDTaken)
+	}
+}
+
+func TestServerUpdatePrecondition(t *testing.T) {
+	server := New()
+	defer server.Close()
+	client := server.Client(nil)
+
+	accessPass, err := client.AccessPasses.Issue(doorpasses.IssueAccessPassParams{
+		CardTemplateID: "template_123",
+		CardNumber:     "12345",
+		FullName:       "John Doe",
+		StartDate:      "2025-01-01T00:00:00Z",
+		ExpirationDate: "2026-01-01T00:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	first, err := client.AccessPasses.Update(doorpasses.UpdateAccessPassParams{AccessPassID: accessPass.ID, Title: "Engineer"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+
+	stale := doorpasses.UpdateAccessPassParams{
+		AccessPassID:      accessPass.ID,
+		Title:             "Manager",
+		IfUnmodifiedSince: first.Updated.Add(-time.Second),
+	}
+	if _, err := client.AccessPasses.Update(stale); !errors.Is(err, doorpasses.ErrConflict) {
+		t.Errorf("Update() error = %v, want ErrConflict", err)
+	}
+
+	fresh := stale
+	fresh.IfUnmodifiedSince = first.Updated
+	if _, err := client.AccessPasses.Update(fresh); err != nil {
+		t.Errorf("Update() error = %v", err)
+	}
+}
@@ -0,0 +1,36 @@
+package doorpasses
+
+import (
+	"errors"
+	"fmt"
+)
+
+// dryRunHeader asks the API to validate a request without persisting it
+const dryRunHeader = "X-Dry-Run"
+
+// ErrDryRunNotSupported is returned when a dry run was requested but the API
+// didn't acknowledge it, meaning the request was carried out for real
+var ErrDryRunNotSupported = errors.New("dry run not supported by the API")
+
+// WithDryRun asks the API to validate an Issue or BulkIssue call without
+// creating any pass. The returned passes show what would have been created
+// and have DryRun set; their IDs are previews and don't refer to real
+// passes. Client-side validation still runs first, so invalid params fail
+// fast without a request.
+//
+// Dry runs never send an idempotency key, so a preview can't be replayed in
+// place of the real issuance later.
+func WithDryRun() RequestOption {
+	return func(o *requestOptions) {
+		o.dryRun = true
+		withHeader(dryRunHeader, "true")(o)
+	}
+}
+
+// checkDryRun verifies that the API treated a dry-run issuance as one
+func checkDryRun(accessPass *AccessPass) error {
+	if !accessPass.DryRun {
+		return fmt.Errorf("%w: access pass %s was created", ErrDryRunNotSupported, accessPass.ID)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package doorpasses
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestAccessPassesIssueDryRun(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr error
+	}{
+		{
+			name: "acknowledged",
+			body: `{"success": true, "data": {"id": "pass_preview", "dryRun": true}}`,
+		},
+		{
+			name:    "ignored by the API",
+			body:    `{"success": true, "data": {"id": "pass_123"}}`,
+			wantErr: ErrDryRunNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{GenerateIdempotencyKeys: true}, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get(dryRunHeader); got != "true" {
+					t.Errorf("%s = %q, want %q", dryRunHeader, got, "true")
+				}
+				if got := r.Header.Get(idempotencyKeyHeader); got != "" {
+					t.Errorf("%s = %q, want none on a dry run", idempotencyKeyHeader, got)
+				}
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(tt.body))
+			})
+
+			params := validIssueParams()
+			params.IdempotencyKey = "key_123"
+			accessPass, err := client.AccessPasses.Issue(params, WithDryRun())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Issue() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && !accessPass.DryRun {
+				t.Error("Issue() result is not marked as a dry run")
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueDryRunValidatesFirst(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("invalid params were sent to the API")
+	})
+
+	params := validIssueParams()
+	params.FullName = ""
+	_, err := client.AccessPasses.Issue(params, WithDryRun())
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Errorf("Issue() error = %v, want *ValidationError", err)
+	}
+}
+
+func TestAccessPassesIssueDryRunRetries(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, &Config{MaxRetries: 1, RetryBackoff: func(int) time.Duration { return 0 }}, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_preview", "dryRun": true}}`))
+	})
+
+	result, err := client.AccessPasses.BulkIssue([]IssueAccessPassParams{validIssueParams()}, WithDryRun())
+	if err != nil {
+		t.Fatalf("BulkIssue() error = %v", err)
+	}
+	if item := result.Items[0]; item.Err != nil || !item.AccessPass.DryRun {
+		t.Errorf("Items[0] = %+v, want dry-run pass", item)
+	}
+	if requests != 2 {
+		t.Errorf("server received %d requests, want 2", requests)
+	}
+}
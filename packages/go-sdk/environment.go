@@ -0,0 +1,54 @@
+package doorpasses
+
+import "fmt"
+
+// Environment selects the DoorPasses deployment a client talks to
+type Environment string
+
+const (
+	// Production is the live DoorPasses API at DefaultBaseURL
+	Production Environment = "production"
+
+	// Sandbox is the DoorPasses test API at SandboxBaseURL, which never
+	// issues real passes
+	Sandbox Environment = "sandbox"
+)
+
+// SandboxBaseURL is the base URL of the Sandbox environment
+const SandboxBaseURL = "https://sandbox.api.doorpasses.io"
+
+// BaseURL returns the API base URL of the environment
+func (e Environment) BaseURL() (string, error) {
+	switch e {
+	case Production:
+		return DefaultBaseURL, nil
+	case Sandbox:
+		return SandboxBaseURL, nil
+	}
+	return "", fmt.Errorf("unknown environment %q: must be %q or %q", string(e), Production, Sandbox)
+}
+
+// NewClientForEnv creates a client for the given environment, which picks
+// the base URL. Setting config.BaseURL as well is an error, since the two
+// could disagree.
+//
+// Example:
+//
+//	client, err := doorpasses.NewClientForEnv(accountID, sharedSecret, doorpasses.Sandbox, nil)
+func NewClientForEnv(accountID, sharedSecret string, env Environment, config *Config) (*Client, error) {
+	baseURL, err := env.BaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	if config != nil {
+		if config.BaseURL != "" {
+			return nil, fmt.Errorf("baseURL must not be set with an environment")
+		}
+		c = *config
+	}
+	c.BaseURL = baseURL
+
+	return NewClient(accountID, sharedSecret, &c)
+}
@@ -0,0 +1,38 @@
+package doorpasses
+
+import "testing"
+
+func TestNewClientForEnv(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         Environment
+		config      *Config
+		wantBaseURL string
+		wantErr     bool
+	}{
+		{name: "production", env: Production, wantBaseURL: DefaultBaseURL},
+		{name: "sandbox", env: Sandbox, wantBaseURL: SandboxBaseURL},
+		{name: "with config", env: Sandbox, config: &Config{UserAgent: "acme/1.0"}, wantBaseURL: SandboxBaseURL},
+		{name: "unknown environment", env: "staging", wantErr: true},
+		{name: "empty environment", env: "", wantErr: true},
+		{name: "base url conflict", env: Production, config: &Config{BaseURL: "https://example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClientForEnv("test_account", "test_secret", tt.env, tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewClientForEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if client.http.baseURL != tt.wantBaseURL {
+				t.Errorf("baseURL = %q, want %q", client.http.baseURL, tt.wantBaseURL)
+			}
+			if tt.config != nil && tt.config.BaseURL != "" {
+				t.Error("NewClientForEnv() modified the caller's config")
+			}
+		})
+	}
+}
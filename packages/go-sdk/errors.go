@@ -0,0 +1,389 @@
+package doorpasses
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrPassAlreadyRevoked is returned when revoking an access pass that has
+// already been revoked, or when the API refuses to act on a revoked pass.
+// The returned error also wraps the APIError.
+var ErrPassAlreadyRevoked = errors.New("access pass is already revoked")
+
+// ErrPassExpired is returned when the API refuses to change an access pass
+// because it has expired. The returned error also wraps the APIError.
+var ErrPassExpired = errors.New("access pass has expired")
+
+// ErrPassAlreadySuspended is returned when suspending an access pass that is
+// already suspended
+var ErrPassAlreadySuspended = errors.New("access pass is already suspended")
+
+// ErrPassNotSuspended is returned when reactivating an access pass that is
+// not suspended
+var ErrPassNotSuspended = errors.New("access pass is not suspended")
+
+// ErrPassNotProvisioned is returned when downloading a wallet pass that does
+// not exist yet, e.g. right after issuing. The returned error also wraps the
+// APIError.
+var ErrPassNotProvisioned = errors.New("wallet pass is not provisioned yet")
+
+// ErrClientClosed is returned for every request made after Client.Close
+var ErrClientClosed = errors.New("client is closed")
+
+// ErrGoogleWalletNotConfigured is returned when requesting a Google Wallet
+// link for an access pass whose card template isn't set up for Google Wallet
+var ErrGoogleWalletNotConfigured = errors.New("card template is not configured for Google Wallet")
+
+// ErrCredentialRotationNotSupported is returned by RotateCredential when the
+// access pass's card template doesn't allow replacing its card number. The
+// returned error also wraps the APIError.
+var ErrCredentialRotationNotSupported = errors.New("credential rotation is not supported for this card template")
+
+// ErrEnterpriseRequired is returned by Console methods when the account is
+// not on the Enterprise tier. The returned error also wraps the APIError.
+var ErrEnterpriseRequired = errors.New("enterprise tier required")
+
+// ErrAccountMismatch is returned when the API answers with a resource that
+// belongs to another account than the client's, typically because an ID from
+// one account was used with a client configured for another
+var ErrAccountMismatch = errors.New("resource belongs to another account")
+
+// ErrConflict is returned by AccessPasses.Update when the access pass was
+// changed by someone else since UpdateAccessPassParams.IfUnmodifiedSince
+var ErrConflict = errors.New("access pass was modified concurrently")
+
+// ErrTooManyConcurrentRequests is returned when Config.MaxConcurrentRequests
+// calls are already in flight and Config.FailFastOnConcurrencyLimit is set
+var ErrTooManyConcurrentRequests = errors.New("too many concurrent requests")
+
+// ErrAPIDown is returned by HealthCheck when the API can't be reached or
+// answers the check with an error
+var ErrAPIDown = errors.New("API is down")
+
+// ErrTemplateRefNotFound is returned by Console.ResolveTemplate when no card
+// template has the given external reference
+var ErrTemplateRefNotFound = errors.New("no card template with that external reference")
+
+// ErrIdempotencyKeyReused is returned by AccessPasses.Issue with
+// Config.Dedupe set when a call with the same idempotency key but different
+// params is already in flight
+var ErrIdempotencyKeyReused = errors.New("idempotency key is in use with different params")
+
+// ErrInvalidCredentials is returned by NewClient with
+// Config.VerifyCredentials set when the API rejects the account ID or
+// shared secret
+var ErrInvalidCredentials = errors.New("bad DoorPasses credentials")
+
+// ErrSearchNotSupported is returned by AccessPasses.Search when the API
+// doesn't offer server-side search
+var ErrSearchNotSupported = errors.New("access pass search is not supported by the API")
+
+// ErrPassIDTaken is returned by AccessPasses.Issue when
+// IssueAccessPassParams.ID is already the ID of another access pass. The
+// returned error also wraps the APIError.
+var ErrPassIDTaken = errors.New("access pass ID is already taken")
+
+// ErrUnexpectedRedirect is returned when the API, or a proxy in front of it,
+// redirects a request to another host or in a way that would drop the
+// signed body. Set Config.FollowRedirects to follow such redirects anyway.
+var ErrUnexpectedRedirect = errors.New("unexpected redirect")
+
+// Error codes the API is known to send in APIError.Code. The API may add
+// codes at any time, and unknown ones are passed through as sent, so always
+// allow for a code that isn't listed here.
+const (
+	ErrorCodeNotFound                 = "NOT_FOUND"
+	ErrorCodeAccessPassNotFound       = "ACCESS_PASS_NOT_FOUND"
+	ErrorCodeTemplateNotFound         = "CARD_TEMPLATE_NOT_FOUND"
+	ErrorCodeTemplateNotPublished     = "CARD_TEMPLATE_NOT_PUBLISHED"
+	ErrorCodeValidation               = "VALIDATION_ERROR"
+	ErrorCodeDuplicateEntry           = "DUPLICATE_ENTRY"
+	ErrorCodeInvalidReference         = "INVALID_REFERENCE"
+	ErrorCodeUnauthorized             = "UNAUTHORIZED"
+	ErrorCodeForbidden                = "FORBIDDEN"
+	ErrorCodeRateLimitExceeded        = "RATE_LIMIT_EXCEEDED"
+	ErrorCodeWebhookRateLimitExceeded = "WEBHOOK_RATE_LIMIT_EXCEEDED"
+	ErrorCodeWallet                   = "WALLET_ERROR"
+	ErrorCodeInternal                 = "INTERNAL_ERROR"
+)
+
+// Anticipated error codes the API doesn't send yet. The SDK already handles
+// them, e.g. ErrPassIDTaken and APIError.IsQuotaExceeded, so that code
+// written against them keeps working once the API starts sending them.
+const (
+	ErrorCodeAccessPassIDTaken              = "ACCESS_PASS_ID_TAKEN"
+	ErrorCodeBadRequest                     = "BAD_REQUEST"
+	ErrorCodeAccountMismatch                = "ACCOUNT_MISMATCH"
+	ErrorCodeQuotaExceeded                  = "QUOTA_EXCEEDED"
+	ErrorCodePassExpired                    = "ACCESS_PASS_EXPIRED"
+	ErrorCodePassRevoked                    = "ACCESS_PASS_REVOKED"
+	ErrorCodePassSuspended                  = "ACCESS_PASS_SUSPENDED"
+	ErrorCodeCredentialRotationNotSupported = "CREDENTIAL_ROTATION_NOT_SUPPORTED"
+	ErrorCodePreconditionFailed             = "PRECONDITION_FAILED"
+)
+
+// APIError is returned when the API responds with a non-2xx status
+type APIError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+
+	// Code is the machine-readable error code, e.g. ErrorCodeNotFound. A
+	// code without an ErrorCode constant is kept exactly as the API sent it.
+	Code string
+
+	// Message is the human-readable error message
+	Message string
+
+	// RequestID identifies the request for DoorPasses support
+	RequestID string
+
+	// Body is the start of the response body when it wasn't a JSON error,
+	// e.g. an HTML error page from a proxy or gateway in front of the API
+	Body string
+
+	// Fields lists the invalid parameters when the API rejected the request
+	// with per-field details
+	Fields []FieldError
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("DoorPasses API Error (%d): %s", e.StatusCode, e.Message)
+}
+
+// HasCode reports whether the API sent code, e.g. ErrorCodeQuotaExceeded
+func (e *APIError) HasCode(code string) bool {
+	return e != nil && e.Code == code
+}
+
+// IsQuotaExceeded reports whether the account has used up its quota, e.g.
+// of active passes
+func (e *APIError) IsQuotaExceeded() bool {
+	return e.HasCode(ErrorCodeQuotaExceeded)
+}
+
+// IsTemplateNotFound reports whether the card template doesn't exist
+func (e *APIError) IsTemplateNotFound() bool {
+	return e.HasCode(ErrorCodeTemplateNotFound)
+}
+
+// IsPassRevoked reports whether the access pass was already revoked
+func (e *APIError) IsPassRevoked() bool {
+	return e.HasCode(ErrorCodePassRevoked)
+}
+
+// IsRateLimitExceeded reports whether the request was rate limited
+func (e *APIError) IsRateLimitExceeded() bool {
+	return e.HasCode(ErrorCodeRateLimitExceeded)
+}
+
+// Unwrap returns a *ValidationError carrying Fields, or the message alone
+// when the API listed no fields, if the API rejected the request
+// parameters. This lets errors.As find a *ValidationError whether
+// validation failed client-side or in the API.
+func (e *APIError) Unwrap() error {
+	if len(e.Fields) == 0 && e.Code != ErrorCodeValidation {
+		return nil
+	}
+	return &ValidationError{Fields: e.Fields, Message: e.Message}
+}
+
+// newAPIError builds an APIError from a non-2xx response and its body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  requestIDFromHeader(resp.Header),
+	}
+
+	// The error field is either a plain string or an object carrying a code
+	var errorResp struct {
+		Error     json.RawMessage `json:"error"`
+		Message   string          `json:"message"`
+		Code      string          `json:"code"`
+		RequestID string          `json:"requestId"`
+	}
+	if err := json.Unmarshal(body, &errorResp); err != nil {
+		apiErr.Body = bodySnippet(body)
+	} else {
+		apiErr.Code = errorResp.Code
+		apiErr.Message = errorResp.Message
+
+		var errorObj struct {
+			Code    string          `json:"code"`
+			Message string          `json:"message"`
+			Details json.RawMessage `json:"details"`
+		}
+		var errorStr string
+		if err := json.Unmarshal(errorResp.Error, &errorStr); err == nil && errorStr != "" {
+			apiErr.Message = errorStr
+		} else if err := json.Unmarshal(errorResp.Error, &errorObj); err == nil {
+			if errorObj.Code != "" {
+				apiErr.Code = errorObj.Code
+			}
+			if errorObj.Message != "" {
+				apiErr.Message = errorObj.Message
+			}
+			// Details are free-form; only a list of field errors is kept
+			var fields []FieldError
+			if json.Unmarshal(errorObj.Details, &fields) == nil {
+				apiErr.Fields = fields
+			}
+		}
+
+		if apiErr.RequestID == "" {
+			apiErr.RequestID = errorResp.RequestID
+		}
+	}
+
+	if apiErr.Message == "" {
+		if isHTML(resp.Header, body) {
+			apiErr.Message = http.StatusText(resp.StatusCode)
+		} else {
+			apiErr.Message = bodySnippet(body)
+		}
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
+
+// maxBodySnippet is the most of a non-JSON response body kept in errors
+const maxBodySnippet = 256
+
+// bodySnippet returns the start of body with runs of whitespace collapsed,
+// for including a non-JSON response in an error
+func bodySnippet(body []byte) string {
+	if len(body) > 4*maxBodySnippet {
+		body = body[:4*maxBodySnippet]
+	}
+	snippet := strings.Join(strings.Fields(string(body)), " ")
+	if len(snippet) <= maxBodySnippet {
+		return snippet
+	}
+	// Back up to a rune boundary so the snippet stays valid UTF-8
+	end := maxBodySnippet
+	for end > 0 && !utf8.RuneStart(snippet[end]) {
+		end--
+	}
+	return snippet[:end] + "..."
+}
+
+// isHTML reports whether a response body is an HTML page, going by its
+// content type or, failing that, its first character
+func isHTML(header http.Header, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil {
+		return mediaType == "text/html" || mediaType == "application/xhtml+xml"
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(body), []byte("<"))
+}
+
+// requestIDFromHeader returns the request ID the API attached to a response
+func requestIDFromHeader(header http.Header) string {
+	if id := header.Get("X-Request-ID"); id != "" {
+		return id
+	}
+	return header.Get("Request-Id")
+}
+
+// IsNotFound reports whether err is an APIError with a 404 status
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsUnauthorized reports whether err is an APIError with a 401 status
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, http.StatusUnauthorized)
+}
+
+// IsValidation reports whether err is a ValidationError, or an APIError
+// caused by the API rejecting the request parameters
+func IsValidation(err error) bool {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.Code == ErrorCodeValidation ||
+		apiErr.StatusCode == http.StatusBadRequest ||
+		apiErr.StatusCode == http.StatusUnprocessableEntity
+}
+
+// isEnterpriseRequired reports whether err is the API rejecting a request
+// because the account lacks the Enterprise tier
+func isEnterpriseRequired(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.StatusCode == http.StatusForbidden {
+		return true
+	}
+	return apiErr.StatusCode == http.StatusUnauthorized &&
+		strings.Contains(strings.ToLower(apiErr.Message), "enterprise")
+}
+
+// passStateError wraps err with ErrPassExpired, ErrPassAlreadyRevoked or
+// ErrPassAlreadySuspended when the API rejected a request because of the
+// access pass's state
+func passStateError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	switch apiErr.Code {
+	case ErrorCodePassExpired:
+		return fmt.Errorf("%w: %w", ErrPassExpired, err)
+	case ErrorCodePassRevoked:
+		return fmt.Errorf("%w: %w", ErrPassAlreadyRevoked, err)
+	case ErrorCodePassSuspended:
+		return fmt.Errorf("%w: %w", ErrPassAlreadySuspended, err)
+	}
+	return err
+}
+
+// hasStatus reports whether err is an APIError with the given status code
+func hasStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
+
+// RateLimitError is returned when the API responds with HTTP 429 and the
+// request could not be retried, or retries were exhausted
+type RateLimitError struct {
+	// RetryAfter is how long the API asked the client to wait before sending
+	// another request. It is zero when the response had no Retry-After header.
+	RetryAfter time.Duration
+
+	err *APIError
+}
+
+func (e *RateLimitError) Error() string {
+	msg := "rate limited"
+	if e.err != nil {
+		msg = e.err.Error()
+	}
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s (retry after %s)", msg, e.RetryAfter)
+	}
+	return msg
+}
+
+// Unwrap returns the underlying APIError, nil for a RateLimitError built
+// without one, e.g. in a test
+func (e *RateLimitError) Unwrap() error {
+	if e.err == nil {
+		return nil
+	}
+	return e.err
+}
@@ -0,0 +1,266 @@
+package doorpasses
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		header        http.Header
+		body          string
+		wantCode      string
+		wantMessage   string
+		wantRequestID string
+		wantBody      string
+	}{
+		{
+			name:        "error object",
+			statusCode:  http.StatusNotFound,
+			body:        `{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`,
+			wantCode:    "NOT_FOUND",
+			wantMessage: "Access pass not found",
+		},
+		{
+			name:        "error string",
+			statusCode:  http.StatusUnauthorized,
+			body:        `{"error": "Invalid signature"}`,
+			wantMessage: "Invalid signature",
+		},
+		{
+			name:        "message only",
+			statusCode:  http.StatusBadRequest,
+			body:        `{"message": "Full name is required"}`,
+			wantMessage: "Full name is required",
+		},
+		{
+			name:        "non-JSON body",
+			statusCode:  http.StatusBadGateway,
+			body:        `Bad Gateway`,
+			wantMessage: "Bad Gateway",
+			wantBody:    "Bad Gateway",
+		},
+		{
+			name:        "HTML body",
+			statusCode:  http.StatusBadGateway,
+			header:      http.Header{"Content-Type": []string{"text/html"}},
+			body:        "<html>\n  <head><title>502 Bad Gateway</title></head>\n</html>",
+			wantMessage: "Bad Gateway",
+			wantBody:    "<html> <head><title>502 Bad Gateway</title></head> </html>",
+		},
+		{
+			name:        "HTML body without content type",
+			statusCode:  http.StatusServiceUnavailable,
+			body:        "<!DOCTYPE html><html><body>Service Unavailable</body></html>",
+			wantMessage: "Service Unavailable",
+			wantBody:    "<!DOCTYPE html><html><body>Service Unavailable</body></html>",
+		},
+		{
+			name:        "long body is truncated",
+			statusCode:  http.StatusBadGateway,
+			body:        strings.Repeat("x", 300),
+			wantMessage: strings.Repeat("x", 256) + "...",
+			wantBody:    strings.Repeat("x", 256) + "...",
+		},
+		{
+			name:        "empty body",
+			statusCode:  http.StatusGatewayTimeout,
+			body:        "",
+			wantMessage: "Gateway Timeout",
+		},
+		{
+			name:          "request ID header",
+			statusCode:    http.StatusInternalServerError,
+			header:        http.Header{"X-Request-Id": []string{"req_123"}},
+			body:          `{"error": {"code": "INTERNAL_ERROR", "message": "Internal server error"}}`,
+			wantCode:      "INTERNAL_ERROR",
+			wantMessage:   "Internal server error",
+			wantRequestID: "req_123",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			resp := &http.Response{StatusCode: tt.statusCode, Header: header}
+
+			got := newAPIError(resp, []byte(tt.body))
+			if got.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode = %v, want %v", got.StatusCode, tt.statusCode)
+			}
+			if got.Code != tt.wantCode {
+				t.Errorf("Code = %v, want %v", got.Code, tt.wantCode)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %v, want %v", got.Message, tt.wantMessage)
+			}
+			if got.RequestID != tt.wantRequestID {
+				t.Errorf("RequestID = %v, want %v", got.RequestID, tt.wantRequestID)
+			}
+			if got.Body != tt.wantBody {
+				t.Errorf("Body = %q, want %q", got.Body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAPIErrorFields(t *testing.T) {
+	tests := []struct {
+		name           string
+		statusCode     int
+		body           string
+		wantCode       string
+		wantFields     []FieldError
+		wantValidation bool
+		wantMessage    string
+	}{
+		{
+			name:       "field details",
+			statusCode: http.StatusBadRequest,
+			body: `{"success": false, "error": {"code": "VALIDATION_ERROR", "message": "Request validation failed", "details": [
+				{"field": "fullName", "message": "Required"},
+				{"field": "cardNumber", "code": "too_big", "message": "Card number must be below 65536"}
+			]}}`,
+			wantCode: "VALIDATION_ERROR",
+			wantFields: []FieldError{
+				{Field: "fullName", Message: "Required"},
+				{Field: "cardNumber", Code: "too_big", Message: "Card number must be below 65536"},
+			},
+			wantValidation: true,
+			wantMessage:    "Request validation failed",
+		},
+		{
+			name:           "validation error without details",
+			statusCode:     http.StatusBadRequest,
+			body:           `{"success": false, "error": {"code": "VALIDATION_ERROR", "message": "Full name is required"}}`,
+			wantCode:       "VALIDATION_ERROR",
+			wantValidation: true,
+			wantMessage:    "Full name is required",
+		},
+		{
+			name:       "free-form details",
+			statusCode: http.StatusConflict,
+			body:       `{"success": false, "error": {"code": "ACCESS_PASS_EXPIRED", "message": "Access pass has expired", "details": {"expiredAt": "2025-01-01"}}}`,
+			wantCode:   "ACCESS_PASS_EXPIRED",
+		},
+		{
+			name:       "not a validation error",
+			statusCode: http.StatusNotFound,
+			body:       `{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`,
+			wantCode:   "NOT_FOUND",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.statusCode, Header: http.Header{}}
+			apiErr := newAPIError(resp, []byte(tt.body))
+
+			if apiErr.Code != tt.wantCode {
+				t.Errorf("Code = %q, want %q", apiErr.Code, tt.wantCode)
+			}
+			if !reflect.DeepEqual(apiErr.Fields, tt.wantFields) {
+				t.Errorf("Fields = %+v, want %+v", apiErr.Fields, tt.wantFields)
+			}
+
+			var validationErr *ValidationError
+			if got := errors.As(fmt.Errorf("wrapped: %w", apiErr), &validationErr); got != tt.wantValidation {
+				t.Fatalf("errors.As(*ValidationError) = %v, want %v", got, tt.wantValidation)
+			}
+			if !tt.wantValidation {
+				return
+			}
+			if !reflect.DeepEqual(validationErr.Fields, tt.wantFields) {
+				t.Errorf("ValidationError.Fields = %+v, want %+v", validationErr.Fields, tt.wantFields)
+			}
+			if validationErr.Message != tt.wantMessage {
+				t.Errorf("ValidationError.Message = %q, want %q", validationErr.Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestAPIErrorHelpers(t *testing.T) {
+	notFound := &APIError{StatusCode: http.StatusNotFound, Code: "NOT_FOUND"}
+	unauthorized := &APIError{StatusCode: http.StatusUnauthorized, Code: "UNAUTHORIZED"}
+	validation := &APIError{StatusCode: http.StatusBadRequest, Code: "VALIDATION_ERROR"}
+
+	tests := []struct {
+		name  string
+		check func(error) bool
+		err   error
+		want  bool
+	}{
+		{name: "not found", check: IsNotFound, err: notFound, want: true},
+		{name: "wrapped not found", check: IsNotFound, err: fmt.Errorf("lookup: %w", notFound), want: true},
+		{name: "not found mismatch", check: IsNotFound, err: unauthorized, want: false},
+		{name: "unauthorized", check: IsUnauthorized, err: unauthorized, want: true},
+		{name: "validation", check: IsValidation, err: validation, want: true},
+		{name: "validation mismatch", check: IsValidation, err: notFound, want: false},
+		{name: "plain error", check: IsNotFound, err: errors.New("boom"), want: false},
+		{name: "nil error", check: IsNotFound, err: nil, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.check(tt.err); got != tt.want {
+				t.Errorf("check(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorCodes(t *testing.T) {
+	tests := []struct {
+		name                  string
+		code                  string
+		wantQuotaExceeded     bool
+		wantTemplateNotFound  bool
+		wantPassRevoked       bool
+		wantRateLimitExceeded bool
+	}{
+		{name: "quota exceeded", code: ErrorCodeQuotaExceeded, wantQuotaExceeded: true},
+		{name: "template not found", code: ErrorCodeTemplateNotFound, wantTemplateNotFound: true},
+		{name: "template not found as the API sends it", code: "CARD_TEMPLATE_NOT_FOUND", wantTemplateNotFound: true},
+		{name: "pass revoked", code: ErrorCodePassRevoked, wantPassRevoked: true},
+		{name: "rate limited", code: ErrorCodeRateLimitExceeded, wantRateLimitExceeded: true},
+		{name: "unknown code", code: "SOMETHING_NEW"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}}
+			apiErr := newAPIError(resp, []byte(`{"success": false, "error": {"code": "`+tt.code+`", "message": "failed"}}`))
+
+			if apiErr.Code != tt.code || !apiErr.HasCode(tt.code) {
+				t.Errorf("Code = %q, want %q passed through", apiErr.Code, tt.code)
+			}
+			if got := apiErr.IsQuotaExceeded(); got != tt.wantQuotaExceeded {
+				t.Errorf("IsQuotaExceeded() = %v, want %v", got, tt.wantQuotaExceeded)
+			}
+			if got := apiErr.IsTemplateNotFound(); got != tt.wantTemplateNotFound {
+				t.Errorf("IsTemplateNotFound() = %v, want %v", got, tt.wantTemplateNotFound)
+			}
+			if got := apiErr.IsPassRevoked(); got != tt.wantPassRevoked {
+				t.Errorf("IsPassRevoked() = %v, want %v", got, tt.wantPassRevoked)
+			}
+			if got := apiErr.IsRateLimitExceeded(); got != tt.wantRateLimitExceeded {
+				t.Errorf("IsRateLimitExceeded() = %v, want %v", got, tt.wantRateLimitExceeded)
+			}
+		})
+	}
+
+	var nilErr *APIError
+	if nilErr.HasCode(ErrorCodeNotFound) {
+		t.Error("HasCode() on a nil *APIError = true, want false")
+	}
+}
@@ -0,0 +1,89 @@
+package doorpasses
+
+import (
+	"container/list"
+	"sync"
+)
+
+// ResponseCache stores the ETag and body of responses so that repeated Get
+// calls can send If-None-Match and skip downloading unchanged objects.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the response stored under key, if any
+	Get(key string) (CachedResponse, bool)
+
+	// Set stores resp under key
+	Set(key string, resp CachedResponse)
+}
+
+// CachedResponse is a response kept by a ResponseCache
+type CachedResponse struct {
+	// ETag is the entity tag the API sent with Body
+	ETag string
+
+	// Body is the decoded object exactly as the API sent it
+	Body []byte
+}
+
+// memoryCache is a ResponseCache holding the most recently used entries
+type memoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+// memoryCacheEntry is an element of memoryCache.order
+type memoryCacheEntry struct {
+	key  string
+	resp CachedResponse
+}
+
+// NewMemoryCache returns an in-memory ResponseCache that keeps at most
+// maxEntries responses, evicting the least recently used. A maxEntries of
+// zero or less means no limit.
+func NewMemoryCache(maxEntries int) ResponseCache {
+	return &memoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+func (c *memoryCache) Get(key string) (CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return CachedResponse{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*memoryCacheEntry).resp, true
+}
+
+func (c *memoryCache) Set(key string, resp CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).resp = resp
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, resp: resp})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// cacheKey identifies a cached GET of path, including the account so a
+// cache shared between clients never mixes up their objects, and the query
+// built from opts since WithFields changes the response
+func (c *HTTPClient) cacheKey(path string, opts []RequestOption) string {
+	key := c.accountID + " " + path
+	if query := newRequestOptions(opts).query; len(query) > 0 {
+		key += "?" + query.Encode()
+	}
+	return key
+}
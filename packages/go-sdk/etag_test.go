@@ -0,0 +1,109 @@
+package doorpasses
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAccessPassesGetConditional(t *testing.T) {
+	var gotIfNoneMatch []string
+	etag := `"v1"`
+	client := newTestClient(t, &Config{ResponseCache: NewMemoryCache(0)}, func(w http.ResponseWriter, r *http.Request) {
+		gotIfNoneMatch = append(gotIfNoneMatch, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "fullName": "John Doe", "state": "active"}}`))
+	})
+
+	first, err := client.AccessPasses.Get("pass_123")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if first.NotModified {
+		t.Error("first Get() NotModified = true, want false")
+	}
+
+	second, err := client.AccessPasses.Get("pass_123")
+	if err != nil {
+		t.Fatalf("second Get() error = %v", err)
+	}
+	if !second.NotModified || second.FullName != "John Doe" || second.State != AccessPassStateActive {
+		t.Errorf("second Get() = %+v, want the cached pass marked NotModified", second)
+	}
+
+	// A changed pass replaces the cached copy
+	etag = `"v2"`
+	third, err := client.AccessPasses.Get("pass_123")
+	if err != nil {
+		t.Fatalf("third Get() error = %v", err)
+	}
+	if third.NotModified {
+		t.Error("third Get() NotModified = true, want false")
+	}
+
+	want := []string{"", `"v1"`, `"v1"`}
+	if len(gotIfNoneMatch) != len(want) {
+		t.Fatalf("If-None-Match headers = %q, want %q", gotIfNoneMatch, want)
+	}
+	for i := range want {
+		if gotIfNoneMatch[i] != want[i] {
+			t.Errorf("request %d If-None-Match = %q, want %q", i, gotIfNoneMatch[i], want[i])
+		}
+	}
+
+	// Sparse responses are cached separately
+	if _, err := client.AccessPasses.Get("pass_123", WithFields("id")); err != nil {
+		t.Fatalf("Get() with fields error = %v", err)
+	}
+	if got := gotIfNoneMatch[len(gotIfNoneMatch)-1]; got != "" {
+		t.Errorf("Get() with fields If-None-Match = %q, want none", got)
+	}
+}
+
+func TestAccessPassesGetWithoutCache(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("If-None-Match"); got != "" {
+			t.Errorf("If-None-Match = %q, want none without a cache", got)
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.AccessPasses.Get("pass_123"); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	cache := NewMemoryCache(2)
+	cache.Set("a", CachedResponse{ETag: "1"})
+	cache.Set("b", CachedResponse{ETag: "2"})
+	cache.Get("a") // a is now the most recently used
+	cache.Set("c", CachedResponse{ETag: "3"})
+
+	tests := []struct {
+		key      string
+		wantETag string
+		wantOK   bool
+	}{
+		{key: "a", wantETag: "1", wantOK: true},
+		{key: "b"},
+		{key: "c", wantETag: "3", wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := cache.Get(tt.key)
+		if ok != tt.wantOK || got.ETag != tt.wantETag {
+			t.Errorf("Get(%q) = %q, %v, want %q, %v", tt.key, got.ETag, ok, tt.wantETag, tt.wantOK)
+		}
+	}
+
+	cache.Set("c", CachedResponse{ETag: "4"})
+	if got, _ := cache.Get("c"); got.ETag != "4" {
+		t.Errorf("Get(c) after update = %q, want 4", got.ETag)
+	}
+}
@@ -0,0 +1,116 @@
+package doorpasses
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// exportPages are the pages of template_123 served by exportHandler, by
+// cursor
+var exportPages = map[string]string{
+	"":         `{"success": true, "data": {"items": [{"id": "pass_1", "cardTemplateId": "template_123", "fullName": "John Doe", "email": "john@example.com", "state": "active"}], "nextCursor": "cursor_2", "hasMore": true}}`,
+	"cursor_2": `{"success": true, "data": {"items": [{"id": "pass_2", "cardTemplateId": "template_123", "fullName": "Doe, Jane", "state": "revoked"}], "hasMore": false}}`,
+}
+
+// exportHandler serves exportPages, calling onPage with each cursor
+func exportHandler(t *testing.T, onPage func(cursor string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		var params struct {
+			TemplateID string `json:"template_id"`
+			Cursor     string `json:"cursor"`
+		}
+		json.Unmarshal(payload, &params)
+		if params.TemplateID != "template_123" {
+			t.Errorf("template_id = %q, want template_123", params.TemplateID)
+		}
+		if onPage != nil {
+			onPage(params.Cursor)
+		}
+		w.Write([]byte(exportPages[params.Cursor]))
+	}
+}
+
+func TestConsoleExportPasses(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ExportFormat
+		want   string
+	}{
+		{
+			name:   "csv",
+			format: ExportFormatCSV,
+			want: "id,cardTemplateId,employeeId,fullName,email,phoneNumber,classification,title,state,startDate,expirationDate,createdAt,updatedAt\n" +
+				"pass_1,template_123,,John Doe,john@example.com,,,,active,,,,\n" +
+				"pass_2,template_123,,\"Doe, Jane\",,,,,revoked,,,,\n",
+		},
+		{
+			name:   "ndjson",
+			format: ExportFormatNDJSON,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, exportHandler(t, nil))
+
+			var out strings.Builder
+			if err := client.Console.ExportPasses("template_123", &out, tt.format); err != nil {
+				t.Fatalf("ExportPasses() error = %v", err)
+			}
+			if tt.want != "" {
+				if out.String() != tt.want {
+					t.Errorf("output =\n%s\nwant\n%s", out.String(), tt.want)
+				}
+				return
+			}
+
+			var ids []string
+			scanner := bufio.NewScanner(strings.NewReader(out.String()))
+			for scanner.Scan() {
+				var p AccessPass
+				if err := json.Unmarshal(scanner.Bytes(), &p); err != nil {
+					t.Fatalf("line %q is not a pass: %v", scanner.Text(), err)
+				}
+				ids = append(ids, p.ID)
+			}
+			if strings.Join(ids, ",") != "pass_1,pass_2" {
+				t.Errorf("exported %v, want pass_1 and pass_2", ids)
+			}
+		})
+	}
+}
+
+func TestConsoleExportPassesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newTestClient(t, nil, exportHandler(t, func(cursor string) {
+		if cursor == "cursor_2" {
+			cancel()
+		}
+	}))
+
+	var out strings.Builder
+	err := client.Console.ExportPassesWithContext(ctx, "template_123", &out, ExportFormatNDJSON)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ExportPassesWithContext() error = %v, want context.Canceled", err)
+	}
+	if !strings.Contains(out.String(), "pass_1") || strings.Contains(out.String(), "pass_2") {
+		t.Errorf("output = %q, want only the first page", out.String())
+	}
+}
+
+func TestConsoleExportPassesUnknownFormat(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request for an unknown format")
+	})
+	if err := client.Console.ExportPasses("template_123", &strings.Builder{}, "xml"); err == nil {
+		t.Error("ExportPasses() with an unknown format succeeded, want an error")
+	}
+}
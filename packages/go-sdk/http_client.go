@@ -2,185 +2,661 @@ package doorpasses
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"math"
+	"mime"
+	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 )
 
-// HTTPClient handles authenticated requests to the DoorPasses API
+// HTTPClient handles authenticated requests to the DoorPasses API. It is
+// safe for concurrent use by multiple goroutines.
 type HTTPClient struct {
 	client       *http.Client
 	accountID    string
 	sharedSecret string
+	signer       Signer
 	baseURL      string
+	userAgent    string
+	compress     bool
+	maxRetries   int
+
+	maxResponseBytes int64
+	maxDownloadBytes int64
+
+	retryBackoff func(attempt int) time.Duration
+	retryBudget  *retryBudget
+	logger       *slog.Logger
+	onRequest    func(req *http.Request)
+	onResponse   func(req *http.Request, resp *http.Response, err error, duration time.Duration)
+	tracer       Tracer
+	metrics      MetricsObserver
+	breaker      *circuitBreaker
+	marshal      func(v interface{}) ([]byte, error)
+	unmarshal    func(data []byte, v interface{}) error
+
+	responseCache ResponseCache
+
+	// retryableStatusCodes are the statuses retried on top of 5xx and 429
+	retryableStatusCodes map[int]bool
+
+	// redactFields are the lower-cased body fields redacted before logging
+	redactFields map[string]bool
+
+	// skipAccountCheck disables checkAccount
+	skipAccountCheck bool
+
+	limiter *concurrencyLimiter
+
+	// transport is the SDK-owned transport closed by close, nil when the
+	// caller supplied the http.Client
+	transport *http.Transport
+
+	// inflightMu guards closed and inflight together, so no request can
+	// start once close or shutdown has run
+	inflightMu sync.Mutex
+	closed     bool
+	inflight   int
+
+	// drained is closed when the last in-flight request ends after
+	// shutdown, nil when no shutdown is waiting
+	drained chan struct{}
+
+	rateLimitMu sync.Mutex
+	rateLimit   RateLimit
 }
 
 // NewHTTPClient creates a new HTTP client
 func NewHTTPClient(accountID, sharedSecret, baseURL string, timeout time.Duration) *HTTPClient {
-	return &HTTPClient{
+	c := &HTTPClient{
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:       timeout,
+			CheckRedirect: redirectPolicy(false),
 		},
 		accountID:    accountID,
 		sharedSecret: sharedSecret,
+		signer:       SHA256Signer{AccountID: accountID, SharedSecret: sharedSecret},
 		baseURL:      baseURL,
+		userAgent:    defaultUserAgent,
+		maxRetries:   DefaultMaxRetries,
+		retryBackoff: defaultRetryBackoff,
+		marshal:      json.Marshal,
+		unmarshal:    json.Unmarshal,
+
+		maxResponseBytes: DefaultMaxResponseBytes,
+		maxDownloadBytes: DefaultMaxDownloadBytes,
+	}
+
+	// Use a transport of our own so closing it doesn't affect other users of
+	// http.DefaultTransport. If DefaultTransport has been replaced, e.g. by a
+	// test mock, keep using it.
+	if transport, ok := http.DefaultTransport.(*http.Transport); ok {
+		c.transport = transport.Clone()
+		c.client.Transport = c.transport
+	}
+	return c
+}
+
+// defaultDialKeepAlive is the keep-alive period of http.DefaultTransport,
+// kept when Config.DialTimeout replaces its dialer
+const defaultDialKeepAlive = 30 * time.Second
+
+// configureTransport applies Config.DialTimeout, Config.TLSHandshakeTimeout
+// and the connection pool settings to the SDK-owned transport. A transport
+// the SDK doesn't own is left as is.
+func (c *HTTPClient) configureTransport(config *Config) {
+	if c.transport == nil {
+		return
+	}
+	if config.DialTimeout != 0 {
+		dialer := &net.Dialer{Timeout: max(config.DialTimeout, 0), KeepAlive: defaultDialKeepAlive}
+		c.transport.DialContext = dialer.DialContext
+	}
+	if config.TLSHandshakeTimeout != 0 {
+		c.transport.TLSHandshakeTimeout = max(config.TLSHandshakeTimeout, 0)
+	}
+	if config.MaxIdleConns != 0 {
+		c.transport.MaxIdleConns = max(config.MaxIdleConns, 0)
+	}
+	if config.MaxIdleConnsPerHost > 0 {
+		c.transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	} else if config.MaxIdleConnsPerHost < 0 {
+		// The transport treats zero as the default of 2, not as no limit
+		c.transport.MaxIdleConnsPerHost = math.MaxInt
+	}
+	if config.IdleConnTimeout != 0 {
+		c.transport.IdleConnTimeout = max(config.IdleConnTimeout, 0)
+	}
+}
+
+// close marks the client closed and releases the idle connections of the
+// transport it owns. It is safe to call more than once.
+func (c *HTTPClient) close() {
+	c.inflightMu.Lock()
+	c.closed = true
+	c.inflightMu.Unlock()
+	c.closeIdleConnections()
+}
+
+// shutdown marks the client closed and waits for the requests in flight to
+// end or ctx to be done, whichever comes first, then releases the idle
+// connections of the transport it owns
+func (c *HTTPClient) shutdown(ctx context.Context) error {
+	c.inflightMu.Lock()
+	c.closed = true
+	var drained chan struct{}
+	if c.inflight > 0 {
+		if c.drained == nil {
+			c.drained = make(chan struct{})
+		}
+		drained = c.drained
+	}
+	c.inflightMu.Unlock()
+
+	var err error
+	if drained != nil {
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+	}
+	c.closeIdleConnections()
+	return err
+}
+
+// begin records the start of a request, failing with ErrClientClosed once
+// the client is closed. Every successful begin must be paired with end.
+func (c *HTTPClient) begin() error {
+	c.inflightMu.Lock()
+	defer c.inflightMu.Unlock()
+	if c.closed {
+		return ErrClientClosed
+	}
+	c.inflight++
+	return nil
+}
+
+// end records the end of a request. The last request to end on a closed
+// client releases the connection it returned to the idle pool.
+func (c *HTTPClient) end() {
+	c.inflightMu.Lock()
+	c.inflight--
+	last := c.closed && c.inflight == 0
+	if last && c.drained != nil {
+		close(c.drained)
+		c.drained = nil
+	}
+	c.inflightMu.Unlock()
+
+	if last {
+		c.closeIdleConnections()
+	}
+}
+
+// closeIdleConnections closes the idle connections of the SDK-owned
+// transport, if any
+func (c *HTTPClient) closeIdleConnections() {
+	if c.transport != nil {
+		c.transport.CloseIdleConnections()
+	}
+}
+
+// withFallbackTimeout returns a copy of client that uses timeout when client
+// has none of its own. The copy shares the original's transport and cookie jar.
+func withFallbackTimeout(client *http.Client, timeout time.Duration) *http.Client {
+	if client.Timeout > 0 {
+		return client
 	}
+	clone := *client
+	clone.Timeout = timeout
+	return &clone
 }
 
 // Get makes a GET request
-func (c *HTTPClient) Get(path string, sigPayload map[string]interface{}, result interface{}) error {
-	headers, encodedPayload, err := createGetAuthHeaders(c.accountID, c.sharedSecret, sigPayload)
+func (c *HTTPClient) Get(path string, sigPayload map[string]interface{}, result interface{}, opts ...RequestOption) error {
+	return c.GetWithContext(context.Background(), path, sigPayload, result, opts...)
+}
+
+// GetWithContext makes a GET request bound to ctx
+func (c *HTTPClient) GetWithContext(ctx context.Context, path string, sigPayload map[string]interface{}, result interface{}, opts ...RequestOption) error {
+	o := newRequestOptions(opts)
+	headers, encodedPayload, err := c.signGet(sigPayload, o.query)
 	if err != nil {
 		return fmt.Errorf("failed to create auth headers: %w", err)
 	}
 
-	// Build URL with query parameter
+	fullURL, err := c.signedURL(path, encodedPayload, o.query)
+	if err != nil {
+		return err
+	}
+
+	return c.execute(ctx, "GET", fullURL, headers, nil, result, o)
+}
+
+// signedURL builds the URL for a GET request carrying the signed payload as
+// its sig_payload query parameter, along with any extra query parameters
+func (c *HTTPClient) signedURL(path, encodedPayload string, query url.Values) (string, error) {
 	fullURL := c.baseURL + path
-	if encodedPayload != "" {
-		parsedURL, err := url.Parse(fullURL)
-		if err != nil {
-			return fmt.Errorf("failed to parse URL: %w", err)
-		}
-		q := parsedURL.Query()
-		q.Set("sig_payload", encodedPayload)
-		parsedURL.RawQuery = q.Encode()
-		fullURL = parsedURL.String()
+	if encodedPayload == "" && len(query) == 0 {
+		return fullURL, nil
 	}
 
-	req, err := http.NewRequest("GET", fullURL, nil)
+	parsedURL, err := url.Parse(fullURL)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to parse URL: %w", err)
 	}
-
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	q := parsedURL.Query()
+	for key, values := range query {
+		q[key] = values
 	}
-
-	return c.doRequest(req, result)
+	if encodedPayload != "" {
+		q.Set("sig_payload", encodedPayload)
+	}
+	parsedURL.RawQuery = q.Encode()
+	return parsedURL.String(), nil
 }
 
 // Post makes a POST request
-func (c *HTTPClient) Post(path string, data interface{}, result interface{}) error {
-	headers, err := createAuthHeaders(c.accountID, c.sharedSecret, data)
+func (c *HTTPClient) Post(path string, data interface{}, result interface{}, opts ...RequestOption) error {
+	return c.PostWithContext(context.Background(), path, data, result, opts...)
+}
+
+// PostWithContext makes a POST request bound to ctx
+func (c *HTTPClient) PostWithContext(ctx context.Context, path string, data interface{}, result interface{}, opts ...RequestOption) error {
+	return c.sendWithBody(ctx, "POST", path, data, result, opts)
+}
+
+// Patch makes a PATCH request
+func (c *HTTPClient) Patch(path string, data interface{}, result interface{}, opts ...RequestOption) error {
+	return c.PatchWithContext(context.Background(), path, data, result, opts...)
+}
+
+// PatchWithContext makes a PATCH request bound to ctx
+func (c *HTTPClient) PatchWithContext(ctx context.Context, path string, data interface{}, result interface{}, opts ...RequestOption) error {
+	return c.sendWithBody(ctx, "PATCH", path, data, result, opts)
+}
+
+// Delete makes a DELETE request
+func (c *HTTPClient) Delete(path string, result interface{}, opts ...RequestOption) error {
+	return c.DeleteWithContext(context.Background(), path, result, opts...)
+}
+
+// DeleteWithContext makes a DELETE request bound to ctx
+func (c *HTTPClient) DeleteWithContext(ctx context.Context, path string, result interface{}, opts ...RequestOption) error {
+	headers, _, err := c.signBody(nil)
 	if err != nil {
 		return fmt.Errorf("failed to create auth headers: %w", err)
 	}
 
-	var body []byte
-	if data != nil {
-		body, err = json.Marshal(data)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
-		}
-	}
+	return c.execute(ctx, "DELETE", c.baseURL+path, headers, nil, result, newRequestOptions(opts))
+}
 
-	req, err := http.NewRequest("POST", c.baseURL+path, bytes.NewBuffer(body))
+// signBody marshals data with the client's Marshal and signs it, returning
+// the headers and the JSON body, which is exactly what was signed. The
+// default Marshal sorts map keys, so the same data always produces the same
+// body and signature. A nil data signs defaultPayload and has no body.
+func (c *HTTPClient) signBody(data interface{}) (map[string]string, []byte, error) {
+	payload := data
+	if payload == nil {
+		payload = defaultPayload
+	}
+	jsonBytes, err := c.marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	headers, _, err := signJSON(c.signer, jsonBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data == nil {
+		return headers, nil, nil
 	}
+	return headers, jsonBytes, nil
+}
 
-	return c.doRequest(req, result)
+// signGet marshals and signs the sig_payload of a GET request, returning the
+// headers and the encoded payload. query holds the request's other query
+// parameters, signed as well when the signer is a QuerySigner.
+func (c *HTTPClient) signGet(sigPayload map[string]interface{}, query url.Values) (map[string]string, string, error) {
+	var payload interface{} = defaultPayload
+	if sigPayload != nil {
+		payload = sigPayload
+	}
+	jsonBytes, err := c.marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return signQueryJSON(c.signer, jsonBytes, query)
 }
 
-// Patch makes a PATCH request
-func (c *HTTPClient) Patch(path string, data interface{}, result interface{}) error {
-	headers, err := createAuthHeaders(c.accountID, c.sharedSecret, data)
+// sendWithBody signs data and sends it as the JSON body of a request
+func (c *HTTPClient) sendWithBody(ctx context.Context, method, path string, data interface{}, result interface{}, opts []RequestOption) error {
+	headers, body, err := c.signBody(data)
 	if err != nil {
 		return fmt.Errorf("failed to create auth headers: %w", err)
 	}
 
-	var body []byte
-	if data != nil {
-		body, err = json.Marshal(data)
-		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+	o := newRequestOptions(opts)
+	if c.observesBodies() {
+		o.logBody = c.redactBody(body)
+	}
+
+	// The signature covers the JSON payload, so compressing afterwards
+	// doesn't affect it
+	if c.compress && len(body) >= minCompressSize {
+		if body, err = gzipBody(body); err != nil {
+			return err
 		}
+		headers["Content-Encoding"] = "gzip"
 	}
 
-	req, err := http.NewRequest("PATCH", c.baseURL+path, bytes.NewBuffer(body))
+	return c.execute(ctx, method, c.baseURL+path, headers, body, result, o)
+}
+
+// execute sends the request, retrying transient failures when the request
+// is safe to repeat, and traces and measures the call when a tracer or
+// metrics observer is configured
+func (c *HTTPClient) execute(ctx context.Context, method, fullURL string, headers map[string]string, body []byte, result interface{}, o *requestOptions) error {
+	if err := c.begin(); err != nil {
+		return err
+	}
+	defer c.end()
+	if err := c.limiter.acquire(ctx); err != nil {
+		return err
+	}
+	defer c.limiter.release()
+	if c.metrics != nil {
+		defer c.observeRequest(method, o, time.Now())
+	}
+	if c.tracer == nil {
+		return c.executeAttempts(ctx, method, fullURL, headers, body, result, o)
+	}
+
+	ctx, span := c.startSpan(ctx, method, fullURL, o)
+	defer span.End()
+
+	err := c.executeAttempts(ctx, method, fullURL, headers, body, result, o)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		span.RecordError(err)
 	}
+	return err
+}
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+// executeAttempts sends the request until it succeeds, fails permanently or
+// runs out of retries
+func (c *HTTPClient) executeAttempts(ctx context.Context, method, fullURL string, headers map[string]string, body []byte, result interface{}, o *requestOptions) error {
+	for key, value := range o.headers {
+		headers[key] = value
 	}
+	retryable := isIdempotent(method, headers)
+	client := c.httpClientFor(o)
+
+	reqCtx := withRequiredHeaders(ctx, headers)
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(reqCtx, method, fullURL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
 
-	return c.doRequest(req, result)
+		// Set the caller's extra headers first so the SDK's own win
+		for key, values := range o.extraHeaders {
+			req.Header[key] = values
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+		req.Header.Set("Accept-Encoding", "gzip")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+
+		canRetry := retryable && !o.noRetry && attempt < c.maxRetries
+
+		if err := c.breaker.allow(); err != nil {
+			if lastErr != nil {
+				return fmt.Errorf("%w: %w", err, lastErr)
+			}
+			return err
+		}
+		attemptStart := time.Now()
+		resp, err := c.do(client, req, attempt, o.logBody)
+		attemptDuration := time.Since(attemptStart)
+		c.breaker.record(attemptOutcome(ctx, resp, err))
+		if resp != nil {
+			o.recordResponse(resp)
+		}
+		if err != nil {
+			// Surface cancellation as-is so callers can match context.Canceled
+			// and context.DeadlineExceeded directly
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return deadlineError(ctxErr, lastErr)
+			}
+			err = fmt.Errorf("request failed: %w", err)
+			if errors.Is(err, ErrUnexpectedRedirect) {
+				// Another attempt would be redirected the same way
+				return err
+			}
+		} else if canRetry && c.isRetryableStatus(resp.StatusCode) {
+			err = c.handleResponse(ctx, resp, nil)
+		} else {
+			return c.handleResponse(ctx, resp, result)
+		}
+
+		if !canRetry {
+			return err
+		}
+
+		// Prefer the server's Retry-After hint over our own backoff
+		delay := c.retryBackoff(attempt + 1)
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+			delay = rateLimitErr.RetryAfter
+		}
+		// Give up now rather than start a retry that can't finish before the
+		// deadline, judging by how long this attempt took
+		if exceedsDeadline(ctx, delay+attemptDuration) {
+			return errors.Join(context.DeadlineExceeded, err)
+		}
+		if !c.retryBudget.take() {
+			return err
+		}
+		if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+			return deadlineError(sleepErr, err)
+		}
+		lastErr = err
+	}
 }
 
-// Delete makes a DELETE request
-func (c *HTTPClient) Delete(path string, result interface{}) error {
-	headers, err := createAuthHeaders(c.accountID, c.sharedSecret, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create auth headers: %w", err)
+// rawResponse is passed as the result of a request whose successful
+// response body is copied to w as-is instead of being decoded as JSON
+type rawResponse struct {
+	w           io.Writer
+	contentType string
+
+	// allowJSON accepts a JSON body, for endpoints that return either JSON
+	// or binary content
+	allowJSON bool
+}
+
+// download makes a GET request and streams the successful response body to
+// w, returning its content type. Error responses are decoded as usual.
+func (c *HTTPClient) download(ctx context.Context, path string, sigPayload map[string]interface{}, accept string, w io.Writer, opts []RequestOption) (string, error) {
+	raw := &rawResponse{w: w}
+	if err := c.getRaw(ctx, path, sigPayload, accept, raw, opts); err != nil {
+		return "", err
 	}
+	return raw.contentType, nil
+}
 
-	req, err := http.NewRequest("DELETE", c.baseURL+path, nil)
+// getRaw makes a GET request whose successful response body is copied into
+// raw
+func (c *HTTPClient) getRaw(ctx context.Context, path string, sigPayload map[string]interface{}, accept string, raw *rawResponse, opts []RequestOption) error {
+	o := newRequestOptions(opts)
+	headers, encodedPayload, err := c.signGet(sigPayload, o.query)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create auth headers: %w", err)
 	}
+	headers["Accept"] = accept
 
-	for key, value := range headers {
-		req.Header.Set(key, value)
+	fullURL, err := c.signedURL(path, encodedPayload, o.query)
+	if err != nil {
+		return err
 	}
 
-	return c.doRequest(req, result)
+	return c.execute(ctx, "GET", fullURL, headers, nil, raw, o)
 }
 
-// doRequest executes the HTTP request and handles the response
-func (c *HTTPClient) doRequest(req *http.Request, result interface{}) error {
-	resp, err := c.client.Do(req)
+// handleResponse reads the response and decodes it into result
+func (c *HTTPClient) handleResponse(ctx context.Context, resp *http.Response, result interface{}) error {
+	defer resp.Body.Close()
+
+	c.recordRateLimit(resp.Header)
+
+	if raw, ok := result.(*rawResponse); ok && resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return c.copyResponse(ctx, resp, raw)
+	}
+
+	reader, err := responseBody(resp)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := readLimited(reader, c.maxResponseBytes)
 	if err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			err:        newAPIError(resp, body),
+		}
+	}
+
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		var errorResp struct {
-			Error   string `json:"error"`
-			Message string `json:"message"`
-		}
-		if err := json.Unmarshal(body, &errorResp); err == nil {
-			errorMsg := errorResp.Error
-			if errorMsg == "" {
-				errorMsg = errorResp.Message
-			}
-			if errorMsg != "" {
-				return fmt.Errorf("DoorPasses API Error (%d): %s", resp.StatusCode, errorMsg)
-			}
+		apiErr := newAPIError(resp, body)
+		if !c.skipAccountCheck && apiErr.Code == ErrorCodeAccountMismatch {
+			return fmt.Errorf("%w: %w", ErrAccountMismatch, apiErr)
 		}
-		return fmt.Errorf("DoorPasses API Error (%d): %s", resp.StatusCode, string(body))
+		return apiErr
 	}
 
 	if result != nil {
-		// Try to unmarshal as a response with data field
-		var dataResp struct {
-			Data json.RawMessage `json:"data"`
+		if !json.Valid(body) {
+			// e.g. a proxy's HTML page served with a 2xx status
+			return fmt.Errorf("unexpected non-JSON response (%d %s): %s",
+				resp.StatusCode, http.StatusText(resp.StatusCode), bodySnippet(body))
 		}
-		if err := json.Unmarshal(body, &dataResp); err == nil && len(dataResp.Data) > 0 {
-			if err := json.Unmarshal(dataResp.Data, result); err != nil {
-				return fmt.Errorf("failed to unmarshal data field: %w", err)
-			}
-		} else {
-			// Otherwise unmarshal directly
-			if err := json.Unmarshal(body, result); err != nil {
-				return fmt.Errorf("failed to unmarshal response: %w", err)
+		if !c.skipAccountCheck {
+			if err := checkAccount(body, c.accountID); err != nil {
+				return err
 			}
 		}
+		return decodeResult(body, result, c.unmarshal)
+	}
+
+	return nil
+}
+
+// checkAccount fails with ErrAccountMismatch when the resource in a
+// successful response body, or any item of a list, carries an accountId
+// other than accountID. Responses without an accountId pass.
+func checkAccount(body []byte, accountID string) error {
+	if !bytes.Contains(body, []byte(`"accountId"`)) {
+		return nil
+	}
+
+	var envelope struct {
+		AccountID string          `json:"accountId"`
+		Data      json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		// Not an object; leave the error to decodeResult
+		return nil
+	}
+
+	type scoped struct {
+		AccountID string `json:"accountId"`
+	}
+	var data struct {
+		scoped
+		Items []scoped `json:"items"`
+	}
+	var items []scoped
+	if json.Unmarshal(envelope.Data, &data) != nil {
+		json.Unmarshal(envelope.Data, &items)
 	}
 
+	accounts := []string{envelope.AccountID, data.AccountID}
+	for _, item := range append(data.Items, items...) {
+		accounts = append(accounts, item.AccountID)
+	}
+	for _, account := range accounts {
+		if account != "" && account != accountID {
+			return fmt.Errorf("%w: got account %q, client is configured for %q", ErrAccountMismatch, account, accountID)
+		}
+	}
+	return nil
+}
+
+// decodeResult unmarshals a successful response body into result with
+// unmarshal, unwrapping the API's data envelope when present. The envelope
+// itself is always read with encoding/json.
+func decodeResult(body []byte, result interface{}, unmarshal func(data []byte, v interface{}) error) error {
+	// Try to unmarshal as a response with data field
+	var dataResp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &dataResp); err == nil && len(dataResp.Data) > 0 {
+		if err := unmarshal(dataResp.Data, result); err != nil {
+			return fmt.Errorf("failed to unmarshal data field: %w", err)
+		}
+	} else {
+		// Otherwise unmarshal directly
+		if err := unmarshal(body, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyResponse streams a successful response body into raw. Unless
+// raw.allowJSON is set, a JSON body is rejected since it means the API didn't
+// return the requested binary content.
+func (c *HTTPClient) copyResponse(ctx context.Context, resp *http.Response, raw *rawResponse) error {
+	raw.contentType = resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(raw.contentType); !raw.allowJSON && mediaType == "application/json" {
+		return fmt.Errorf("unexpected response content type %q", raw.contentType)
+	}
+
+	reader, err := responseBody(resp)
+	if err != nil {
+		return err
+	}
+
+	if err := copyLimited(raw.w, reader, c.maxDownloadBytes); err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return err
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
 	return nil
 }
@@ -0,0 +1,328 @@
+package doorpasses
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestClient(t *testing.T, config *Config, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if config == nil {
+		config = &Config{}
+	}
+	config.BaseURL = server.URL
+
+	client, err := NewClient("test_account", "test_secret", config)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+func TestHTTPClientContextCancellation(t *testing.T) {
+	release := make(chan struct{})
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	t.Cleanup(func() { close(release) })
+
+	tests := []struct {
+		name    string
+		ctx     func() (context.Context, context.CancelFunc)
+		wantErr error
+	}{
+		{
+			name: "cancelled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantErr: context.Canceled,
+		},
+		{
+			name: "deadline exceeded",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			_, err := client.AccessPasses.IssueWithContext(ctx, validIssueParams())
+			if err != tt.wantErr {
+				t.Errorf("IssueWithContext() error = %v, want %v", err, tt.wantErr)
+			}
+
+			_, err = client.HealthWithContext(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("HealthWithContext() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRetries(t *testing.T) {
+	noBackoff := func(attempt int) time.Duration { return 0 }
+
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxRetries   int
+		idempotent   bool
+		wantErr      bool
+		wantRequests int32
+	}{
+		{
+			name:         "retries 5xx until success",
+			statuses:     []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			idempotent:   true,
+			wantErr:      false,
+			wantRequests: 3,
+		},
+		{
+			name:         "gives up after max retries",
+			statuses:     []int{http.StatusGatewayTimeout},
+			maxRetries:   2,
+			idempotent:   true,
+			wantErr:      true,
+			wantRequests: 3,
+		},
+		{
+			name:         "does not retry 4xx",
+			statuses:     []int{http.StatusBadRequest},
+			idempotent:   true,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "does not retry non-idempotent requests",
+			statuses:     []int{http.StatusServiceUnavailable},
+			idempotent:   false,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+		{
+			name:         "retries disabled",
+			statuses:     []int{http.StatusServiceUnavailable},
+			maxRetries:   -1,
+			idempotent:   true,
+			wantErr:      true,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			client := newTestClient(t, &Config{
+				MaxRetries:   tt.maxRetries,
+				RetryBackoff: noBackoff,
+			}, func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&requests, 1))
+				status := tt.statuses[len(tt.statuses)-1]
+				if n <= len(tt.statuses) {
+					status = tt.statuses[n-1]
+				}
+				w.WriteHeader(status)
+				w.Write([]byte(`{"success": true}`))
+			})
+
+			var err error
+			if tt.idempotent {
+				err = client.http.Get("/health", nil, nil)
+			} else {
+				err = client.http.Post("/v1/access-passes", nil, nil)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("request error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("server received %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestHTTPClientNonJSONResponses(t *testing.T) {
+	page := "<html><head><title>502 Bad Gateway</title></head><body>nginx</body></html>"
+
+	tests := []struct {
+		name       string
+		statusCode int
+		wantStatus int
+		wantErr    string
+	}{
+		{
+			name:       "error status",
+			statusCode: http.StatusBadGateway,
+			wantStatus: http.StatusBadGateway,
+			wantErr:    "DoorPasses API Error (502): Bad Gateway",
+		},
+		{
+			name:       "success status",
+			statusCode: http.StatusOK,
+			wantErr:    "unexpected non-JSON response (200 OK): " + page,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(page))
+			})
+
+			_, err := client.Health()
+			if err == nil {
+				t.Fatal("Health() returned no error")
+			}
+			if err.Error() != tt.wantErr {
+				t.Errorf("Health() error = %q, want %q", err, tt.wantErr)
+			}
+
+			var apiErr *APIError
+			if tt.wantStatus == 0 {
+				if errors.As(err, &apiErr) {
+					t.Errorf("Health() error = %v, want no APIError", err)
+				}
+				return
+			}
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Health() error = %v, want APIError", err)
+			}
+			if apiErr.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, tt.wantStatus)
+			}
+			if apiErr.Body != page {
+				t.Errorf("Body = %q, want %q", apiErr.Body, page)
+			}
+		})
+	}
+}
+
+func TestHTTPClientCustomJSON(t *testing.T) {
+	var marshalled, unmarshalled atomic.Int32
+	marshal := func(v interface{}) ([]byte, error) {
+		marshalled.Add(1)
+		return json.Marshal(v)
+	}
+	strict := func(data []byte, v interface{}) error {
+		unmarshalled.Add(1)
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(v)
+	}
+
+	var response atomic.Value
+	response.Store(`{"success": true, "data": {"id": "pass_123", "fullName": "John Doe"}}`)
+	client := newTestClient(t, &Config{Marshal: marshal, Unmarshal: strict}, func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodGet {
+			payload, _ = base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		} else if len(payload) == 0 {
+			payload = []byte(`{"id":"0"}`)
+		}
+		if !verifySignature("test_secret", base64.StdEncoding.EncodeToString(payload), r.Header.Get("X-PAYLOAD-SIG")) {
+			t.Errorf("%s request body is not what was signed", r.Method)
+		}
+		w.Write([]byte(response.Load().(string)))
+	})
+
+	if _, err := client.AccessPasses.Get("pass_123"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	fullName := "Jane Doe"
+	if _, err := client.AccessPasses.Patch("pass_123", PatchAccessPassParams{FullName: &fullName}); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+	if marshalled.Load() != 2 || unmarshalled.Load() != 2 {
+		t.Errorf("Marshal called %d times and Unmarshal %d times, want 2 each", marshalled.Load(), unmarshalled.Load())
+	}
+
+	response.Store(`{"success": true, "data": {"success": true, "channel": "email", "trackingId": "trk_1"}}`)
+	if _, err := client.AccessPasses.ResendInvite("pass_123", ""); err == nil {
+		t.Error("ResendInvite() with an unknown field returned no error from strict decoding")
+	}
+}
+
+func TestAccountCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		disable    bool
+		wantErr    bool
+	}{
+		{
+			name:       "no account in response",
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123"}}`,
+		},
+		{
+			name:       "matching account",
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123", "accountId": "test_account"}}`,
+		},
+		{
+			name:       "other account",
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123", "accountId": "other_account"}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "other account in list item",
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"items": [{"id": "pass_1", "accountId": "test_account"}, {"id": "pass_2", "accountId": "other_account"}]}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "mismatch reported by the API",
+			statusCode: http.StatusNotFound,
+			body:       `{"success": false, "error": {"code": "ACCOUNT_MISMATCH", "message": "Access pass belongs to another account"}}`,
+			wantErr:    true,
+		},
+		{
+			name:       "check disabled",
+			statusCode: http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123", "accountId": "other_account"}}`,
+			disable:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{DisableAccountCheck: tt.disable}, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.body))
+			})
+
+			err := client.Do(context.Background(), http.MethodGet, "/v1/access-passes/pass_123", nil, &map[string]interface{}{})
+			if errors.Is(err, ErrAccountMismatch) != tt.wantErr {
+				t.Errorf("Do() error = %v, want ErrAccountMismatch: %v", err, tt.wantErr)
+			}
+			if err != nil && !tt.wantErr {
+				t.Errorf("Do() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package doorpasses
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// idempotencyKeyHeader is the header carrying a request's idempotency key
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// newIdempotencyKey generates a random version 4 UUID
+func newIdempotencyKey() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate idempotency key: %w", err)
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,220 @@
+package doorpasses
+
+import (
+	"context"
+	"time"
+)
+
+// AccessPassIterator iterates over access passes, fetching pages on demand.
+// Unlike Client, an iterator is not safe for concurrent use.
+//
+// Example:
+//
+//	iter := client.AccessPasses.ListAll(&doorpasses.ListAccessPassesParams{
+//	    TemplateID: "template_123",
+//	})
+//	for iter.Next() {
+//	    fmt.Println(iter.Pass().ID)
+//	}
+//	if err := iter.Err(); err != nil {
+//	    log.Fatal(err)
+//	}
+type AccessPassIterator struct {
+	ctx     context.Context
+	passes  *AccessPasses
+	params  ListAccessPassesParams
+	opts    []RequestOption
+	page    []AccessPass
+	index   int
+	current *AccessPass
+	done    bool
+	err     error
+
+	// keep, when set, skips the passes it returns false for
+	keep func(*AccessPass) bool
+
+	// pageCursor is the cursor that fetched page
+	pageCursor string
+	pageCount  int
+	itemCount  int
+}
+
+// ListAll returns an iterator over every access pass matching params
+func (a *AccessPasses) ListAll(params *ListAccessPassesParams, opts ...RequestOption) *AccessPassIterator {
+	return a.ListAllWithContext(context.Background(), params, opts...)
+}
+
+// ListAllWithContext returns an iterator over every access pass matching
+// params. Cancelling ctx stops the iteration at the next page fetch.
+func (a *AccessPasses) ListAllWithContext(ctx context.Context, params *ListAccessPassesParams, opts ...RequestOption) *AccessPassIterator {
+	opts = withOperation(opts, "AccessPasses.ListAll")
+	it := &AccessPassIterator{
+		ctx:    ctx,
+		passes: a,
+		opts:   opts,
+	}
+	if params != nil {
+		it.params = *params
+	}
+	return it
+}
+
+// ResumeListAll returns an iterator over the access passes matching params,
+// starting at cursor, e.g. the LastCursor checkpointed by an iterator that
+// was interrupted. An empty cursor starts from the beginning.
+func (a *AccessPasses) ResumeListAll(cursor string, params *ListAccessPassesParams, opts ...RequestOption) *AccessPassIterator {
+	return a.ResumeListAllWithContext(context.Background(), cursor, params, opts...)
+}
+
+// ResumeListAllWithContext returns an iterator over the access passes
+// matching params, starting at cursor. Cancelling ctx stops the iteration
+// at the next page fetch.
+func (a *AccessPasses) ResumeListAllWithContext(ctx context.Context, cursor string, params *ListAccessPassesParams, opts ...RequestOption) *AccessPassIterator {
+	opts = withOperation(opts, "AccessPasses.ResumeListAll")
+	it := a.ListAllWithContext(ctx, params, opts...)
+	it.params.Cursor = cursor
+	return it
+}
+
+// ExpiringBefore returns an iterator over the active access passes that
+// expire before t, e.g. to send renewal reminders for the passes expiring
+// in the next 30 days
+func (a *AccessPasses) ExpiringBefore(t time.Time, opts ...RequestOption) *AccessPassIterator {
+	return a.ExpiringBeforeWithContext(context.Background(), t, opts...)
+}
+
+// ExpiringBeforeWithContext returns an iterator over the active access
+// passes that expire before t. The filter is sent to the API, and results
+// are also checked on the client, so other passes are never returned even
+// if the API ignores it. Passes without a parseable expiration date are
+// skipped.
+func (a *AccessPasses) ExpiringBeforeWithContext(ctx context.Context, t time.Time, opts ...RequestOption) *AccessPassIterator {
+	opts = withOperation(opts, "AccessPasses.ExpiringBefore")
+	it := a.ListAllWithContext(ctx, &ListAccessPassesParams{
+		State:         AccessPassStateActive,
+		ExpiresBefore: t,
+	}, opts...)
+	it.keep = func(p *AccessPass) bool {
+		return p.State == AccessPassStateActive && !p.ExpiresAt.IsZero() && p.ExpiresAt.Before(t)
+	}
+	return it
+}
+
+// Next advances to the next access pass, fetching the next page when the
+// current one is exhausted. It returns false when iteration is complete or
+// an error occurred.
+func (it *AccessPassIterator) Next() bool {
+	for it.next() {
+		if it.keep == nil || it.keep(it.current) {
+			it.itemCount++
+			return true
+		}
+	}
+	return false
+}
+
+// next advances to the next access pass of the listing, whether or not it
+// is kept
+func (it *AccessPassIterator) next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.index >= len(it.page) {
+		if it.done {
+			it.current = nil
+			return false
+		}
+
+		cursor := it.params.Cursor
+		page, err := it.passes.ListPageWithContext(it.ctx, &it.params, it.opts...)
+		if err != nil {
+			it.err = err
+			it.current = nil
+			return false
+		}
+
+		it.pageCursor = cursor
+		it.pageCount++
+		it.page = page.Items
+		it.index = 0
+		if !page.HasMore || page.NextCursor == "" {
+			it.done = true
+		} else {
+			it.params.Cursor = page.NextCursor
+		}
+	}
+
+	it.current = &it.page[it.index]
+	it.index++
+	return true
+}
+
+// Pass returns the current access pass
+func (it *AccessPassIterator) Pass() *AccessPass {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *AccessPassIterator) Err() error {
+	return it.err
+}
+
+// PageCount returns the number of pages fetched so far
+func (it *AccessPassIterator) PageCount() int {
+	return it.pageCount
+}
+
+// ItemCount returns the number of access passes Next has returned so far
+func (it *AccessPassIterator) ItemCount() int {
+	return it.itemCount
+}
+
+// LastCursor returns the cursor that fetched the page of the current access
+// pass, empty for the first page. Checkpoint it to restart an interrupted
+// listing with ResumeListAll: the resumed iterator starts over at the
+// current page, so passes may be seen twice but none are skipped.
+func (it *AccessPassIterator) LastCursor() string {
+	return it.pageCursor
+}
+
+// Stream lists every access pass matching params, sending each on the
+// returned pass channel as its page arrives. Both channels are closed when
+// the listing ends; the error channel first receives the error that stopped
+// it, if any. The goroutine feeding the channels exits once ctx is done, so
+// cancel ctx to stop reading early without leaking it.
+//
+// Example:
+//
+//	passes, errs := client.AccessPasses.Stream(ctx, nil)
+//	for pass := range passes {
+//	    process(pass)
+//	}
+//	if err := <-errs; err != nil {
+//	    log.Fatal(err)
+//	}
+func (a *AccessPasses) Stream(ctx context.Context, params *ListAccessPassesParams, opts ...RequestOption) (<-chan *AccessPass, <-chan error) {
+	opts = withOperation(opts, "AccessPasses.Stream")
+	passes := make(chan *AccessPass)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(passes)
+
+		it := a.ListAllWithContext(ctx, params, opts...)
+		for it.Next() {
+			select {
+			case passes <- it.Pass():
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			errs <- err
+		}
+	}()
+
+	return passes, errs
+}
@@ -0,0 +1,257 @@
+package doorpasses
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testPages are the pages served by pagesHandler, by cursor
+var testPages = map[string]string{
+	"":         `{"success": true, "data": {"items": [{"id": "pass_1"}, {"id": "pass_2"}], "nextCursor": "cursor_2", "hasMore": true}}`,
+	"cursor_2": `{"success": true, "data": {"items": [], "nextCursor": "cursor_3", "hasMore": true}}`,
+	"cursor_3": `{"success": true, "data": {"items": [{"id": "pass_3"}], "hasMore": false}}`,
+}
+
+// pagesHandler serves testPages, failing the page at cursor fail
+func pagesHandler(fail string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		var params struct {
+			Cursor string `json:"cursor"`
+		}
+		json.Unmarshal(payload, &params)
+
+		if fail != "" && params.Cursor == fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(testPages[params.Cursor]))
+	}
+}
+
+func TestAccessPassIterator(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "all pages",
+			wantIDs: []string{"pass_1", "pass_2", "pass_3"},
+		},
+		{
+			name:    "error mid-iteration",
+			fail:    "cursor_3",
+			wantIDs: []string{"pass_1", "pass_2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, pagesHandler(tt.fail))
+
+			params := &ListAccessPassesParams{TemplateID: "template_123"}
+			iter := client.AccessPasses.ListAll(params)
+
+			var ids []string
+			for iter.Next() {
+				ids = append(ids, iter.Pass().ID)
+			}
+
+			if (iter.Err() != nil) != tt.wantErr {
+				t.Errorf("Err() = %v, wantErr %v", iter.Err(), tt.wantErr)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("iterated %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Errorf("iterated %v, want %v", ids, tt.wantIDs)
+					break
+				}
+			}
+			if params.Cursor != "" {
+				t.Errorf("ListAll() modified caller params: Cursor = %q", params.Cursor)
+			}
+		})
+	}
+}
+
+func TestAccessPassIteratorProgress(t *testing.T) {
+	client := newTestClient(t, &Config{MaxRetries: -1}, pagesHandler(""))
+
+	type progress struct {
+		id     string
+		pages  int
+		items  int
+		cursor string
+	}
+	var got []progress
+	iter := client.AccessPasses.ListAll(nil)
+	for iter.Next() {
+		got = append(got, progress{iter.Pass().ID, iter.PageCount(), iter.ItemCount(), iter.LastCursor()})
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+
+	want := []progress{
+		{"pass_1", 1, 1, ""},
+		{"pass_2", 1, 2, ""},
+		{"pass_3", 3, 3, "cursor_3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("progress = %+v, want %+v", got, want)
+	}
+}
+
+func TestAccessPassesResumeListAll(t *testing.T) {
+	client := newTestClient(t, &Config{MaxRetries: -1}, pagesHandler("cursor_3"))
+
+	// The first run fails on the last page after checkpointing its cursor
+	var checkpoint string
+	iter := client.AccessPasses.ListAll(nil)
+	for iter.Next() {
+		checkpoint = iter.LastCursor()
+	}
+	if iter.Err() == nil {
+		t.Fatal("Err() = nil, want the failure of the last page")
+	}
+
+	client = newTestClient(t, &Config{MaxRetries: -1}, pagesHandler(""))
+	params := &ListAccessPassesParams{TemplateID: "template_123"}
+	resumed := client.AccessPasses.ResumeListAll(checkpoint, params)
+	var ids []string
+	for resumed.Next() {
+		ids = append(ids, resumed.Pass().ID)
+	}
+	if err := resumed.Err(); err != nil {
+		t.Fatalf("resumed Err() = %v", err)
+	}
+	// The checkpoint is the start of the page that held pass_2, so that
+	// page is read again and nothing is skipped
+	if want := []string{"pass_1", "pass_2", "pass_3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("resumed iteration = %v, want %v", ids, want)
+	}
+	if params.Cursor != "" {
+		t.Errorf("ResumeListAll() modified caller params: Cursor = %q", params.Cursor)
+	}
+
+	resumed = client.AccessPasses.ResumeListAll("cursor_3", nil)
+	ids = nil
+	for resumed.Next() {
+		ids = append(ids, resumed.Pass().ID)
+	}
+	if want := []string{"pass_3"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("iteration resumed at cursor_3 = %v, want %v", ids, want)
+	}
+}
+
+func TestAccessPassesStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		fail    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "all pages",
+			wantIDs: []string{"pass_1", "pass_2", "pass_3"},
+		},
+		{
+			name:    "error mid-stream",
+			fail:    "cursor_3",
+			wantIDs: []string{"pass_1", "pass_2"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, pagesHandler(tt.fail))
+
+			passes, errs := client.AccessPasses.Stream(context.Background(), &ListAccessPassesParams{TemplateID: "template_123"})
+			var ids []string
+			for pass := range passes {
+				ids = append(ids, pass.ID)
+			}
+			err := <-errs
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Stream() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("streamed %v, want %v", ids, tt.wantIDs)
+			}
+			if _, ok := <-errs; ok {
+				t.Error("error channel not closed")
+			}
+		})
+	}
+}
+
+func TestAccessPassesStreamCancelled(t *testing.T) {
+	client := newTestClient(t, &Config{MaxRetries: -1}, pagesHandler(""))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	passes, errs := client.AccessPasses.Stream(ctx, nil)
+	if pass := <-passes; pass == nil || pass.ID != "pass_1" {
+		t.Fatalf("first pass = %v, want pass_1", pass)
+	}
+
+	// Stop reading; the producer must notice the cancellation and exit
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Stream() error = %v, want context.Canceled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stream() did not stop after cancellation")
+	}
+	for range passes {
+	}
+}
+
+func TestAccessPassesExpiringBefore(t *testing.T) {
+	pages := map[string]string{
+		"": `{"success": true, "data": {"items": [
+			{"id": "pass_soon", "state": "ACTIVE", "expirationDate": "2025-11-15T00:00:00Z"},
+			{"id": "pass_later", "state": "ACTIVE", "expirationDate": "2026-03-01T00:00:00Z"},
+			{"id": "pass_revoked", "state": "DELETED", "expirationDate": "2025-11-15T00:00:00Z"},
+			{"id": "pass_undated", "state": "ACTIVE"},
+			{"id": "pass_suspended", "state": "SUSPENDED", "expirationDate": "2025-11-15T00:00:00Z"}
+		], "nextCursor": "cursor_2", "hasMore": true}}`,
+		"cursor_2": `{"success": true, "data": {"items": [{"id": "pass_sooner", "state": "ACTIVE", "expirationDate": "2025-11-02T00:00:00Z"}], "hasMore": false}}`,
+	}
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		var params map[string]interface{}
+		json.Unmarshal(payload, &params)
+		if params["state"] != "ACTIVE" || params["expires_before"] != "2025-12-01T00:00:00Z" {
+			t.Errorf("sig_payload = %v, want active passes expiring before 2025-12-01T00:00:00Z", params)
+		}
+		cursor, _ := params["cursor"].(string)
+		w.Write([]byte(pages[cursor]))
+	})
+
+	iter := client.AccessPasses.ExpiringBefore(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC))
+	var ids []string
+	for iter.Next() {
+		ids = append(ids, iter.Pass().ID)
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Err() = %v", err)
+	}
+	if want := []string{"pass_soon", "pass_sooner"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("iterated %v, want %v", ids, want)
+	}
+}
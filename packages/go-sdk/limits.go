@@ -0,0 +1,60 @@
+package doorpasses
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseBytes is the default limit on the size of a JSON
+// response body
+const DefaultMaxResponseBytes = 10 << 20
+
+// DefaultMaxDownloadBytes is the default limit on the size of a binary
+// download such as a .pkpass file
+const DefaultMaxDownloadBytes = 50 << 20
+
+// ErrResponseTooLarge is returned when a response body exceeds
+// Config.MaxResponseBytes, or Config.MaxDownloadBytes for downloads
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// readLimited reads all of r, failing with ErrResponseTooLarge once more than
+// limit bytes have been read. A negative limit reads without limit.
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	if limit < 0 {
+		return io.ReadAll(r)
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > limit {
+		return nil, responseTooLarge(limit)
+	}
+	return body, nil
+}
+
+// copyLimited copies r to w, failing with ErrResponseTooLarge once more than
+// limit bytes have been read. A negative limit copies without limit.
+func copyLimited(w io.Writer, r io.Reader, limit int64) error {
+	if limit < 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	n, err := io.Copy(w, io.LimitReader(r, limit))
+	if err != nil {
+		return err
+	}
+	if n == limit {
+		// Check whether anything is left beyond the limit
+		var probe [1]byte
+		if m, _ := io.ReadFull(r, probe[:]); m > 0 {
+			return responseTooLarge(limit)
+		}
+	}
+	return nil
+}
+
+func responseTooLarge(limit int64) error {
+	return fmt.Errorf("%w: exceeds the limit of %d bytes", ErrResponseTooLarge, limit)
+}
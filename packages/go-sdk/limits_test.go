@@ -0,0 +1,130 @@
+package doorpasses
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestReadLimited(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "under limit", body: "abc", limit: 4},
+		{name: "at limit", body: "abcd", limit: 4},
+		{name: "over limit", body: "abcde", limit: 4, wantErr: true},
+		{name: "no limit", body: "abcde", limit: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readLimited(strings.NewReader(tt.body), tt.limit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readLimited() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Errorf("readLimited() error = %v, want ErrResponseTooLarge", err)
+				}
+				return
+			}
+			if string(got) != tt.body {
+				t.Errorf("readLimited() = %q, want %q", got, tt.body)
+			}
+
+			var buf bytes.Buffer
+			if err := copyLimited(&buf, strings.NewReader(tt.body), tt.limit); err != nil {
+				t.Fatalf("copyLimited() error = %v", err)
+			}
+			if buf.String() != tt.body {
+				t.Errorf("copyLimited() wrote %q, want %q", buf.String(), tt.body)
+			}
+		})
+	}
+
+	if err := copyLimited(&bytes.Buffer{}, strings.NewReader("abcde"), 4); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("copyLimited() error = %v, want ErrResponseTooLarge", err)
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	large := `{"success": true, "data": {"status": "` + strings.Repeat("x", 1024) + `"}}`
+
+	tests := []struct {
+		name    string
+		config  *Config
+		gzip    bool
+		wantErr bool
+	}{
+		{name: "default limit", config: nil},
+		{name: "over limit", config: &Config{MaxResponseBytes: 512}, wantErr: true},
+		{name: "over limit after decompression", config: &Config{MaxResponseBytes: 512}, gzip: true, wantErr: true},
+		{name: "limit disabled", config: &Config{MaxResponseBytes: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				if !tt.gzip {
+					w.Write([]byte(large))
+					return
+				}
+				w.Header().Set("Content-Encoding", "gzip")
+				zw := gzip.NewWriter(w)
+				zw.Write([]byte(large))
+				zw.Close()
+			})
+
+			_, err := client.Health()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Health() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrResponseTooLarge) {
+				t.Errorf("Health() error = %v, want ErrResponseTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestMaxDownloadBytes(t *testing.T) {
+	pkpass := append([]byte("PK\x03\x04"), bytes.Repeat([]byte{0}, 2048)...)
+
+	tests := []struct {
+		name    string
+		config  *Config
+		wantErr bool
+	}{
+		{name: "default limit", config: nil},
+		{name: "larger than JSON limit", config: &Config{MaxResponseBytes: 512}},
+		{name: "over limit", config: &Config{MaxDownloadBytes: 1024}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", ApplePassContentType)
+				w.Write(pkpass)
+			})
+
+			got, _, err := client.AccessPasses.DownloadApplePass("pass_123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadApplePass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrResponseTooLarge) {
+					t.Errorf("DownloadApplePass() error = %v, want ErrResponseTooLarge", err)
+				}
+				return
+			}
+			if !bytes.Equal(got, pkpass) {
+				t.Errorf("DownloadApplePass() returned %d bytes, want %d", len(got), len(pkpass))
+			}
+		})
+	}
+}
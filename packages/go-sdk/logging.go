@@ -0,0 +1,166 @@
+package doorpasses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// redacted replaces sensitive values in log output
+const redacted = "REDACTED"
+
+// DefaultRedactFields are the request body fields redacted before a body is
+// logged or handed to Config.OnRequest when Config.RedactFields is nil
+var DefaultRedactFields = []string{"cardNumber", "email", "fullName"}
+
+// configureObservability applies the logger, hooks and tracer from config
+func (c *HTTPClient) configureObservability(config *Config) {
+	c.tracer = config.Tracer
+	c.logger = config.Logger
+	c.onRequest = config.OnRequest
+	c.onResponse = config.OnResponse
+	c.metrics = config.MetricsObserver
+
+	fields := config.RedactFields
+	if fields == nil {
+		fields = DefaultRedactFields
+	}
+	c.redactFields = make(map[string]bool, len(fields))
+	for _, field := range fields {
+		c.redactFields[strings.ToLower(field)] = true
+	}
+}
+
+// observesBodies reports whether request bodies are logged or passed to a
+// hook, and so need a redacted copy
+func (c *HTTPClient) observesBodies() bool {
+	return c.logger != nil || c.onRequest != nil
+}
+
+// redactBody returns a copy of the JSON body with the value of every
+// configured sensitive field replaced, at any depth. A body that isn't JSON
+// is redacted whole.
+func (c *HTTPClient) redactBody(body []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return []byte(redacted)
+	}
+	out, err := json.Marshal(c.redactValue(v))
+	if err != nil {
+		return []byte(redacted)
+	}
+	return out
+}
+
+// redactValue replaces the sensitive fields of a decoded JSON value
+func (c *HTTPClient) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if c.redactFields[strings.ToLower(key)] {
+				v[key] = redacted
+			} else {
+				v[key] = c.redactValue(value)
+			}
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = c.redactValue(value)
+		}
+	}
+	return v
+}
+
+// do sends a single request attempt, reporting it to the configured tracer,
+// hooks and logger. logBody is the redacted request body, if any.
+func (c *HTTPClient) do(client *http.Client, req *http.Request, attempt int, logBody []byte) (*http.Response, error) {
+	c.traceAttempt(req)
+	if c.onRequest != nil {
+		c.onRequest(hookRequest(req, logBody))
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	duration := time.Since(start)
+
+	c.traceResponse(req.Context(), resp)
+
+	if c.onResponse != nil {
+		c.onResponse(req, resp, err, duration)
+	}
+	c.logAttempt(req.Context(), req, logBody, resp, err, attempt, duration)
+
+	return resp, err
+}
+
+// hookRequest returns the view of req handed to Config.OnRequest: a shallow
+// copy sharing its headers, so a hook can still add some, but carrying the
+// redacted body and query payload instead of the real ones
+func hookRequest(req *http.Request, logBody []byte) *http.Request {
+	view := req.WithContext(req.Context())
+	u := *req.URL
+	if q := u.Query(); q.Has("sig_payload") {
+		q.Set("sig_payload", redacted)
+		u.RawQuery = q.Encode()
+	}
+	view.URL = &u
+
+	view.Body, view.GetBody, view.ContentLength = http.NoBody, nil, 0
+	if logBody != nil {
+		view.Body = io.NopCloser(bytes.NewReader(logBody))
+		view.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(logBody)), nil
+		}
+		view.ContentLength = int64(len(logBody))
+	}
+	return view
+}
+
+// logAttempt logs a request attempt at debug level. Auth headers are never
+// logged, and the signed query payload and sensitive body fields are
+// redacted.
+func (c *HTTPClient) logAttempt(ctx context.Context, req *http.Request, logBody []byte, resp *http.Response, err error, attempt int, duration time.Duration) {
+	if c.logger == nil || !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("url", redactURL(req.URL)),
+		slog.Int("attempt", attempt+1),
+		slog.Duration("duration", duration),
+	}
+	if logBody != nil {
+		attrs = append(attrs, slog.String("body", string(logBody)))
+	}
+	if resp != nil {
+		attrs = append(attrs,
+			slog.Int("status", resp.StatusCode),
+			slog.String("request_id", requestIDFromHeader(resp.Header)),
+		)
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "doorpasses request", attrs...)
+}
+
+// redactURL returns u as a string with the sig_payload query parameter
+// redacted, since it can carry personal data
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	if !q.Has("sig_payload") {
+		return u.String()
+	}
+	q.Set("sig_payload", redacted)
+	clone := *u
+	clone.RawQuery = q.Encode()
+	return clone.String()
+}
@@ -0,0 +1,153 @@
+package doorpasses
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHTTPClientLogging(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	var signature string
+	client := newTestClient(t, &Config{Logger: logger}, func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-PAYLOAD-SIG")
+		w.Header().Set("X-Request-ID", "req_123")
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	if _, err := client.AccessPasses.Get("pass_123"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	encoded, _ := encodePayload(map[string]interface{}{"id": "pass_123"})
+	out := logs.String()
+	for _, want := range []string{"method=GET", "/v1/access-passes/pass_123", "status=200", "request_id=req_123", "sig_payload=" + redacted} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q: %s", want, out)
+		}
+	}
+	for _, secret := range []string{"test_secret", signature, encoded} {
+		if secret != "" && strings.Contains(out, secret) {
+			t.Errorf("log output leaks %q: %s", secret, out)
+		}
+	}
+}
+
+func TestHTTPClientHooks(t *testing.T) {
+	var requests, responses int
+	var gotStatus int
+	config := &Config{
+		OnRequest: func(req *http.Request) {
+			requests++
+		},
+		OnResponse: func(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+			responses++
+			if err != nil {
+				t.Errorf("OnResponse() error = %v", err)
+				return
+			}
+			gotStatus = resp.StatusCode
+		},
+	}
+	client := newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status": "healthy"}`))
+	})
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if requests != 1 || responses != 1 {
+		t.Errorf("hooks called %d/%d times, want 1/1", requests, responses)
+	}
+	if gotStatus != http.StatusOK {
+		t.Errorf("OnResponse() status = %d, want %d", gotStatus, http.StatusOK)
+	}
+}
+
+func TestHTTPClientRedactsBody(t *testing.T) {
+	tests := []struct {
+		name         string
+		redactFields []string
+		compress     bool
+		hidden       []string
+		shown        []string
+	}{
+		{
+			name:   "default fields",
+			hidden: []string{"12345", "John Doe", "john@example.com"},
+			shown:  []string{"template_123"},
+		},
+		{
+			name:     "default fields compressed",
+			compress: true,
+			hidden:   []string{"12345", "John Doe", "john@example.com"},
+			shown:    []string{"template_123"},
+		},
+		{
+			name:         "custom fields",
+			redactFields: []string{"cardNumber", "cardTemplateId"},
+			hidden:       []string{"12345", "template_123"},
+			shown:        []string{"John Doe"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			var hookBody []byte
+			var sentBody []byte
+			config := &Config{
+				Logger:           slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+				RedactFields:     tt.redactFields,
+				CompressRequests: tt.compress,
+				// The filler in Metadata["notes"] is over the client-side
+				// metadata limits, which only the SDK enforces
+				DisableClientValidation: true,
+				OnRequest: func(req *http.Request) {
+					hookBody, _ = io.ReadAll(req.Body)
+				},
+			}
+			client := newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+				sentBody, _ = io.ReadAll(r.Body)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			params := validIssueParams()
+			// Large enough to be compressed when CompressRequests is set
+			params.Metadata = map[string]interface{}{
+				"badge": map[string]interface{}{"cardNumber": "12345"},
+				"notes": strings.Repeat("x", 2*minCompressSize),
+			}
+			if _, err := client.AccessPasses.Issue(params); err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+
+			out := logs.String()
+			for _, value := range tt.hidden {
+				if strings.Contains(out, value) {
+					t.Errorf("log output leaks %q: %s", value, out)
+				}
+				if bytes.Contains(hookBody, []byte(value)) {
+					t.Errorf("OnRequest body leaks %q: %s", value, hookBody)
+				}
+			}
+			for _, value := range tt.shown {
+				if !strings.Contains(out, value) {
+					t.Errorf("log output missing %q: %s", value, out)
+				}
+				if !bytes.Contains(hookBody, []byte(value)) {
+					t.Errorf("OnRequest body missing %q: %s", value, hookBody)
+				}
+			}
+			if !tt.compress && !bytes.Contains(sentBody, []byte("12345")) {
+				t.Errorf("sent body = %s, want the real card number", sentBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package doorpasses
+
+import (
+	"strings"
+	"time"
+	"unicode"
+)
+
+// MetricsObserver receives a measurement for every API call. Implement it
+// with an adapter around your metrics library, e.g. a Prometheus histogram
+// labelled by operation and status.
+type MetricsObserver interface {
+	// ObserveRequest is called once a call returns. operation names the SDK
+	// method, e.g. "access_passes.issue", status is the HTTP status of the
+	// last response, or 0 when none was received, and duration covers every
+	// attempt including retry delays.
+	ObserveRequest(operation string, status int, duration time.Duration)
+}
+
+// observeRequest reports a call that started at start to the metrics
+// observer
+func (c *HTTPClient) observeRequest(method string, o *requestOptions, start time.Time) {
+	c.metrics.ObserveRequest(metricsOperation(method, o.operation), o.statusCode, time.Since(start))
+}
+
+// metricsOperation converts an operation name such as
+// "AccessPasses.EnrollmentURL" to "access_passes.enrollment_url", falling
+// back to the lowercased HTTP method for requests made outside an SDK method
+func metricsOperation(method, operation string) string {
+	if operation == "" {
+		return strings.ToLower(method)
+	}
+
+	var b strings.Builder
+	runes := []rune(operation)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && runes[i-1] != '.' {
+			// Start a new word after a lowercase letter, or at the last
+			// capital of an acronym followed by a lowercase letter
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,114 @@
+package doorpasses
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type observation struct {
+	operation string
+	status    int
+	duration  time.Duration
+}
+
+type testMetrics struct {
+	mu           sync.Mutex
+	observations []observation
+}
+
+func (m *testMetrics) ObserveRequest(operation string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observations = append(m.observations, observation{operation, status, duration})
+}
+
+func TestMetricsObserver(t *testing.T) {
+	metrics := &testMetrics{}
+	var flaky atomic.Int32
+	client := newTestClient(t, &Config{
+		MetricsObserver: metrics,
+		RetryBackoff:    func(int) time.Duration { return 0 },
+	}, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/access-passes/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+		case "/v1/access-passes/flaky":
+			if flaky.Add(1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"success": true, "data": {"id": "flaky"}}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+		}
+	})
+
+	client.AccessPasses.Issue(validIssueParams())
+	client.AccessPasses.Get("missing")
+	client.AccessPasses.Get("flaky")
+
+	want := []observation{
+		{operation: "access_passes.issue", status: http.StatusCreated},
+		{operation: "access_passes.get", status: http.StatusNotFound},
+		// Retries are part of one call, observed with the final status
+		{operation: "access_passes.get", status: http.StatusOK},
+	}
+	if len(metrics.observations) != len(want) {
+		t.Fatalf("observations = %+v, want %d", metrics.observations, len(want))
+	}
+	for i, got := range metrics.observations {
+		if got.operation != want[i].operation || got.status != want[i].status {
+			t.Errorf("observation %d = %s %d, want %s %d", i, got.operation, got.status, want[i].operation, want[i].status)
+		}
+		if got.duration <= 0 {
+			t.Errorf("observation %d duration = %v, want > 0", i, got.duration)
+		}
+	}
+}
+
+func TestMetricsObserverNoResponse(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	metrics := &testMetrics{}
+	client, err := NewClient("test_account", "test_secret", &Config{BaseURL: server.URL, MetricsObserver: metrics, MaxRetries: -1})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.AccessPasses.Get("pass_123"); err == nil {
+		t.Fatal("Get() error = nil, want network error")
+	}
+	if len(metrics.observations) != 1 || metrics.observations[0].status != 0 {
+		t.Errorf("observations = %+v, want one with status 0", metrics.observations)
+	}
+}
+
+func TestMetricsOperation(t *testing.T) {
+	tests := []struct {
+		method    string
+		operation string
+		want      string
+	}{
+		{method: "POST", operation: "AccessPasses.Issue", want: "access_passes.issue"},
+		{method: "GET", operation: "AccessPasses.ListEventsPage", want: "access_passes.list_events_page"},
+		{method: "GET", operation: "AccessPasses.EnrollmentURL", want: "access_passes.enrollment_url"},
+		{method: "GET", operation: "Console.ReadTemplate", want: "console.read_template"},
+		{method: "GET", operation: "Client.HealthCheck", want: "client.health_check"},
+		{method: "DELETE", want: "delete"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := metricsOperation(tt.method, tt.operation); got != tt.want {
+				t.Errorf("metricsOperation(%q, %q) = %q, want %q", tt.method, tt.operation, got, tt.want)
+			}
+		})
+	}
+}
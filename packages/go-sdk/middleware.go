@@ -0,0 +1,61 @@
+package doorpasses
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware wraps the transport used to send requests, e.g. to add
+// correlation IDs, record metrics or start tracing spans
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// requiredHeadersKey is the context key under which execute stores the
+// headers the SDK requires on a request
+type requiredHeadersKey struct{}
+
+// withRequiredHeaders records headers that must reach the network unchanged
+func withRequiredHeaders(ctx context.Context, headers map[string]string) context.Context {
+	return context.WithValue(ctx, requiredHeadersKey{}, headers)
+}
+
+// requiredHeadersTransport sits beneath user middleware and restores the
+// SDK's auth and signing headers, so middleware can't strip or alter them
+type requiredHeadersTransport struct {
+	next http.RoundTripper
+}
+
+func (t *requiredHeadersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	headers, _ := req.Context().Value(requiredHeadersKey{}).(map[string]string)
+	if len(headers) == 0 {
+		return t.next.RoundTrip(req)
+	}
+
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// withMiddleware returns a copy of client whose transport is wrapped by
+// middleware, the first entry being the outermost
+func withMiddleware(client *http.Client, middleware []Middleware) *http.Client {
+	if len(middleware) == 0 {
+		return client
+	}
+
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	var transport http.RoundTripper = &requiredHeadersTransport{next: base}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+
+	clone := *client
+	clone.Transport = transport
+	return &clone
+}
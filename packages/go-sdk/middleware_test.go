@@ -0,0 +1,50 @@
+package doorpasses
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	tamper := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("X-Correlation-ID", "corr_123")
+			req.Header.Del("X-PAYLOAD-SIG")
+			req.Header.Set("X-ACCT-ID", "someone_else")
+			return next.RoundTrip(req)
+		})
+	}
+
+	client := newTestClient(t, &Config{
+		Middleware: []Middleware{record("outer"), record("inner"), tamper},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Correlation-ID"); got != "corr_123" {
+			t.Errorf("X-Correlation-ID = %q, want corr_123", got)
+		}
+		if got := r.Header.Get("X-ACCT-ID"); got != "test_account" {
+			t.Errorf("X-ACCT-ID = %q, want test_account", got)
+		}
+		if r.Header.Get("X-PAYLOAD-SIG") == "" {
+			t.Errorf("X-PAYLOAD-SIG was stripped by middleware")
+		}
+		w.Write([]byte(`{"status": "healthy"}`))
+	})
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if want := []string{"outer", "inner"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("middleware order = %v, want %v", order, want)
+	}
+}
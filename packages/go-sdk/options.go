@@ -0,0 +1,222 @@
+package doorpasses
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RequestOption configures a single API call
+type RequestOption func(*requestOptions)
+
+// requestOptions holds the settings applied by RequestOptions
+type requestOptions struct {
+	timeout   time.Duration
+	headers   map[string]string
+	operation string
+	meta      []*ResponseMeta
+	raw       []**http.Response
+	dryRun    bool
+	query     url.Values
+
+	// extraHeaders are the caller's WithHeader headers, which never
+	// override the SDK's own
+	extraHeaders http.Header
+
+	// statusCode is the status of the last response received for the call
+	statusCode int
+
+	// noRetry sends the call once whatever Config.MaxRetries says
+	noRetry bool
+
+	// logBody is the request body with sensitive fields redacted, as
+	// logged and handed to Config.OnRequest
+	logBody []byte
+
+	// observers are told about every response received for the call,
+	// including those of attempts that are retried
+	observers []func(resp *http.Response)
+}
+
+// WithTimeout overrides Config.Timeout for a single call. Like Config.Timeout
+// it bounds each attempt, so a retried call may take longer in total. A
+// context deadline still applies, and whichever of the two is sooner wins.
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *requestOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithFields asks the API for a sparse response containing only the named
+// fields, e.g. WithFields("id", "fullName", "state") on Get or List. Fields
+// that weren't requested are left at their zero value in the returned
+// structs, so callers must only read the fields they asked for. It applies
+// to GET requests only.
+func WithFields(fields ...string) RequestOption {
+	return func(o *requestOptions) {
+		if len(fields) == 0 {
+			return
+		}
+		if o.query == nil {
+			o.query = make(url.Values)
+		}
+		o.query.Set("fields", strings.Join(fields, ","))
+	}
+}
+
+// ResponseMeta describes the API response to a call, filled in by
+// WithResponseMeta
+type ResponseMeta struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int
+
+	// RequestID identifies the request for DoorPasses support. Failed calls
+	// also carry it in APIError.RequestID.
+	RequestID string
+
+	// Replayed reports that the API answered with the stored response of an
+	// earlier request with the same idempotency key
+	Replayed bool
+
+	// ETag is the entity tag of the response, if the API sent one
+	ETag string
+
+	// Header holds the response headers
+	Header http.Header
+}
+
+// idempotencyReplayedHeader marks a response replayed for an idempotency key
+const idempotencyReplayedHeader = "Idempotency-Replayed"
+
+// responseMetaMu guards writes to ResponseMeta, which bulk operations share
+// between their concurrent requests
+var responseMetaMu sync.Mutex
+
+// WithResponseMeta fills meta in with details of the response once the call
+// returns, whether or not it succeeded. When the call was retried, meta
+// describes the last response; in bulk operations, whichever response
+// arrived last.
+//
+// Example:
+//
+//	var meta doorpasses.ResponseMeta
+//	accessPass, err := client.AccessPasses.Get("pass_123", doorpasses.WithResponseMeta(&meta))
+//	log.Printf("request %s", meta.RequestID)
+func WithResponseMeta(meta *ResponseMeta) RequestOption {
+	return func(o *requestOptions) {
+		if meta != nil {
+			o.meta = append(o.meta, meta)
+		}
+	}
+}
+
+// WithRawResponse stores the final *http.Response of the call in *resp once
+// it returns, whether or not it succeeded, for reading the status and
+// headers the typed API doesn't expose, e.g. while diagnosing an issue with
+// DoorPasses support. The SDK has already read the body into the returned
+// value or error, so the stored response's Body is http.NoBody. When the
+// call was retried, *resp is the last response; in bulk operations,
+// whichever response arrived last. *resp is left as is when no response
+// was received.
+//
+// WithRawResponse is an advanced escape hatch for debugging. Prefer
+// WithResponseMeta, which covers the common needs such as the request ID.
+func WithRawResponse(resp **http.Response) RequestOption {
+	return func(o *requestOptions) {
+		if resp != nil {
+			o.raw = append(o.raw, resp)
+		}
+	}
+}
+
+// recordResponse notes the status of resp and fills in the caller's
+// ResponseMeta and WithRawResponse targets, if any, from it
+func (o *requestOptions) recordResponse(resp *http.Response) {
+	o.statusCode = resp.StatusCode
+	for _, observe := range o.observers {
+		observe(resp)
+	}
+	if len(o.meta) == 0 && len(o.raw) == 0 {
+		return
+	}
+	responseMetaMu.Lock()
+	defer responseMetaMu.Unlock()
+	if len(o.raw) > 0 {
+		// The SDK reads and closes the body itself, so hand out a copy that
+		// can't be read a second time
+		captured := *resp
+		captured.Body = http.NoBody
+		for _, raw := range o.raw {
+			*raw = &captured
+		}
+	}
+	for _, meta := range o.meta {
+		meta.StatusCode = resp.StatusCode
+		meta.RequestID = requestIDFromHeader(resp.Header)
+		meta.Replayed = resp.Header.Get(idempotencyReplayedHeader) == "true"
+		meta.ETag = resp.Header.Get("ETag")
+		meta.Header = resp.Header
+	}
+}
+
+// WithHeader sets an extra header on a single call, e.g. a debug header
+// asked for by DoorPasses support. Headers the SDK sets itself, such as the
+// auth and signature headers, Content-Type and User-Agent, can't be
+// overridden and are ignored.
+func WithHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.extraHeaders == nil {
+			o.extraHeaders = make(http.Header)
+		}
+		o.extraHeaders.Set(key, value)
+	}
+}
+
+// withoutRetries sends a single call once, for checks that must fail fast
+func withoutRetries() RequestOption {
+	return func(o *requestOptions) {
+		o.noRetry = true
+	}
+}
+
+// withResponseObserver has observe called with every response of a single
+// call, before its body is read
+func withResponseObserver(observe func(resp *http.Response)) RequestOption {
+	return func(o *requestOptions) {
+		o.observers = append(o.observers, observe)
+	}
+}
+
+// withHeader sets a header the SDK needs on a single call
+func withHeader(key, value string) RequestOption {
+	return func(o *requestOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string)
+		}
+		o.headers[key] = value
+	}
+}
+
+// newRequestOptions applies opts in order
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// httpClientFor returns the client to send a call with, honouring a
+// per-call timeout
+func (c *HTTPClient) httpClientFor(o *requestOptions) *http.Client {
+	if o.timeout <= 0 {
+		return c.client
+	}
+	clone := *c.client
+	clone.Timeout = o.timeout
+	return &clone
+}
@@ -0,0 +1,242 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name            string
+		opts            []RequestOption
+		contextTimeout  time.Duration
+		wantErr         bool
+		wantDeadlineErr bool
+	}{
+		{
+			name:    "client timeout applies by default",
+			wantErr: true,
+		},
+		{
+			name: "per-call timeout overrides client timeout",
+			opts: []RequestOption{WithTimeout(2 * time.Second)},
+		},
+		{
+			name:            "sooner context deadline wins",
+			opts:            []RequestOption{WithTimeout(2 * time.Second)},
+			contextTimeout:  20 * time.Millisecond,
+			wantErr:         true,
+			wantDeadlineErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Timeout: 20 * time.Millisecond, MaxRetries: -1}
+			client := newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-time.After(100 * time.Millisecond):
+				case <-r.Context().Done():
+					return
+				}
+				w.Write([]byte(`{"status": "healthy"}`))
+			})
+
+			ctx := context.Background()
+			if tt.contextTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.contextTimeout)
+				defer cancel()
+			}
+
+			_, err := client.HealthWithContext(ctx, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HealthWithContext() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantDeadlineErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("HealthWithContext() error = %v, want context.DeadlineExceeded", err)
+			}
+		})
+	}
+}
+
+func TestWithResponseMeta(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantErr    bool
+		wantStatus int
+	}{
+		{
+			name:       "success",
+			status:     http.StatusOK,
+			body:       `{"success": true, "data": {"id": "pass_123"}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "error",
+			status:     http.StatusNotFound,
+			body:       `{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`,
+			wantErr:    true,
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-ID", "req_123")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			var meta ResponseMeta
+			_, err := client.AccessPasses.Get("pass_123", WithResponseMeta(&meta))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if meta.RequestID != "req_123" {
+				t.Errorf("RequestID = %q, want %q", meta.RequestID, "req_123")
+			}
+			if meta.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", meta.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWithRawResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		wantErr    bool
+		wantStatus int
+	}{
+		{name: "success", statuses: []int{http.StatusOK}, wantStatus: http.StatusOK},
+		{name: "error", statuses: []int{http.StatusNotFound}, wantErr: true, wantStatus: http.StatusNotFound},
+		{name: "retried", statuses: []int{http.StatusServiceUnavailable, http.StatusOK}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt := 0
+			client := newTestClient(t, &Config{RetryBackoff: func(int) time.Duration { return 0 }}, func(w http.ResponseWriter, r *http.Request) {
+				status := tt.statuses[attempt]
+				attempt++
+				w.Header().Set("X-Debug-Shard", "shard-7")
+				w.WriteHeader(status)
+				if status == http.StatusOK {
+					w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+					return
+				}
+				w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+			})
+
+			var resp *http.Response
+			accessPass, err := client.AccessPasses.Get("pass_123", WithRawResponse(&resp))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && accessPass.ID != "pass_123" {
+				t.Errorf("ID = %q, want the body decoded as usual", accessPass.ID)
+			}
+			if resp == nil {
+				t.Fatal("WithRawResponse() stored no response")
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("StatusCode = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := resp.Header.Get("X-Debug-Shard"); got != "shard-7" {
+				t.Errorf("X-Debug-Shard = %q, want shard-7", got)
+			}
+			if body, err := io.ReadAll(resp.Body); err != nil || len(body) != 0 {
+				t.Errorf("reading Body = %q, %v, want it empty", body, err)
+			}
+		})
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	var gotFields []string
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sig_payload") == "" {
+			t.Error("sig_payload missing")
+		}
+		gotFields = append(gotFields, r.URL.Query().Get("fields"))
+		if r.URL.Path == "/v1/access-passes" {
+			w.Write([]byte(`{"success": true, "data": {"items": [{"id": "pass_123", "fullName": "John Doe", "state": "active"}]}}`))
+			return
+		}
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123", "fullName": "John Doe", "state": "active"}}`))
+	})
+
+	accessPass, err := client.AccessPasses.Get("pass_123", WithFields("id", "fullName", "state"))
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if accessPass.FullName != "John Doe" || accessPass.CardTemplateID != "" || !accessPass.Created.IsZero() {
+		t.Errorf("Get() = %+v, want only the requested fields set", accessPass)
+	}
+
+	if _, err := client.AccessPasses.List(nil, WithFields("id")); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if _, err := client.AccessPasses.Get("pass_123", WithFields()); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	want := []string{"id,fullName,state", "id", ""}
+	if len(gotFields) != len(want) {
+		t.Fatalf("server received %d requests, want %d", len(gotFields), len(want))
+	}
+	for i := range want {
+		if gotFields[i] != want[i] {
+			t.Errorf("request %d fields = %q, want %q", i, gotFields[i], want[i])
+		}
+	}
+}
+
+func TestWithHeader(t *testing.T) {
+	var got http.Header
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	_, err := client.AccessPasses.Get("pass_123",
+		WithHeader("X-Debug", "trace"),
+		WithHeader("x-feature-flag", "new-wallet"),
+		WithHeader("X-PAYLOAD-SIG", "forged"),
+		WithHeader("x-acct-id", "other_account"),
+		WithHeader("User-Agent", "custom"),
+	)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	if got.Get("X-Debug") != "trace" || got.Get("X-Feature-Flag") != "new-wallet" {
+		t.Errorf("extra headers = %v, want X-Debug and X-Feature-Flag", got)
+	}
+	if got.Get("X-ACCT-ID") != "test_account" {
+		t.Errorf("X-ACCT-ID = %q, want test_account", got.Get("X-ACCT-ID"))
+	}
+	if got.Get("X-PAYLOAD-SIG") == "forged" || len(got.Values("X-PAYLOAD-SIG")) != 1 {
+		t.Errorf("X-PAYLOAD-SIG = %v, want the SDK's signature only", got.Values("X-PAYLOAD-SIG"))
+	}
+	if got.Get("User-Agent") == "custom" {
+		t.Error("User-Agent was overridden")
+	}
+
+	// The header applies to one call only
+	if _, err := client.AccessPasses.Get("pass_123"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Get("X-Debug") != "" {
+		t.Errorf("X-Debug = %q on a later call, want none", got.Get("X-Debug"))
+	}
+}
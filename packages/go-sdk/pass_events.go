@@ -0,0 +1,181 @@
+package doorpasses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// PassEventType is the kind of change recorded in an access pass's history
+type PassEventType string
+
+const (
+	PassEventIssued      PassEventType = "issued"
+	PassEventDelivered   PassEventType = "delivered"
+	PassEventInstalled   PassEventType = "installed"
+	PassEventUpdated     PassEventType = "updated"
+	PassEventSuspended   PassEventType = "suspended"
+	PassEventResumed     PassEventType = "resumed"
+	PassEventUnlinked    PassEventType = "unlinked"
+	PassEventRevoked     PassEventType = "revoked"
+	PassEventDeleted     PassEventType = "deleted"
+	PassEventExpired     PassEventType = "expired"
+	PassEventInviteSent  PassEventType = "invite_sent"
+	PassEventUninstalled PassEventType = "uninstalled"
+)
+
+// Known reports whether t is one of the event types defined by this version
+// of the SDK
+func (t PassEventType) Known() bool {
+	switch t {
+	case PassEventIssued, PassEventDelivered, PassEventInstalled, PassEventUpdated,
+		PassEventSuspended, PassEventResumed, PassEventUnlinked, PassEventRevoked,
+		PassEventDeleted, PassEventExpired, PassEventInviteSent, PassEventUninstalled:
+		return true
+	}
+	return false
+}
+
+// PassEvent is an entry in the history of an access pass. Events of a type
+// this version of the SDK doesn't define decode like any other, keeping the
+// type the API sent; check Type.Known to tell them apart.
+type PassEvent struct {
+	ID   string        `json:"id"`
+	Type PassEventType `json:"type"`
+
+	// Timestamp is when the event happened, in UTC. It is zero when the API
+	// sent no timestamp or one that isn't recognised.
+	Timestamp time.Time `json:"-"`
+
+	// Actor identifies who or what caused the event, e.g. an API key, a
+	// console user or "system"
+	Actor string `json:"actor,omitempty"`
+
+	// Device is the device the event happened on, for wallet events
+	Device   string                 `json:"device,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Raw is the event exactly as the API sent it
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes an event, parses its timestamp and keeps the raw
+// payload in Raw
+func (e *PassEvent) UnmarshalJSON(data []byte) error {
+	type passEvent PassEvent
+	aux := struct {
+		*passEvent
+		Timestamp string `json:"timestamp"`
+	}{passEvent: (*passEvent)(e)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	e.Timestamp = parseTimestamp(aux.Timestamp)
+	e.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// ListPassEventsParams represents parameters for listing the events of an
+// access pass
+type ListPassEventsParams struct {
+	// Limit is the maximum number of events per page
+	Limit int `json:"limit,omitempty"`
+
+	// Cursor is the opaque NextCursor from a previous page
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// PassEventPage represents a single page of access pass events
+type PassEventPage struct {
+	Items []PassEvent `json:"items"`
+
+	// NextCursor is an opaque cursor for the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// HasMore reports whether there are more pages after this one
+	HasMore bool `json:"hasMore"`
+}
+
+// UnmarshalJSON accepts either a page object or a bare array of events
+func (p *PassEventPage) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*p = PassEventPage{}
+		return json.Unmarshal(trimmed, &p.Items)
+	}
+
+	type page PassEventPage
+	return json.Unmarshal(data, (*page)(p))
+}
+
+// ListEvents returns the full history of an access pass, oldest first. It
+// fetches as many pages as needed.
+func (a *AccessPasses) ListEvents(accessPassID string, opts ...RequestOption) ([]PassEvent, error) {
+	return a.ListEventsWithContext(context.Background(), accessPassID, opts...)
+}
+
+// ListEventsWithContext returns the full history of an access pass,
+// aborting if ctx is done
+func (a *AccessPasses) ListEventsWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) ([]PassEvent, error) {
+	opts = withOperation(opts, "AccessPasses.ListEvents")
+
+	var events []PassEvent
+	params := &ListPassEventsParams{}
+	for {
+		page, err := a.ListEventsPageWithContext(ctx, accessPassID, params, opts...)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page.Items...)
+		if !page.HasMore || page.NextCursor == "" {
+			break
+		}
+		params.Cursor = page.NextCursor
+	}
+
+	// Events without a parseable timestamp go last
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, tj := events[i].Timestamp, events[j].Timestamp
+		if ti.IsZero() || tj.IsZero() {
+			return !ti.IsZero() && tj.IsZero()
+		}
+		return ti.Before(tj)
+	})
+	return events, nil
+}
+
+// ListEventsPage retrieves a single page of an access pass's history. Pass
+// the returned NextCursor back in params.Cursor to fetch the following page.
+func (a *AccessPasses) ListEventsPage(accessPassID string, params *ListPassEventsParams, opts ...RequestOption) (*PassEventPage, error) {
+	return a.ListEventsPageWithContext(context.Background(), accessPassID, params, opts...)
+}
+
+// ListEventsPageWithContext retrieves a single page of an access pass's
+// history, aborting if ctx is done
+func (a *AccessPasses) ListEventsPageWithContext(ctx context.Context, accessPassID string, params *ListPassEventsParams, opts ...RequestOption) (*PassEventPage, error) {
+	opts = withOperation(opts, "AccessPasses.ListEventsPage")
+	if accessPassID == "" {
+		return nil, fmt.Errorf("accessPassId is required")
+	}
+
+	sigPayload := map[string]interface{}{
+		"id": accessPassID,
+	}
+	if params != nil {
+		if params.Limit > 0 {
+			sigPayload["limit"] = params.Limit
+		}
+		if params.Cursor != "" {
+			sigPayload["cursor"] = params.Cursor
+		}
+	}
+
+	var result PassEventPage
+	err := a.http.GetWithContext(ctx, fmt.Sprintf("/v1/access-passes/%s/events", accessPassID), sigPayload, &result, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
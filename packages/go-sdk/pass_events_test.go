@@ -0,0 +1,109 @@
+package doorpasses
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestPassEventUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantType  PassEventType
+		wantKnown bool
+		wantTime  time.Time
+	}{
+		{
+			name:      "known type",
+			data:      `{"id": "evt_1", "type": "revoked", "timestamp": "2025-03-01T12:00:00+02:00", "actor": "api_key"}`,
+			wantType:  PassEventRevoked,
+			wantKnown: true,
+			wantTime:  time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "unknown type",
+			data:     `{"id": "evt_2", "type": "badge_printed", "timestamp": "2025-03-01T10:00:00Z"}`,
+			wantType: "badge_printed",
+			wantTime: time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "missing timestamp",
+			data:      `{"id": "evt_3", "type": "issued"}`,
+			wantType:  PassEventIssued,
+			wantKnown: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var event PassEvent
+			if err := json.Unmarshal([]byte(tt.data), &event); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if event.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", event.Type, tt.wantType)
+			}
+			if event.Type.Known() != tt.wantKnown {
+				t.Errorf("Type.Known() = %v, want %v", event.Type.Known(), tt.wantKnown)
+			}
+			if !event.Timestamp.Equal(tt.wantTime) {
+				t.Errorf("Timestamp = %v, want %v", event.Timestamp, tt.wantTime)
+			}
+			if string(event.Raw) != tt.data {
+				t.Errorf("Raw = %s, want %s", event.Raw, tt.data)
+			}
+		})
+	}
+}
+
+func TestAccessPassesListEvents(t *testing.T) {
+	pages := map[string]string{
+		"": `{"items": [
+			{"id": "evt_2", "type": "installed", "timestamp": "2025-01-02T00:00:00Z", "device": "mobile"},
+			{"id": "evt_1", "type": "issued", "timestamp": "2025-01-01T00:00:00Z", "actor": "api_key"}
+		], "nextCursor": "page_2", "hasMore": true}`,
+		"page_2": `{"items": [
+			{"id": "evt_4", "type": "badge_printed"},
+			{"id": "evt_3", "type": "revoked", "timestamp": "2025-02-01T00:00:00Z", "actor": "console_user"}
+		]}`,
+	}
+
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/access-passes/pass_123/events" {
+			t.Errorf("path = %s, want /v1/access-passes/pass_123/events", r.URL.Path)
+		}
+		payload, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+		var sig map[string]interface{}
+		json.Unmarshal(payload, &sig)
+		if sig["id"] != "pass_123" {
+			t.Errorf("id = %v, want pass_123", sig["id"])
+		}
+		cursor, _ := sig["cursor"].(string)
+		w.Write([]byte(`{"success": true, "data": ` + pages[cursor] + `}`))
+	})
+
+	events, err := client.AccessPasses.ListEvents("pass_123")
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+
+	want := []string{"evt_1", "evt_2", "evt_3", "evt_4"}
+	if len(events) != len(want) {
+		t.Fatalf("ListEvents() returned %d events, want %d", len(events), len(want))
+	}
+	for i, id := range want {
+		if events[i].ID != id {
+			t.Errorf("events[%d].ID = %q, want %q", i, events[i].ID, id)
+		}
+	}
+	if events[2].Actor != "console_user" {
+		t.Errorf("events[2].Actor = %q, want console_user", events[2].Actor)
+	}
+
+	if _, err := client.AccessPasses.ListEvents(""); err == nil {
+		t.Error("ListEvents(\"\") returned no error")
+	}
+}
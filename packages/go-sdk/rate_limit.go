@@ -0,0 +1,87 @@
+package doorpasses
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit describes the rate limit reported by the API on its most recent
+// response
+type RateLimit struct {
+	// Limit is the number of requests allowed in the current window
+	Limit int
+
+	// Remaining is the number of requests left in the current window
+	Remaining int
+
+	// Reset is when the current window ends
+	Reset time.Time
+}
+
+// unixTimestampThreshold separates reset values given as seconds from now
+// from values given as a Unix timestamp
+const unixTimestampThreshold = 1_000_000_000
+
+// parseRateLimit reads the X-RateLimit-* headers, or their standard
+// RateLimit-* equivalents. It reports false when the response carries none.
+func parseRateLimit(header http.Header, now time.Time) (RateLimit, bool) {
+	limit := rateLimitHeader(header, "Limit")
+	remaining := rateLimitHeader(header, "Remaining")
+	reset := rateLimitHeader(header, "Reset")
+	if limit == "" && remaining == "" && reset == "" {
+		return RateLimit{}, false
+	}
+
+	var rl RateLimit
+	rl.Limit, _ = strconv.Atoi(limit)
+	rl.Remaining, _ = strconv.Atoi(remaining)
+	rl.Reset = parseRateLimitReset(reset, now)
+	return rl, true
+}
+
+// rateLimitHeader returns the X-RateLimit-<name> header, falling back to
+// RateLimit-<name>
+func rateLimitHeader(header http.Header, name string) string {
+	if value := header.Get("X-RateLimit-" + name); value != "" {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(header.Get("RateLimit-" + name))
+}
+
+// parseRateLimitReset accepts a number of seconds from now, a Unix timestamp
+// or an RFC3339 date. It returns the zero time when value is malformed.
+func parseRateLimitReset(value string, now time.Time) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds >= unixTimestampThreshold {
+			return time.Unix(seconds, 0)
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if reset, err := time.Parse(time.RFC3339, value); err == nil {
+		return reset
+	}
+	return time.Time{}
+}
+
+// recordRateLimit stores the rate limit reported by a response
+func (c *HTTPClient) recordRateLimit(header http.Header) {
+	rl, ok := parseRateLimit(header, time.Now())
+	if !ok {
+		return
+	}
+	c.rateLimitMu.Lock()
+	c.rateLimit = rl
+	c.rateLimitMu.Unlock()
+}
+
+// lastRateLimit returns the most recently recorded rate limit
+func (c *HTTPClient) lastRateLimit() RateLimit {
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+	return c.rateLimit
+}
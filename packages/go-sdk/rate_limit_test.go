@@ -0,0 +1,93 @@
+package doorpasses
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	now := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   RateLimit
+		wantOK bool
+	}{
+		{
+			name:   "no headers",
+			header: http.Header{},
+		},
+		{
+			name: "x-ratelimit headers with unix reset",
+			header: http.Header{
+				"X-Ratelimit-Limit":     {"100"},
+				"X-Ratelimit-Remaining": {"42"},
+				"X-Ratelimit-Reset":     {"1761955260"},
+			},
+			want:   RateLimit{Limit: 100, Remaining: 42, Reset: time.Unix(1761955260, 0)},
+			wantOK: true,
+		},
+		{
+			name: "standard headers with reset in seconds",
+			header: http.Header{
+				"Ratelimit-Limit":     {"100"},
+				"Ratelimit-Remaining": {"0"},
+				"Ratelimit-Reset":     {"30"},
+			},
+			want:   RateLimit{Limit: 100, Remaining: 0, Reset: now.Add(30 * time.Second)},
+			wantOK: true,
+		},
+		{
+			name: "rfc3339 reset",
+			header: http.Header{
+				"X-Ratelimit-Limit": {"100"},
+				"X-Ratelimit-Reset": {"2025-11-01T00:01:00Z"},
+			},
+			want:   RateLimit{Limit: 100, Reset: now.Add(time.Minute)},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRateLimit(tt.header, now)
+			if ok != tt.wantOK {
+				t.Fatalf("parseRateLimit() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if got.Limit != tt.want.Limit || got.Remaining != tt.want.Remaining || !got.Reset.Equal(tt.want.Reset) {
+				t.Errorf("parseRateLimit() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientLastRateLimit(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "99")
+		w.Write([]byte(`{"status": "healthy"}`))
+	})
+
+	if got := client.LastRateLimit(); got != (RateLimit{}) {
+		t.Errorf("LastRateLimit() before any request = %+v, want zero value", got)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Health()
+			client.LastRateLimit()
+		}()
+	}
+	wg.Wait()
+
+	got := client.LastRateLimit()
+	if got.Limit != 100 || got.Remaining != 99 {
+		t.Errorf("LastRateLimit() = %+v, want limit 100 and remaining 99", got)
+	}
+}
@@ -0,0 +1,51 @@
+package doorpasses
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// maxRedirects is the number of redirects followed before giving up, as for
+// http.Client
+const maxRedirects = 10
+
+// redirectPolicy returns the CheckRedirect of the SDK's HTTP client. Every
+// redirect followed carries the original request's auth and signing
+// headers again, since net/http drops some of them on the way. Unless
+// follow is set, a redirect to another host or scheme, or one that changes
+// the method and so loses the signed body, fails with ErrUnexpectedRedirect.
+func redirectPolicy(follow bool) func(req *http.Request, via []*http.Request) error {
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("%w: stopped after %d redirects", ErrUnexpectedRedirect, maxRedirects)
+		}
+
+		first := via[0]
+		if !follow {
+			if req.URL.Host != first.URL.Host || req.URL.Scheme != first.URL.Scheme {
+				return fmt.Errorf("%w: %s redirected to %s", ErrUnexpectedRedirect, redactURL(first.URL), redactURL(req.URL))
+			}
+			if req.Method != first.Method {
+				return fmt.Errorf("%w: %s %s redirected as %s", ErrUnexpectedRedirect, first.Method, redactURL(first.URL), req.Method)
+			}
+		}
+
+		for key, values := range first.Header {
+			if _, ok := req.Header[key]; !ok {
+				req.Header[key] = values
+			}
+		}
+		return nil
+	}
+}
+
+// withRedirectPolicy returns a copy of client using redirectPolicy, or
+// client itself when it already has a CheckRedirect of its own
+func withRedirectPolicy(client *http.Client, follow bool) *http.Client {
+	if client.CheckRedirect != nil {
+		return client
+	}
+	clone := *client
+	clone.CheckRedirect = redirectPolicy(follow)
+	return &clone
+}
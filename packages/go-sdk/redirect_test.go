@@ -0,0 +1,75 @@
+package doorpasses
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRedirects(t *testing.T) {
+	tests := []struct {
+		name            string
+		follow          bool
+		status          int
+		crossHost       bool
+		wantErr         bool
+		wantOtherServer bool
+	}{
+		{name: "same host is followed", status: http.StatusTemporaryRedirect},
+		{name: "other host is refused", status: http.StatusTemporaryRedirect, crossHost: true, wantErr: true},
+		{name: "other host is followed when allowed", follow: true, status: http.StatusTemporaryRedirect, crossHost: true, wantOtherServer: true},
+		{name: "method change is refused", status: http.StatusFound, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkSigned := func(r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Header.Get("X-PAYLOAD-SIG") == "" || len(body) == 0 {
+					t.Errorf("redirected request lost its signature or body: headers %v, body %q", r.Header, body)
+				}
+			}
+
+			var otherRequests atomic.Int32
+			other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				otherRequests.Add(1)
+				checkSigned(r)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			}))
+			defer other.Close()
+
+			var requests atomic.Int32
+			client := newTestClient(t, &Config{FollowRedirects: tt.follow}, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if r.URL.Path == "/moved" {
+					checkSigned(r)
+					w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+					return
+				}
+				target := "/moved"
+				if tt.crossHost {
+					target = other.URL + "/moved"
+				}
+				http.Redirect(w, r, target, tt.status)
+			})
+
+			_, err := client.AccessPasses.Issue(validIssueParams())
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnexpectedRedirect) {
+					t.Errorf("Issue() error = %v, want ErrUnexpectedRedirect", err)
+				}
+				if got := requests.Load(); got != 1 {
+					t.Errorf("requests = %d, want 1 without retries", got)
+				}
+			} else if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			if got := otherRequests.Load() > 0; got != tt.wantOtherServer {
+				t.Errorf("reached other host = %v, want %v", got, tt.wantOtherServer)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRetries is the number of retries used when Config.MaxRetries is unset
+const DefaultMaxRetries = 3
+
+const (
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	defaultRetryMaxDelay  = 10 * time.Second
+)
+
+// defaultRetryBackoff doubles the delay on every attempt, starting at 200ms
+// and capped at 10s, with random jitter
+var defaultRetryBackoff = exponentialBackoff(defaultRetryBaseDelay, defaultRetryMaxDelay, true)
+
+// exponentialBackoff returns a backoff that starts at initial and doubles on
+// every attempt, never exceeding maxDelay. With jitter, up to half of the
+// delay is taken off at random so that clients retrying together spread out,
+// even once they reach maxDelay.
+func exponentialBackoff(initial, maxDelay time.Duration, jitter bool) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		delay := initial
+		for i := 1; i < attempt && delay < maxDelay; i++ {
+			delay *= 2
+		}
+		delay = min(delay, maxDelay)
+		if jitter {
+			delay -= time.Duration(rand.Int63n(int64(delay)/2 + 1))
+		}
+		return delay
+	}
+}
+
+// configureRetries applies the retry settings from config
+func (c *HTTPClient) configureRetries(config *Config) {
+	if config.MaxRetries < 0 {
+		c.maxRetries = 0
+	} else if config.MaxRetries > 0 {
+		c.maxRetries = config.MaxRetries
+	}
+
+	initial := defaultRetryBaseDelay
+	if config.RetryInitialBackoff > 0 {
+		initial = config.RetryInitialBackoff
+	}
+	maxDelay := defaultRetryMaxDelay
+	if config.RetryMaxBackoff > 0 {
+		maxDelay = config.RetryMaxBackoff
+	}
+	c.retryBackoff = exponentialBackoff(initial, maxDelay, !config.DisableRetryJitter)
+
+	c.retryBudget = newRetryBudget(config.RetryBudget)
+
+	if len(config.RetryableStatusCodes) > 0 {
+		c.retryableStatusCodes = make(map[int]bool, len(config.RetryableStatusCodes))
+		for _, statusCode := range config.RetryableStatusCodes {
+			c.retryableStatusCodes[statusCode] = true
+		}
+	}
+
+	if config.RetryBackoff != nil {
+		c.retryBackoff = config.RetryBackoff
+		if config.RetryMaxBackoff > 0 {
+			backoff := config.RetryBackoff
+			c.retryBackoff = func(attempt int) time.Duration {
+				return min(backoff(attempt), config.RetryMaxBackoff)
+			}
+		}
+	}
+}
+
+// RetryBudget limits the rate of retries across every call made with a
+// client, on top of each call's MaxRetries. Retries spend tokens from a
+// bucket that refills at Rate per second and holds at most Burst tokens.
+type RetryBudget struct {
+	// Rate is the number of retries per second the client may make in
+	// aggregate
+	Rate float64
+
+	// Burst is the number of retries that may be made at once after a quiet
+	// period. Defaults to Rate, and at least 1.
+	Burst int
+}
+
+// retryBudget is the token bucket behind RetryBudget. A nil *retryBudget
+// allows every retry.
+type retryBudget struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newRetryBudget returns the token bucket for budget, or nil when budget is
+// nil
+func newRetryBudget(budget *RetryBudget) *retryBudget {
+	if budget == nil {
+		return nil
+	}
+	burst := float64(budget.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(budget.Rate)
+	}
+	burst = math.Max(burst, 1)
+	return &retryBudget{
+		rate:   math.Max(budget.Rate, 0),
+		burst:  burst,
+		now:    time.Now,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// take spends a token for a retry, reporting false when none is left
+func (b *retryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// deadlineError returns ctxErr, joined with the error of the last attempt
+// when ctx ran out of time while retrying. Cancellation is returned as-is.
+func deadlineError(ctxErr, lastErr error) error {
+	if lastErr == nil || !errors.Is(ctxErr, context.DeadlineExceeded) {
+		return ctxErr
+	}
+	return errors.Join(ctxErr, lastErr)
+}
+
+// exceedsDeadline reports whether waiting for delay would run past ctx's
+// deadline
+func exceedsDeadline(ctx context.Context, delay time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) < delay
+}
+
+// isIdempotent reports whether a request can be safely sent more than once.
+// GET requests always qualify; other methods only when they carry an
+// idempotency key or are dry runs, which change nothing.
+func isIdempotent(method string, headers map[string]string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	return headers[idempotencyKeyHeader] != "" || headers[dryRunHeader] == "true"
+}
+
+// isRetryableStatus reports whether a response status indicates a transient
+// failure: a server-side error, a rate limit or one of
+// Config.RetryableStatusCodes
+func (c *HTTPClient) isRetryableStatus(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusTooManyRequests || c.retryableStatusCodes[statusCode]
+}
+
+// parseRetryAfter parses a Retry-After header given either as a number of
+// seconds or as an HTTP date. It returns zero when the header is absent or
+// malformed.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if d := date.Sub(now); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
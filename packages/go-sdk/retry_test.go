@@ -0,0 +1,352 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{
+			name:  "empty",
+			value: "",
+			want:  0,
+		},
+		{
+			name:  "seconds",
+			value: "120",
+			want:  2 * time.Minute,
+		},
+		{
+			name:  "negative seconds",
+			value: "-5",
+			want:  0,
+		},
+		{
+			name:  "http date",
+			value: now.Add(30 * time.Second).Format(http.TimeFormat),
+			want:  30 * time.Second,
+		},
+		{
+			name:  "http date in the past",
+			value: now.Add(-time.Minute).Format(http.TimeFormat),
+			want:  0,
+		},
+		{
+			name:  "malformed",
+			value: "soon",
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": "Too many requests"}`))
+	})
+
+	_, err := client.Health()
+
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Health() error = %v, want *RateLimitError", err)
+	}
+	if rateLimitErr.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want %v", rateLimitErr.RetryAfter, 7*time.Second)
+	}
+}
+
+func TestRateLimitErrorZeroValue(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *RateLimitError
+		want string
+	}{
+		{name: "zero value", err: &RateLimitError{}, want: "rate limited"},
+		{name: "retry after only", err: &RateLimitError{RetryAfter: 3 * time.Second}, want: "rate limited (retry after 3s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+			var apiErr *APIError
+			if errors.As(tt.err, &apiErr) {
+				t.Errorf("errors.As() found %#v, want no APIError", apiErr)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		attempt int
+		jitter  bool
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "first attempt", attempt: 1, wantMin: 100 * time.Millisecond, wantMax: 100 * time.Millisecond},
+		{name: "doubles", attempt: 3, wantMin: 400 * time.Millisecond, wantMax: 400 * time.Millisecond},
+		{name: "capped", attempt: 50, wantMin: time.Second, wantMax: time.Second},
+		{name: "jitter", attempt: 2, jitter: true, wantMin: 100 * time.Millisecond, wantMax: 200 * time.Millisecond},
+		{name: "jitter when capped", attempt: 50, jitter: true, wantMin: 500 * time.Millisecond, wantMax: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backoff := exponentialBackoff(100*time.Millisecond, time.Second, tt.jitter)
+			for i := 0; i < 20; i++ {
+				if got := backoff(tt.attempt); got < tt.wantMin || got > tt.wantMax {
+					t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.attempt, got, tt.wantMin, tt.wantMax)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigureRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		attempt int
+		want    time.Duration
+	}{
+		{
+			name:    "initial backoff",
+			config:  &Config{RetryInitialBackoff: 50 * time.Millisecond, DisableRetryJitter: true},
+			attempt: 2,
+			want:    100 * time.Millisecond,
+		},
+		{
+			name:    "max backoff",
+			config:  &Config{RetryMaxBackoff: time.Second, DisableRetryJitter: true},
+			attempt: 20,
+			want:    time.Second,
+		},
+		{
+			name: "max backoff caps custom backoff",
+			config: &Config{
+				RetryMaxBackoff: time.Second,
+				RetryBackoff:    func(int) time.Duration { return time.Minute },
+			},
+			attempt: 1,
+			want:    time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewHTTPClient("test_account", "test_secret", "http://localhost", time.Second)
+			c.configureRetries(tt.config)
+			if got := c.retryBackoff(tt.attempt); got != tt.want {
+				t.Errorf("retryBackoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryableStatusCodes(t *testing.T) {
+	tests := []struct {
+		name         string
+		codes        []int
+		statusCode   int
+		method       string
+		wantRequests int
+	}{
+		{name: "4xx not retried by default", statusCode: http.StatusRequestTimeout, method: http.MethodGet, wantRequests: 1},
+		{name: "opted in 4xx retried", codes: []int{408, 425}, statusCode: http.StatusRequestTimeout, method: http.MethodGet, wantRequests: 3},
+		{name: "other 4xx still terminal", codes: []int{408, 425}, statusCode: http.StatusConflict, method: http.MethodGet, wantRequests: 1},
+		{name: "defaults kept", codes: []int{408}, statusCode: http.StatusServiceUnavailable, method: http.MethodGet, wantRequests: 3},
+		{name: "unsafe request not retried", codes: []int{408}, statusCode: http.StatusRequestTimeout, method: http.MethodPost, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			config := &Config{
+				MaxRetries:           2,
+				RetryableStatusCodes: tt.codes,
+				RetryBackoff:         func(int) time.Duration { return 0 },
+			}
+			client := newTestClient(t, config, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{"success": false, "error": {"code": "TRANSIENT", "message": "try again"}}`))
+			})
+
+			err := client.Do(context.Background(), tt.method, "/v1/things", nil, nil)
+			if !hasStatus(err, tt.statusCode) {
+				t.Errorf("Do() error = %v, want status %d", err, tt.statusCode)
+			}
+			if got := int(requests.Load()); got != tt.wantRequests {
+				t.Errorf("server received %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRetryStopsBeforeDeadline(t *testing.T) {
+	requests := 0
+	client := newTestClient(t, &Config{RetryBackoff: func(int) time.Duration { return time.Minute }}, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"success": false, "error": {"code": "UNAVAILABLE", "message": "try again"}}`))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.HealthWithContext(ctx)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("HealthWithContext() error = %v, want the 503", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HealthWithContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if requests != 1 {
+		t.Errorf("server received %d requests, want 1", requests)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("HealthWithContext() took %v, want no wait", elapsed)
+	}
+}
+
+func TestRetrySkipsAttemptThatCannotFinish(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, &Config{RetryBackoff: func(int) time.Duration { return 0 }}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(300 * time.Millisecond)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// The first attempt takes 300ms, leaving too little time for another
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	_, err := client.HealthWithContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("HealthWithContext() error = %v, want context.DeadlineExceeded", err)
+	}
+	if !hasStatus(err, http.StatusServiceUnavailable) {
+		t.Errorf("HealthWithContext() error = %v, want the 503", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server received %d requests, want 1", got)
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	type step struct {
+		advance time.Duration
+		want    bool
+	}
+
+	tests := []struct {
+		name   string
+		budget RetryBudget
+		steps  []step
+	}{
+		{
+			name:   "burst then refill",
+			budget: RetryBudget{Rate: 1, Burst: 2},
+			steps: []step{
+				{0, true},
+				{0, true},
+				{0, false},
+				{500 * time.Millisecond, false},
+				{500 * time.Millisecond, true},
+				{0, false},
+			},
+		},
+		{
+			name:   "refill is capped at burst",
+			budget: RetryBudget{Rate: 10, Burst: 1},
+			steps: []step{
+				{time.Hour, true},
+				{0, false},
+			},
+		},
+		{
+			name:   "burst defaults to at least one",
+			budget: RetryBudget{Rate: 0.1},
+			steps: []step{
+				{0, true},
+				{0, false},
+				{10 * time.Second, true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(0, 0)
+			b := newRetryBudget(&tt.budget)
+			b.now = func() time.Time { return now }
+			b.last = now
+
+			for i, s := range tt.steps {
+				now = now.Add(s.advance)
+				if got := b.take(); got != s.want {
+					t.Errorf("step %d: take() = %v, want %v", i, got, s.want)
+				}
+			}
+		})
+	}
+
+	var unlimited *retryBudget
+	if !unlimited.take() {
+		t.Error("nil retry budget refused a retry")
+	}
+}
+
+func TestRetryBudgetSharedAcrossCalls(t *testing.T) {
+	var requests atomic.Int32
+	client := newTestClient(t, &Config{
+		MaxRetries:   3,
+		RetryBackoff: func(int) time.Duration { return 0 },
+		RetryBudget:  &RetryBudget{Rate: 0.001, Burst: 2},
+	}, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	// The first call spends the whole budget on its first two retries
+	if _, err := client.Health(); !hasStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("Health() error = %v, want 503", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("first call sent %d requests, want 3", got)
+	}
+
+	// The second call gets no retries at all
+	if _, err := client.Health(); !hasStatus(err, http.StatusServiceUnavailable) {
+		t.Fatalf("Health() error = %v, want 503", err)
+	}
+	if got := requests.Load(); got != 4 {
+		t.Errorf("second call sent %d requests, want 1", got-3)
+	}
+}
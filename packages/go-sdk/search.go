@@ -0,0 +1,69 @@
+package doorpasses
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SearchParams represents parameters for searching access passes
+type SearchParams struct {
+	// TemplateID and State narrow the search like the List filters of the
+	// same name
+	TemplateID string          `json:"template_id,omitempty"`
+	State      AccessPassState `json:"state,omitempty"`
+
+	// Limit is the maximum number of access passes per page
+	Limit int `json:"limit,omitempty"`
+
+	// Cursor is the opaque NextCursor from a previous page
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// sigPayload builds the signed query payload for a search request
+func (p SearchParams) sigPayload(query string) map[string]interface{} {
+	sigPayload := map[string]interface{}{"q": query}
+	if p.TemplateID != "" {
+		sigPayload["template_id"] = p.TemplateID
+	}
+	if p.State != "" {
+		sigPayload["state"] = p.State
+	}
+	if p.Limit > 0 {
+		sigPayload["limit"] = p.Limit
+	}
+	if p.Cursor != "" {
+		sigPayload["cursor"] = p.Cursor
+	}
+	return sigPayload
+}
+
+// Search returns a page of access passes whose holder name or email matches
+// query, best match first. The search runs on the API; pass the returned
+// NextCursor back in params.Cursor to fetch the following page. If the API
+// doesn't offer search, ErrSearchNotSupported is returned rather than
+// scanning every pass on the client.
+func (a *AccessPasses) Search(query string, params SearchParams, opts ...RequestOption) (*AccessPassPage, error) {
+	return a.SearchWithContext(context.Background(), query, params, opts...)
+}
+
+// SearchWithContext returns a page of access passes matching query,
+// aborting if ctx is done
+func (a *AccessPasses) SearchWithContext(ctx context.Context, query string, params SearchParams, opts ...RequestOption) (*AccessPassPage, error) {
+	opts = withOperation(opts, "AccessPasses.Search")
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("query is required")
+	}
+
+	var result AccessPassPage
+	err := a.http.GetWithContext(ctx, "/v1/access-passes/search", params.sigPayload(query), &result, opts...)
+	if err != nil {
+		if IsNotFound(err) || hasStatus(err, http.StatusNotImplemented) {
+			return nil, fmt.Errorf("%w: %w", ErrSearchNotSupported, err)
+		}
+		return nil, err
+	}
+	return &result, nil
+}
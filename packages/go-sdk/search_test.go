@@ -0,0 +1,95 @@
+package doorpasses
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAccessPassesSearch(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantIDs     []string
+		wantCursor  string
+		wantErr     error
+		wantPayload map[string]interface{}
+	}{
+		{
+			name:        "ranked page",
+			status:      http.StatusOK,
+			body:        `{"success": true, "data": {"items": [{"id": "pass_2", "fullName": "Jo Smith"}, {"id": "pass_1", "fullName": "Smithers"}], "nextCursor": "cursor_2", "hasMore": true}}`,
+			wantIDs:     []string{"pass_2", "pass_1"},
+			wantCursor:  "cursor_2",
+			wantPayload: map[string]interface{}{"q": "Smith", "state": "ACTIVE", "limit": float64(2)},
+		},
+		{
+			name:    "not supported",
+			status:  http.StatusNotFound,
+			body:    `{"success": false, "error": {"code": "NOT_FOUND", "message": "Route not found"}}`,
+			wantErr: ErrSearchNotSupported,
+		},
+		{
+			name:    "other errors pass through",
+			status:  http.StatusUnauthorized,
+			body:    `{"success": false, "error": {"code": "UNAUTHORIZED", "message": "Invalid signature"}}`,
+			wantErr: &APIError{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/v1/access-passes/search" {
+					t.Errorf("request = %s %s, want GET /v1/access-passes/search", r.Method, r.URL.Path)
+				}
+				if tt.wantPayload != nil {
+					raw, _ := base64.StdEncoding.DecodeString(r.URL.Query().Get("sig_payload"))
+					var payload map[string]interface{}
+					json.Unmarshal(raw, &payload)
+					if !reflect.DeepEqual(payload, tt.wantPayload) {
+						t.Errorf("sig_payload = %v, want %v", payload, tt.wantPayload)
+					}
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			page, err := client.AccessPasses.Search(" Smith ", SearchParams{State: AccessPassStateActive, Limit: 2})
+			if tt.wantErr != nil {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) {
+					t.Fatalf("Search() error = %v, want an APIError", err)
+				}
+				if errors.Is(err, ErrSearchNotSupported) != (tt.wantErr == ErrSearchNotSupported) {
+					t.Errorf("Search() error = %v, want ErrSearchNotSupported %v", err, tt.wantErr == ErrSearchNotSupported)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var ids []string
+			for _, p := range page.Items {
+				ids = append(ids, p.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if page.NextCursor != tt.wantCursor || !page.HasMore {
+				t.Errorf("NextCursor = %q, HasMore = %v, want %q and more", page.NextCursor, page.HasMore, tt.wantCursor)
+			}
+		})
+	}
+
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("unexpected request for an empty query")
+	})
+	if _, err := client.AccessPasses.Search("  ", SearchParams{}); err == nil {
+		t.Error("Search() with an empty query succeeded, want an error")
+	}
+}
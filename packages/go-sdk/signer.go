@@ -0,0 +1,110 @@
+package doorpasses
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Signer authenticates API requests. Set Config.Signer to replace the
+// default SHA256Signer, e.g. for a gateway that expects a different
+// algorithm or header scheme. A Signer must be safe for concurrent use.
+type Signer interface {
+	// Sign returns the headers that authenticate a request. encodedPayload
+	// is the base64-encoded JSON the request is signed over: its body, the
+	// sig_payload query parameter of a GET request, or {"id":"0"} when
+	// there's neither.
+	Sign(encodedPayload string) (map[string]string, error)
+}
+
+// QuerySigner is a Signer that also signs the query parameters of GET
+// requests, e.g. for a caching proxy that validates the whole URL. Signers
+// that don't implement it only sign the encoded payload.
+type QuerySigner interface {
+	Signer
+
+	// SignWithQuery returns the headers that authenticate a request whose
+	// URL carries query parameters besides sig_payload, such as fields.
+	// canonicalQuery holds those parameters as built by CanonicalQuery; it
+	// is empty when there are none, and the signature should then match
+	// Sign's.
+	SignWithQuery(encodedPayload, canonicalQuery string) (map[string]string, error)
+}
+
+// SHA256Signer is the default Signer and the scheme the DoorPasses API
+// accepts. It sends AccountID as X-ACCT-ID, and as X-PAYLOAD-SIG the hex
+// SHA-256 digest of SharedSecret followed by the encoded payload.
+type SHA256Signer struct {
+	AccountID    string
+	SharedSecret string
+
+	// SignQuery makes SignWithQuery append a newline and the canonical
+	// query to the encoded payload before hashing. Requests without query
+	// parameters other than sig_payload are signed exactly as by Sign.
+	SignQuery bool
+}
+
+// Sign returns the X-ACCT-ID and X-PAYLOAD-SIG headers for encodedPayload
+func (s SHA256Signer) Sign(encodedPayload string) (map[string]string, error) {
+	return map[string]string{
+		"X-ACCT-ID":     s.AccountID,
+		"X-PAYLOAD-SIG": createSignature(s.SharedSecret, encodedPayload),
+	}, nil
+}
+
+// SignWithQuery returns the X-ACCT-ID and X-PAYLOAD-SIG headers for
+// encodedPayload, covering canonicalQuery too when SignQuery is set
+func (s SHA256Signer) SignWithQuery(encodedPayload, canonicalQuery string) (map[string]string, error) {
+	if !s.SignQuery || canonicalQuery == "" {
+		return s.Sign(encodedPayload)
+	}
+	return s.Sign(encodedPayload + "\n" + canonicalQuery)
+}
+
+// CanonicalQuery returns the query parameters of query other than
+// sig_payload in the form QuerySigner signs them: sorted by key, then by
+// value, percent-encoded as in RFC 3986 and joined with '&'
+func CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		if key != "sig_payload" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, escapeRFC3986(key)+"="+escapeRFC3986(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapeRFC3986 percent-encodes s, escaping spaces as %20 rather than '+'
+func escapeRFC3986(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+// signQueryJSON signs an already marshalled GET payload like signJSON, also
+// signing query when signer is a QuerySigner
+func signQueryJSON(signer Signer, jsonBytes []byte, query url.Values) (map[string]string, string, error) {
+	if querySigner, ok := signer.(QuerySigner); ok {
+		signer = boundQuerySigner{QuerySigner: querySigner, canonicalQuery: CanonicalQuery(query)}
+	}
+	return signJSON(signer, jsonBytes)
+}
+
+// boundQuerySigner signs with a QuerySigner and a fixed canonical query
+type boundQuerySigner struct {
+	QuerySigner
+	canonicalQuery string
+}
+
+// Sign signs encodedPayload together with the bound canonical query
+func (s boundQuerySigner) Sign(encodedPayload string) (map[string]string, error) {
+	return s.SignWithQuery(encodedPayload, s.canonicalQuery)
+}
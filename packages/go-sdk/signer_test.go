@@ -0,0 +1,175 @@
+package doorpasses
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestSHA256SignerKnownVector(t *testing.T) {
+	tests := []struct {
+		name           string
+		encodedPayload string
+		want           string
+	}{
+		{
+			name:           "default payload",
+			encodedPayload: "eyJpZCI6IjAifQ==",
+			want:           "4b773ad252c6891113571613157c69705a8a5808516a2744fb89c3176e4e2c80",
+		},
+		{
+			name:           "issue payload",
+			encodedPayload: "eyJjYXJkVGVtcGxhdGVJZCI6InRlbXBsYXRlXzEyMyIsImZ1bGxOYW1lIjoiSm9obiBEb2UifQ==",
+			want:           "e7f55ad409a97d87f954e20d80ec1f95d69161b9fb90769a8e8adba113e3db60",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := SHA256Signer{AccountID: "test_account", SharedSecret: "test_secret"}.Sign(tt.encodedPayload)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if headers["X-ACCT-ID"] != "test_account" {
+				t.Errorf("X-ACCT-ID = %q, want %q", headers["X-ACCT-ID"], "test_account")
+			}
+			if headers["X-PAYLOAD-SIG"] != tt.want {
+				t.Errorf("X-PAYLOAD-SIG = %q, want %q", headers["X-PAYLOAD-SIG"], tt.want)
+			}
+		})
+	}
+}
+
+func TestCanonicalQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query url.Values
+		want  string
+	}{
+		{name: "none", query: nil, want: ""},
+		{name: "only sig_payload", query: url.Values{"sig_payload": {"eyJpZCI6IjAifQ=="}}, want: ""},
+		{name: "fields", query: url.Values{"fields": {"id,fullName"}, "sig_payload": {"eyJpZCI6IjAifQ=="}}, want: "fields=id%2CfullName"},
+		{name: "sorted keys and values", query: url.Values{"b": {"x y"}, "a": {"2", "1"}}, want: "a=1&a=2&b=x%20y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanonicalQuery(tt.query); got != tt.want {
+				t.Errorf("CanonicalQuery() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSHA256SignerQueryKnownVector(t *testing.T) {
+	tests := []struct {
+		name           string
+		signQuery      bool
+		canonicalQuery string
+		want           string
+	}{
+		{
+			name:           "fields",
+			signQuery:      true,
+			canonicalQuery: "fields=id%2CfullName",
+			want:           "72f1b628efa934b2f527dddc6da8631b43e168fff1491e416b34d2ca0d31dd06",
+		},
+		{
+			name:           "repeated and escaped values",
+			signQuery:      true,
+			canonicalQuery: "a=1&a=2&b=x%20y",
+			want:           "be0e04ee130d50f2340d7d5d9b1689ba926ed4b691b3f6d1dbbe82cdcff86016",
+		},
+		{
+			name:      "no query matches Sign",
+			signQuery: true,
+			want:      "4b773ad252c6891113571613157c69705a8a5808516a2744fb89c3176e4e2c80",
+		},
+		{
+			name:           "query ignored unless SignQuery",
+			canonicalQuery: "fields=id%2CfullName",
+			want:           "4b773ad252c6891113571613157c69705a8a5808516a2744fb89c3176e4e2c80",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signer := SHA256Signer{AccountID: "test_account", SharedSecret: "test_secret", SignQuery: tt.signQuery}
+			headers, err := signer.SignWithQuery("eyJpZCI6IjAifQ==", tt.canonicalQuery)
+			if err != nil {
+				t.Fatalf("SignWithQuery() error = %v", err)
+			}
+			if headers["X-PAYLOAD-SIG"] != tt.want {
+				t.Errorf("X-PAYLOAD-SIG = %q, want %q", headers["X-PAYLOAD-SIG"], tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigSignQueryParams(t *testing.T) {
+	client := newTestClient(t, &Config{SignQueryParams: true}, func(w http.ResponseWriter, r *http.Request) {
+		payload := r.URL.Query().Get("sig_payload")
+		signed := payload
+		if query := CanonicalQuery(r.URL.Query()); query != "" {
+			signed += "\n" + query
+		}
+		if !verifySignature("test_secret", signed, r.Header.Get("X-PAYLOAD-SIG")) {
+			t.Errorf("invalid signature for %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	if _, err := client.AccessPasses.Get("pass_123", WithFields("id", "fullName")); err != nil {
+		t.Fatalf("Get() with fields error = %v", err)
+	}
+	if _, err := client.AccessPasses.Get("pass_123"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+}
+
+func TestDefaultSignerMatchesAPI(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		payload := r.URL.Query().Get("sig_payload")
+		if !verifySignature("test_secret", payload, r.Header.Get("X-PAYLOAD-SIG")) {
+			t.Errorf("invalid signature for payload %q", payload)
+		}
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	})
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+}
+
+// hmacSHA512Signer signs with HMAC-SHA512 under a custom header
+type hmacSHA512Signer struct {
+	key []byte
+}
+
+func (s hmacSHA512Signer) Sign(encodedPayload string) (map[string]string, error) {
+	mac := hmac.New(sha512.New, s.key)
+	mac.Write([]byte(encodedPayload))
+	return map[string]string{"X-Gateway-Signature": hex.EncodeToString(mac.Sum(nil))}, nil
+}
+
+func TestConfigSigner(t *testing.T) {
+	signer := hmacSHA512Signer{key: []byte("gateway_key")}
+	want, _ := signer.Sign("eyJpZCI6IjAifQ==")
+
+	client := newTestClient(t, &Config{Signer: signer}, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Gateway-Signature"); got != want["X-Gateway-Signature"] {
+			t.Errorf("X-Gateway-Signature = %q, want %q", got, want["X-Gateway-Signature"])
+		}
+		if got := r.Header.Get("X-PAYLOAD-SIG"); got != "" {
+			t.Errorf("X-PAYLOAD-SIG = %q, want none with a custom signer", got)
+		}
+		w.Write([]byte(`{"success": true, "data": {"status": "healthy"}}`))
+	})
+
+	if _, err := client.Health(); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+package doorpasses
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Tracer starts spans for API calls. Implement it with an adapter around
+// your tracing library, e.g. OpenTelemetry.
+type Tracer interface {
+	// Start starts a span as a child of any span in ctx
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// Inject adds the trace propagation headers for the span in ctx
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is a single traced API call
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// Span attribute keys set by the SDK
+const (
+	SpanAttributeHTTPMethod     = "http.method"
+	SpanAttributeHTTPPath       = "http.path"
+	SpanAttributeHTTPStatusCode = "http.status_code"
+	SpanAttributeRequestID      = "doorpasses.request_id"
+)
+
+// withOperation names the API call made with opts, e.g. "AccessPasses.Issue".
+// When calls are nested, the outermost name is kept.
+func withOperation(opts []RequestOption, operation string) []RequestOption {
+	return append(opts[:len(opts):len(opts)], func(o *requestOptions) {
+		if o.operation == "" {
+			o.operation = operation
+		}
+	})
+}
+
+// spanKey is the context key of the span for the current API call
+type spanKey struct{}
+
+// startSpan starts the span for an API call when a tracer is configured
+func (c *HTTPClient) startSpan(ctx context.Context, method, fullURL string, o *requestOptions) (context.Context, Span) {
+	name := "doorpasses." + method
+	if o.operation != "" {
+		name = "doorpasses." + o.operation
+	}
+
+	ctx, span := c.tracer.Start(ctx, name)
+	span.SetAttribute(SpanAttributeHTTPMethod, method)
+	if u, err := url.Parse(fullURL); err == nil {
+		span.SetAttribute(SpanAttributeHTTPPath, u.Path)
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// traceAttempt injects the propagation headers into req before it is sent
+func (c *HTTPClient) traceAttempt(req *http.Request) {
+	if c.tracer != nil {
+		c.tracer.Inject(req.Context(), req.Header)
+	}
+}
+
+// traceResponse records the outcome of an attempt on the call's span
+func (c *HTTPClient) traceResponse(ctx context.Context, resp *http.Response) {
+	if c.tracer == nil || resp == nil {
+		return
+	}
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	span.SetAttribute(SpanAttributeHTTPStatusCode, resp.StatusCode)
+	if id := requestIDFromHeader(resp.Header); id != "" {
+		span.SetAttribute(SpanAttributeRequestID, id)
+	}
+}
@@ -0,0 +1,93 @@
+package doorpasses
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type testSpan struct {
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) { s.attrs[key] = value }
+func (s *testSpan) RecordError(err error)                      { s.err = err }
+func (s *testSpan) End()                                       { s.ended = true }
+
+type testTracer struct {
+	mu    sync.Mutex
+	spans []*testSpan
+}
+
+func (t *testTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &testSpan{name: name, attrs: make(map[string]interface{})}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+func (t *testTracer) Inject(ctx context.Context, header http.Header) {
+	header.Set("traceparent", "00-trace-span-01")
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &testTracer{}
+	client := newTestClient(t, &Config{Tracer: tracer, MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("traceparent"); got != "00-trace-span-01" {
+			t.Errorf("traceparent = %q, want 00-trace-span-01", got)
+		}
+		w.Header().Set("X-Request-ID", "req_123")
+		if r.URL.Path == "/v1/access-passes/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"items": [], "hasMore": false}}`))
+	})
+
+	client.AccessPasses.Issue(validIssueParams())
+	client.AccessPasses.Get("missing")
+	client.AccessPasses.List(nil)
+
+	tests := []struct {
+		name       string
+		path       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "doorpasses.AccessPasses.Issue", path: "/v1/access-passes", statusCode: http.StatusCreated},
+		{name: "doorpasses.AccessPasses.Get", path: "/v1/access-passes/missing", statusCode: http.StatusNotFound, wantErr: true},
+		{name: "doorpasses.AccessPasses.List", path: "/v1/access-passes", statusCode: http.StatusCreated},
+	}
+
+	if len(tracer.spans) != len(tests) {
+		t.Fatalf("recorded %d spans, want %d", len(tracer.spans), len(tests))
+	}
+	for i, tt := range tests {
+		span := tracer.spans[i]
+		if span.name != tt.name {
+			t.Errorf("spans[%d].name = %q, want %q", i, span.name, tt.name)
+		}
+		if !span.ended {
+			t.Errorf("span %q was not ended", span.name)
+		}
+		if got := span.attrs[SpanAttributeHTTPPath]; got != tt.path {
+			t.Errorf("span %q path = %v, want %v", span.name, got, tt.path)
+		}
+		if got := span.attrs[SpanAttributeHTTPStatusCode]; got != tt.statusCode {
+			t.Errorf("span %q status = %v, want %v", span.name, got, tt.statusCode)
+		}
+		if got := span.attrs[SpanAttributeRequestID]; got != "req_123" {
+			t.Errorf("span %q request ID = %v, want req_123", span.name, got)
+		}
+		if (span.err != nil) != tt.wantErr {
+			t.Errorf("span %q error = %v, wantErr %v", span.name, span.err, tt.wantErr)
+		}
+	}
+}
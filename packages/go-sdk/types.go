@@ -1,13 +1,271 @@
 package doorpasses
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
 
 // Config represents configuration options for the DoorPasses client
 type Config struct {
 	AccountID    string
 	SharedSecret string
-	BaseURL      string
-	Timeout      time.Duration
+
+	// BaseURL overrides DefaultBaseURL. It must be an absolute http or https
+	// URL; trailing slashes are removed.
+	BaseURL string
+	Timeout time.Duration
+
+	// DialTimeout bounds establishing a TCP connection, so network problems
+	// fail fast even when Timeout is long enough for large downloads.
+	// Defaults to 30s, the same as http.DefaultTransport; negative disables
+	// it. Ignored when HTTPClient is set.
+	DialTimeout time.Duration
+
+	// TLSHandshakeTimeout bounds the TLS handshake of a new connection.
+	// Defaults to 10s, the same as http.DefaultTransport; negative disables
+	// it. Ignored when HTTPClient is set.
+	TLSHandshakeTimeout time.Duration
+
+	// MaxIdleConns limits the idle keep-alive connections kept across all
+	// hosts. Defaults to 100, the same as http.DefaultTransport; negative
+	// removes the limit. Ignored when HTTPClient is set.
+	MaxIdleConns int
+
+	// MaxIdleConnsPerHost limits the idle keep-alive connections kept to the
+	// API host. Defaults to 2, the same as http.DefaultTransport; raise it
+	// when many requests run concurrently. Negative removes the limit.
+	// Ignored when HTTPClient is set.
+	MaxIdleConnsPerHost int
+
+	// IdleConnTimeout is how long an idle connection is kept before being
+	// closed. Defaults to 90s, the same as http.DefaultTransport; negative
+	// keeps idle connections open indefinitely. Ignored when HTTPClient is
+	// set.
+	IdleConnTimeout time.Duration
+
+	// Signer signs every request, replacing the default SHA256Signer built
+	// from the account ID and shared secret. Only set it when talking to a
+	// gateway that expects a different scheme; the DoorPasses API itself
+	// only accepts SHA256Signer.
+	Signer Signer
+
+	// SignQueryParams makes the default SHA256Signer also sign the query
+	// parameters of GET requests other than sig_payload, such as those added
+	// by WithFields, for a caching proxy that validates the whole URL.
+	// Requests without such parameters are signed as before. Only set it
+	// when whatever checks the signature expects the query to be signed. A
+	// custom Signer signs the query by implementing QuerySigner instead.
+	SignQueryParams bool
+
+	// Marshal encodes request bodies and signed payloads, replacing
+	// json.Marshal. It must produce standard JSON; what it returns is
+	// exactly what is signed and sent. Like json.Marshal, which sorts map
+	// keys, it should encode the same value to the same bytes every time,
+	// so that a proxy re-signing requests computes the same signature.
+	Marshal func(v interface{}) ([]byte, error)
+
+	// Unmarshal decodes response data into the SDK's result types,
+	// replacing json.Unmarshal, e.g. to reject unknown fields. The API's
+	// response envelope and error bodies are always read with encoding/json,
+	// as are types with their own UnmarshalJSON method, such as AccessPass
+	// and CardTemplate, so strict decoding doesn't reach their fields.
+	Unmarshal func(data []byte, v interface{}) error
+
+	// Middleware wraps the transport of the HTTP client, the first entry
+	// being the outermost. It runs before the SDK's auth and signing headers
+	// are finalised, so it can add headers but cannot remove or change them.
+	Middleware []Middleware
+
+	// UserAgent identifies your integration, e.g. "acme-onboarding/2.1". It
+	// is appended to the SDK's own "doorpasses-go/<Version>" User-Agent.
+	UserAgent string
+
+	// HTTPClient is used to send requests instead of a client built by the
+	// SDK, e.g. to configure a proxy, custom TLS roots or connection pooling.
+	// Timeout is applied only when HTTPClient has no timeout of its own.
+	HTTPClient *http.Client
+
+	// CompressRequests gzips request bodies larger than 1KB and sends them
+	// with Content-Encoding: gzip. Responses are always accepted gzipped and
+	// decompressed transparently.
+	CompressRequests bool
+
+	// MaxResponseBytes limits the size of a JSON response body, after
+	// decompression. Larger responses fail with ErrResponseTooLarge. Defaults
+	// to DefaultMaxResponseBytes; a negative value disables the limit.
+	MaxResponseBytes int64
+
+	// MaxDownloadBytes limits the size of a binary download such as
+	// AccessPasses.DownloadApplePass. Defaults to DefaultMaxDownloadBytes; a
+	// negative value disables the limit. Bytes up to the limit have already
+	// been written when ErrResponseTooLarge is returned.
+	MaxDownloadBytes int64
+
+	// MaxRetries is the number of times a GET request, or a request carrying
+	// an idempotency key, is retried after a 5xx or 429 response or a network
+	// error. Defaults to DefaultMaxRetries; a negative value disables retries.
+	MaxRetries int
+
+	// RetryInitialBackoff is the wait before the first retry, doubling on
+	// every retry after that. Defaults to 200ms.
+	RetryInitialBackoff time.Duration
+
+	// RetryMaxBackoff caps the wait between retries, whatever the attempt.
+	// Defaults to 10s. When set, it also caps a custom RetryBackoff. It does
+	// not shorten the wait asked for by a Retry-After header.
+	RetryMaxBackoff time.Duration
+
+	// DisableRetryJitter turns off the random jitter added to each backoff
+	DisableRetryJitter bool
+
+	// RetryBackoff returns how long to wait before the given retry attempt
+	// (starting at 1), replacing RetryInitialBackoff and jitter. Defaults to
+	// exponential backoff with jitter. A 429 response carrying a Retry-After
+	// header waits for that long instead. It may be called concurrently.
+	//
+	// No retry is made when the wait plus another attempt, judged by how
+	// long the last one took, would run past the context deadline. The
+	// error returned instead wraps both context.DeadlineExceeded and the
+	// last attempt's error.
+	RetryBackoff func(attempt int) time.Duration
+
+	// RetryableStatusCodes adds to the statuses that are retried, on top of
+	// 5xx and 429, e.g. 408 or 425 from a gateway that uses them for
+	// transient failures. Like the defaults they only apply to requests that
+	// can be safely retried: GET requests and those carrying an idempotency
+	// key.
+	RetryableStatusCodes []int
+
+	// RetryBudget caps the rate of retries across all calls made with the
+	// client. Once it is spent, failing calls return their error without
+	// retrying. Retries are unlimited when nil.
+	RetryBudget *RetryBudget
+
+	// MaxConcurrentRequests bounds the number of calls in flight at once
+	// across every goroutine sharing the client. Calls beyond it wait for
+	// one to finish, or until their context is done. A call keeps its slot
+	// while it is retried. Unlimited when zero.
+	MaxConcurrentRequests int
+
+	// FailFastOnConcurrencyLimit makes calls beyond MaxConcurrentRequests
+	// fail with ErrTooManyConcurrentRequests instead of waiting
+	FailFastOnConcurrencyLimit bool
+
+	// CircuitBreaker stops requests being sent while the API keeps failing,
+	// failing them with ErrCircuitOpen instead. Retries count as requests,
+	// so an open circuit also cuts retries short. Disabled when nil.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// GenerateIdempotencyKeys makes AccessPasses.Issue send a random
+	// idempotency key when IssueAccessPassParams.IdempotencyKey is empty,
+	// so the request can be safely retried
+	GenerateIdempotencyKeys bool
+
+	// DisableClientValidation skips the Validate method of the params in
+	// AccessPasses.Issue, Update and Patch, leaving all validation to the
+	// server
+	DisableClientValidation bool
+
+	// DisableAccountCheck turns off the check that a response carrying an
+	// accountId belongs to the client's account, for proxies that
+	// legitimately answer for several accounts
+	DisableAccountCheck bool
+
+	// NormalizeInputs trims surrounding whitespace from the string fields of
+	// IssueAccessPassParams before they are validated and signed, and
+	// rejects card numbers that aren't all digits. Card numbers are never
+	// converted to numbers, so leading zeros are kept.
+	NormalizeInputs bool
+
+	// DefaultStartImmediate makes AccessPasses.Issue start a pass now when
+	// neither StartDate nor StartAt is set, instead of rejecting it. The
+	// date is filled in on a copy, so the caller's params are left as they
+	// are.
+	DefaultStartImmediate bool
+
+	// FollowRedirects follows redirects to other hosts, and ones that change
+	// the request method, instead of failing with ErrUnexpectedRedirect.
+	// Redirects within the API's host are always followed, with the auth
+	// headers sent again. A Config.HTTPClient with a CheckRedirect of its
+	// own keeps it.
+	FollowRedirects bool
+
+	// VerifyCredentials makes NewClient call Client.Ping and fail if the
+	// API can't be reached or rejects the credentials, the latter with
+	// ErrInvalidCredentials, so misconfiguration is caught at startup.
+	// NewClient makes no requests when it is false.
+	VerifyCredentials bool
+
+	// Dedupe coalesces concurrent AccessPasses.Issue calls that carry the
+	// same IdempotencyKey into a single request whose result they all
+	// share, marked Replayed for all but the one that sent it; if that
+	// request fails, they all get its error. A call whose key is in flight
+	// with different params fails with ErrIdempotencyKeyReused. Only
+	// caller-supplied keys are coalesced, never generated ones.
+	Dedupe bool
+
+	// BulkConcurrency is the number of requests bulk operations such as
+	// AccessPasses.BulkRevoke keep in flight. Defaults to
+	// DefaultBulkConcurrency. AccessPasses.BulkIssue adapts its concurrency
+	// instead, and never exceeds BulkConcurrency when it is set.
+	BulkConcurrency int
+
+	// BulkAdaptiveConcurrency tunes how AccessPasses.BulkIssue speeds up
+	// while requests succeed and backs off on rate limits. Defaults are
+	// used when nil.
+	BulkAdaptiveConcurrency *AdaptiveConcurrencyConfig
+
+	// DisableAdaptiveBulkConcurrency makes AccessPasses.BulkIssue keep a
+	// fixed BulkConcurrency requests in flight, as the other bulk
+	// operations do
+	DisableAdaptiveBulkConcurrency bool
+
+	// Tracer starts a span for every API call, named after the method, e.g.
+	// "doorpasses.AccessPasses.Issue", and injects trace propagation headers
+	// into its requests. Tracing is disabled when nil.
+	Tracer Tracer
+
+	// MetricsObserver is told the operation, final status and duration of
+	// every API call, e.g. to feed Prometheus histograms and error counters.
+	// It is called from every goroutine using the client, so it must be
+	// safe for concurrent use. Metrics are disabled when nil.
+	MetricsObserver MetricsObserver
+
+	// ResponseCache enables conditional requests for AccessPasses.Get. The
+	// ETag of each fetched pass is stored with it, and later calls send
+	// If-None-Match so an unchanged pass isn't downloaded again. Use
+	// NewMemoryCache or supply your own store. Caching is disabled when nil.
+	ResponseCache ResponseCache
+
+	// Logger receives a debug-level record for every request attempt with
+	// its method, URL, body, status, duration and request ID. Auth headers
+	// are never logged and the RedactFields of the body are redacted.
+	// Logging is disabled when nil.
+	//
+	// Logger, OnRequest and OnResponse are called from every goroutine using
+	// the client, so they must be safe for concurrent use.
+	Logger *slog.Logger
+
+	// OnRequest is called before every request attempt is sent. The request
+	// carries the auth headers, so take care not to log them. Its body is an
+	// uncompressed copy with the RedactFields redacted, and its sig_payload
+	// is redacted; the real ones are sent regardless.
+	OnRequest func(req *http.Request)
+
+	// OnResponse is called after every request attempt with the response,
+	// or the error when no response was received. The response body must
+	// not be read.
+	OnResponse func(req *http.Request, resp *http.Response, err error, duration time.Duration)
+
+	// RedactFields names the request body fields, matched case-insensitively
+	// at any depth, whose values are redacted before the body is logged or
+	// handed to OnRequest. DefaultRedactFields is used when nil; an empty
+	// slice redacts nothing.
+	RedactFields []string
 }
 
 // Response is a common response wrapper
@@ -18,17 +276,57 @@ type Response struct {
 	Message string      `json:"message,omitempty"`
 }
 
-// AccessPassState represents the state of an access pass
+// AccessPassState represents the state of an access pass. The API sends
+// and expects its states in uppercase, e.g. "ACTIVE"; they are decoded
+// case-insensitively into the lowercase constants and encoded back in
+// uppercase.
 type AccessPassState string
 
 const (
+	AccessPassStatePending   AccessPassState = "pending"
 	AccessPassStateActive    AccessPassState = "active"
 	AccessPassStateSuspended AccessPassState = "suspended"
 	AccessPassStateUnlinked  AccessPassState = "unlinked"
 	AccessPassStateDeleted   AccessPassState = "deleted"
+	AccessPassStateRevoked   AccessPassState = "revoked"
 	AccessPassStateExpired   AccessPassState = "expired"
 )
 
+// UnmarshalJSON decodes a state into its constant whatever its case. A
+// state this version of the SDK doesn't recognize is kept exactly as the
+// API sent it, so new server-side states don't break decoding; use Known
+// to tell them apart.
+func (s *AccessPassState) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if state := AccessPassState(strings.ToLower(raw)); state.Known() {
+		*s = state
+	} else {
+		*s = AccessPassState(raw)
+	}
+	return nil
+}
+
+// MarshalJSON encodes a state in the API's uppercase form, e.g. for the
+// state filter of ListAccessPassesParams
+func (s AccessPassState) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(s)))
+}
+
+// Known reports whether s is one of the AccessPassState constants
+func (s AccessPassState) Known() bool {
+	switch s {
+	case AccessPassStatePending, AccessPassStateActive, AccessPassStateSuspended,
+		AccessPassStateUnlinked, AccessPassStateDeleted, AccessPassStateRevoked,
+		AccessPassStateExpired:
+		return true
+	}
+	return false
+}
+
 // Platform represents the platform type
 type Platform string
 
@@ -37,6 +335,24 @@ const (
 	PlatformGoogle Platform = "google"
 )
 
+// WalletType selects card templates by the wallets they support
+type WalletType string
+
+const (
+	WalletTypeApple  WalletType = "apple"
+	WalletTypeGoogle WalletType = "google"
+	WalletTypeBoth   WalletType = "both"
+)
+
+// TemplateStatus represents the publishing status of a card template
+type TemplateStatus string
+
+const (
+	TemplateStatusDraft     TemplateStatus = "DRAFT"
+	TemplateStatusReview    TemplateStatus = "REVIEW"
+	TemplateStatusPublished TemplateStatus = "PUBLISHED"
+)
+
 // Protocol represents the protocol type
 type Protocol string
 
@@ -68,6 +384,7 @@ const (
 // AccessPass represents an access pass
 type AccessPass struct {
 	ID              string                 `json:"id"`
+	AccountID       string                 `json:"accountId,omitempty"`
 	CardTemplateID  string                 `json:"cardTemplateId"`
 	EmployeeID      string                 `json:"employeeId,omitempty"`
 	TagID           string                 `json:"tagId,omitempty"`
@@ -87,25 +404,166 @@ type AccessPass struct {
 	Metadata        map[string]interface{} `json:"metadata,omitempty"`
 	CreatedAt       string                 `json:"createdAt"`
 	UpdatedAt       string                 `json:"updatedAt"`
+
+	// CredentialRotatedAt is when RotateCredential last replaced the pass's
+	// card number, empty if it never has been
+	CredentialRotatedAt string `json:"credentialRotatedAt,omitempty"`
+
+	// AppleInstalled and GoogleInstalled report whether the holder has added
+	// the pass to Apple Wallet or Google Wallet. They are nil when the API
+	// didn't say, e.g. for a wallet the template doesn't target, so nil
+	// means unknown rather than not installed.
+	AppleInstalled  *bool `json:"appleInstalled,omitempty"`
+	GoogleInstalled *bool `json:"googleInstalled,omitempty"`
+
+	// AppleInstalledAt and GoogleInstalledAt are when the pass was added to
+	// each wallet, empty when it isn't installed or the API didn't say
+	AppleInstalledAt  string `json:"appleInstalledAt,omitempty"`
+	GoogleInstalledAt string `json:"googleInstalledAt,omitempty"`
+
+	// Archived marks an access pass that has been archived, e.g. revoked and
+	// later cleaned up. Archived passes are only listed when
+	// ListAccessPassesParams.IncludeArchived is set.
+	Archived bool `json:"archived,omitempty"`
+
+	// DryRun marks a preview returned by an Issue call made WithDryRun. The
+	// pass doesn't exist and its ID must not be used.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Replayed is set on the result of Issue when the API returned the pass
+	// created by an earlier request with the same idempotency key, so the
+	// issuance must not be counted again
+	Replayed bool `json:"-"`
+
+	// NewlyCreated is set on the result of Issue when this call created the
+	// pass, i.e. the API answered 201 Created. It is false when the API
+	// answered 200 OK with a pass that already existed for the card number,
+	// for a Replayed result and for a dry run.
+	NewlyCreated bool `json:"-"`
+
+	// NotModified is set on the result of Get when Config.ResponseCache held
+	// a copy and the API answered 304 Not Modified, so the cached copy was
+	// returned
+	NotModified bool `json:"-"`
+
+	// StartAt, ExpiresAt, Created and Updated are StartDate, ExpirationDate,
+	// CreatedAt and UpdatedAt parsed and converted to UTC. They are zero when
+	// the string is empty or not a recognised timestamp; the string fields
+	// always keep the value the API sent.
+	StartAt   time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+	Created   time.Time `json:"-"`
+	Updated   time.Time `json:"-"`
+
+	// CredentialRotated is CredentialRotatedAt parsed the same way
+	CredentialRotated time.Time `json:"-"`
+
+	// AppleInstallTime and GoogleInstallTime are AppleInstalledAt and
+	// GoogleInstalledAt parsed the same way
+	AppleInstallTime  time.Time `json:"-"`
+	GoogleInstallTime time.Time `json:"-"`
+
+	// Raw is the access pass exactly as the API sent it, for reading fields
+	// this version of the SDK doesn't model yet. It is empty for access
+	// passes that weren't decoded from a response.
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes an access pass, parses its timestamps and keeps the
+// raw payload in Raw
+func (p *AccessPass) UnmarshalJSON(data []byte) error {
+	type accessPass AccessPass
+	if err := json.Unmarshal(data, (*accessPass)(p)); err != nil {
+		return err
+	}
+	p.StartAt = parseTimestamp(p.StartDate)
+	p.ExpiresAt = parseTimestamp(p.ExpirationDate)
+	p.Created = parseTimestamp(p.CreatedAt)
+	p.Updated = parseTimestamp(p.UpdatedAt)
+	p.CredentialRotated = parseTimestamp(p.CredentialRotatedAt)
+	p.AppleInstallTime = parseTimestamp(p.AppleInstalledAt)
+	p.GoogleInstallTime = parseTimestamp(p.GoogleInstalledAt)
+	p.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Installed reports whether the pass is in the holder's Apple or Google
+// wallet. known is false when the API reported the installation state of
+// neither wallet, in which case installed is meaningless.
+func (p *AccessPass) Installed() (installed, known bool) {
+	for _, state := range []*bool{p.AppleInstalled, p.GoogleInstalled} {
+		if state != nil {
+			known = true
+			installed = installed || *state
+		}
+	}
+	return installed, known
+}
+
+// timestampLayouts are the formats accepted by parseTimestamp, most common
+// first. RFC3339Nano also accepts timestamps without fractional seconds.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02",
 }
 
-// IssueAccessPassParams represents parameters for issuing an access pass
+// parseTimestamp parses an API timestamp in UTC, returning the zero time if
+// value is empty or unrecognised. Timestamps without an offset are taken to
+// be UTC.
+func parseTimestamp(value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+// IssueAccessPassParams represents parameters for issuing an access pass.
+// Empty fields are left out of the request rather than sent as "".
 type IssueAccessPassParams struct {
-	CardTemplateID string                 `json:"cardTemplateId"`
+	// ID assigns the new pass's ID instead of letting the API generate one,
+	// e.g. to reuse a stable ID from your own system. It may hold up to
+	// MaxAccessPassIDLength letters, digits, '-' and '_'. Issuing with an ID
+	// another pass already has fails with ErrPassIDTaken.
+	ID string `json:"id,omitempty"`
+
+	CardTemplateID string                 `json:"cardTemplateId,omitempty"`
 	EmployeeID     string                 `json:"employeeId,omitempty"`
 	TagID          string                 `json:"tagId,omitempty"`
 	SiteCode       string                 `json:"siteCode,omitempty"`
-	CardNumber     string                 `json:"cardNumber"`
+	CardNumber     string                 `json:"cardNumber,omitempty"`
 	FileData       string                 `json:"fileData,omitempty"`
-	FullName       string                 `json:"fullName"`
+	FullName       string                 `json:"fullName,omitempty"`
 	Email          string                 `json:"email,omitempty"`
 	PhoneNumber    string                 `json:"phoneNumber,omitempty"`
 	Classification Classification         `json:"classification,omitempty"`
-	StartDate      string                 `json:"startDate"`
-	ExpirationDate string                 `json:"expirationDate"`
+	StartDate      string                 `json:"startDate,omitempty"`
+	ExpirationDate string                 `json:"expirationDate,omitempty"`
 	EmployeePhoto  string                 `json:"employeePhoto,omitempty"`
 	Title          string                 `json:"title,omitempty"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+
+	// StartAt and ExpiresAt are alternatives to StartDate and ExpirationDate.
+	// When set, they are converted to UTC and formatted as RFC3339 before
+	// the request is sent. Setting both forms of the same date is an error.
+	StartAt   time.Time `json:"-"`
+	ExpiresAt time.Time `json:"-"`
+
+	// IdempotencyKey is sent as the Idempotency-Key header so that retrying
+	// the same issuance never creates a duplicate pass
+	IdempotencyKey string `json:"-"`
+
+	// CardTemplateRef is an alternative to CardTemplateID that names the
+	// card template by its ExternalRef. It is resolved to an ID with
+	// Console.ResolveTemplate before the request is sent. Setting both is an
+	// error.
+	CardTemplateRef string `json:"-"`
 }
 
 // UpdateAccessPassParams represents parameters for updating an access pass
@@ -119,12 +577,177 @@ type UpdateAccessPassParams struct {
 	Title          string                 `json:"title,omitempty"`
 	FileData       string                 `json:"fileData,omitempty"`
 	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+
+	// IfUnmodifiedSince makes the update fail with ErrConflict when the pass
+	// has changed since then, e.g. set it to the Updated time of the pass as
+	// read by Get. Leave it zero to write unconditionally.
+	IfUnmodifiedSince time.Time `json:"-"`
+}
+
+// PatchAccessPassParams represents a partial update of an access pass. Only
+// non-nil fields are sent, so a field can be cleared by pointing it at an
+// empty value.
+type PatchAccessPassParams struct {
+	EmployeeID     *string         `json:"employeeId,omitempty"`
+	FullName       *string         `json:"fullName,omitempty"`
+	Classification *Classification `json:"classification,omitempty"`
+	ExpirationDate *string         `json:"expirationDate,omitempty"`
+	EmployeePhoto  *string         `json:"employeePhoto,omitempty"`
+	Title          *string         `json:"title,omitempty"`
+	FileData       *string         `json:"fileData,omitempty"`
+
+	// ExpiresAt is an alternative to ExpirationDate, formatted as UTC RFC3339
+	ExpiresAt *time.Time `json:"-"`
+
+	// Metadata replaces the pass metadata when non-nil, so an empty map
+	// clears it
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MarshalJSON sends Metadata whenever it is non-nil, even when empty
+func (p PatchAccessPassParams) MarshalJSON() ([]byte, error) {
+	type patch PatchAccessPassParams
+	aux := struct {
+		patch
+		Metadata *map[string]interface{} `json:"metadata,omitempty"`
+	}{patch: patch(p)}
+	if p.Metadata != nil {
+		aux.Metadata = &p.Metadata
+	}
+	return json.Marshal(aux)
+}
+
+// DeliveryChannel represents how an access pass invitation is delivered
+type DeliveryChannel string
+
+const (
+	DeliveryChannelEmail DeliveryChannel = "email"
+	DeliveryChannelSMS   DeliveryChannel = "sms"
+)
+
+// resendInviteParams is the request body for resending an invitation
+type resendInviteParams struct {
+	Channel DeliveryChannel `json:"channel,omitempty"`
+}
+
+// ResendInviteResult confirms that an invitation was sent again
+type ResendInviteResult struct {
+	Success bool            `json:"success"`
+	Channel DeliveryChannel `json:"channel"`
+	Message string          `json:"message,omitempty"`
+}
+
+// revokeAccessPassParams is the request body for revoking an access pass
+type revokeAccessPassParams struct {
+	Reason string `json:"reason,omitempty"`
 }
 
 // ListAccessPassesParams represents parameters for listing access passes
 type ListAccessPassesParams struct {
 	TemplateID string          `json:"template_id,omitempty"`
 	State      AccessPassState `json:"state,omitempty"`
+	Email      string          `json:"email,omitempty"`
+
+	// Limit is the maximum number of access passes per page
+	Limit int `json:"limit,omitempty"`
+
+	// Cursor is the opaque NextCursor from a previous page
+	Cursor string `json:"cursor,omitempty"`
+
+	// IncludeArchived also lists archived access passes, which are left out
+	// by default. They are returned with AccessPass.Archived set.
+	IncludeArchived bool `json:"include_archived,omitempty"`
+
+	// Metadata only lists access passes whose metadata sets every one of
+	// these keys to the given value
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// ExpiresBefore only lists access passes whose expiration date is
+	// before this time. It is ignored when zero.
+	ExpiresBefore time.Time `json:"-"`
+}
+
+// sigPayload builds the signed query payload for a list request
+func (p *ListAccessPassesParams) sigPayload() map[string]interface{} {
+	sigPayload := make(map[string]interface{})
+	if p == nil {
+		return sigPayload
+	}
+
+	if p.TemplateID != "" {
+		sigPayload["template_id"] = p.TemplateID
+	}
+	if p.State != "" {
+		sigPayload["state"] = p.State
+	}
+	if p.Email != "" {
+		sigPayload["email"] = p.Email
+	}
+	if p.Limit > 0 {
+		sigPayload["limit"] = p.Limit
+	}
+	if p.Cursor != "" {
+		sigPayload["cursor"] = p.Cursor
+	}
+	if p.IncludeArchived {
+		sigPayload["include_archived"] = true
+	}
+	if len(p.Metadata) > 0 {
+		sigPayload["metadata"] = p.Metadata
+	}
+	if !p.ExpiresBefore.IsZero() {
+		sigPayload["expires_before"] = p.ExpiresBefore.UTC().Format(time.RFC3339)
+	}
+	return sigPayload
+}
+
+// AccessPassPage represents a single page of access passes
+type AccessPassPage struct {
+	Items []AccessPass `json:"items"`
+
+	// NextCursor is an opaque cursor for the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// HasMore reports whether there are more pages after this one
+	HasMore bool `json:"hasMore"`
+
+	// TotalCount is the number of access passes matching the query across
+	// all pages. It is only meaningful when HasTotal is set, since not
+	// every endpoint reports it.
+	TotalCount int `json:"-"`
+
+	// HasTotal reports whether the API sent a total count, as the page's
+	// pagination.total or totalCount field or the X-Total-Count header
+	HasTotal bool `json:"-"`
+}
+
+// UnmarshalJSON accepts either a page object or a bare array of access passes
+func (p *AccessPassPage) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*p = AccessPassPage{}
+		return json.Unmarshal(trimmed, &p.Items)
+	}
+
+	// The API sends its count in a pagination object, e.g.
+	// {"items": [...], "pagination": {"page": 1, "limit": 50, "total": 3412, "totalPages": 69}}
+	type page AccessPassPage
+	aux := struct {
+		*page
+		TotalCount *int `json:"totalCount"`
+		Pagination struct {
+			Total *int `json:"total"`
+		} `json:"pagination"`
+	}{page: (*page)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch {
+	case aux.Pagination.Total != nil:
+		p.TotalCount, p.HasTotal = *aux.Pagination.Total, true
+	case aux.TotalCount != nil:
+		p.TotalCount, p.HasTotal = *aux.TotalCount, true
+	}
+	return nil
 }
 
 // CardTemplateDesign represents design configuration for card templates
@@ -154,6 +777,23 @@ const (
 	UseCaseHotel         UseCase = "hotel"
 )
 
+// FieldPosition represents where a field is shown on a pass
+type FieldPosition string
+
+const (
+	FieldPositionFront FieldPosition = "front"
+	FieldPositionBack  FieldPosition = "back"
+)
+
+// CardTemplateField represents a field in a card template's layout
+type CardTemplateField struct {
+	// Key is the name of the access pass attribute shown in the field, e.g.
+	// "fullName" or "employeeId"
+	Key      string        `json:"key"`
+	Label    string        `json:"label"`
+	Position FieldPosition `json:"position,omitempty"`
+}
+
 // CardTemplate represents a card template
 type CardTemplate struct {
 	ID                     string                 `json:"id"`
@@ -165,10 +805,51 @@ type CardTemplate struct {
 	WatchCount             *int                   `json:"watchCount,omitempty"`
 	IPhoneCount            *int                   `json:"iphoneCount,omitempty"`
 	Design                 *CardTemplateDesign    `json:"design,omitempty"`
+	Fields                 []CardTemplateField    `json:"fields,omitempty"`
 	SupportInfo            *SupportInfo           `json:"supportInfo,omitempty"`
 	Metadata               map[string]interface{} `json:"metadata,omitempty"`
+	ExternalRef            string                 `json:"externalRef,omitempty"`
+	Status                 TemplateStatus         `json:"status,omitempty"`
 	CreatedAt              string                 `json:"createdAt"`
 	UpdatedAt              string                 `json:"updatedAt"`
+
+	// SupportedWallets lists the wallets passes from this template can be
+	// added to. When the API doesn't send it, Platform is the only one.
+	SupportedWallets []Platform `json:"supportedWallets,omitempty"`
+
+	// Raw is the card template exactly as the API sent it, for reading
+	// fields this version of the SDK doesn't model yet
+	Raw json.RawMessage `json:"-"`
+}
+
+// SupportsWallet reports whether passes from the template can be added to
+// the platform's wallet
+func (t *CardTemplate) SupportsWallet(platform Platform) bool {
+	if len(t.SupportedWallets) == 0 {
+		return strings.EqualFold(string(t.Platform), string(platform))
+	}
+	for _, wallet := range t.SupportedWallets {
+		if strings.EqualFold(string(wallet), string(platform)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Usable reports whether the template is published, so passes can be
+// issued from it now
+func (t *CardTemplate) Usable() bool {
+	return strings.EqualFold(string(t.Status), string(TemplateStatusPublished))
+}
+
+// UnmarshalJSON decodes a card template and keeps the raw payload in Raw
+func (t *CardTemplate) UnmarshalJSON(data []byte) error {
+	type cardTemplate CardTemplate
+	if err := json.Unmarshal(data, (*cardTemplate)(t)); err != nil {
+		return err
+	}
+	t.Raw = append(json.RawMessage(nil), data...)
+	return nil
 }
 
 // CreateCardTemplateParams represents parameters for creating a card template
@@ -181,8 +862,10 @@ type CreateCardTemplateParams struct {
 	WatchCount             *int                   `json:"watchCount,omitempty"`
 	IPhoneCount            *int                   `json:"iphoneCount,omitempty"`
 	Design                 *CardTemplateDesign    `json:"design,omitempty"`
+	Fields                 []CardTemplateField    `json:"fields,omitempty"`
 	SupportInfo            *SupportInfo           `json:"supportInfo,omitempty"`
 	Metadata               map[string]interface{} `json:"metadata,omitempty"`
+	ExternalRef            string                 `json:"externalRef,omitempty"`
 }
 
 // UpdateCardTemplateParams represents parameters for updating a card template
@@ -192,8 +875,77 @@ type UpdateCardTemplateParams struct {
 	AllowOnMultipleDevices *bool                  `json:"allowOnMultipleDevices,omitempty"`
 	WatchCount             *int                   `json:"watchCount,omitempty"`
 	IPhoneCount            *int                   `json:"iphoneCount,omitempty"`
+	Design                 *CardTemplateDesign    `json:"design,omitempty"`
+	Fields                 []CardTemplateField    `json:"fields,omitempty"`
 	SupportInfo            *SupportInfo           `json:"supportInfo,omitempty"`
 	Metadata               map[string]interface{} `json:"metadata,omitempty"`
+	ExternalRef            string                 `json:"externalRef,omitempty"`
+}
+
+// ListCardTemplatesParams represents parameters for listing card templates
+type ListCardTemplatesParams struct {
+	Platform Platform `json:"platform,omitempty"`
+
+	// Status only lists templates with this publishing status, e.g.
+	// TemplateStatusPublished for the templates passes can be issued from
+	Status TemplateStatus `json:"status,omitempty"`
+
+	// WalletType only lists templates supporting this wallet, or both
+	// wallets for WalletTypeBoth
+	WalletType WalletType `json:"walletType,omitempty"`
+
+	// Limit is the maximum number of card templates per page
+	Limit int `json:"limit,omitempty"`
+
+	// Cursor is the opaque NextCursor from a previous page
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// sigPayload builds the signed query payload for a list request
+func (p *ListCardTemplatesParams) sigPayload() map[string]interface{} {
+	sigPayload := make(map[string]interface{})
+	if p == nil {
+		return sigPayload
+	}
+
+	if p.Platform != "" {
+		sigPayload["platform"] = p.Platform
+	}
+	if p.Status != "" {
+		sigPayload["status"] = p.Status
+	}
+	if p.WalletType != "" {
+		sigPayload["wallet_type"] = p.WalletType
+	}
+	if p.Limit > 0 {
+		sigPayload["limit"] = p.Limit
+	}
+	if p.Cursor != "" {
+		sigPayload["cursor"] = p.Cursor
+	}
+	return sigPayload
+}
+
+// CardTemplatePage represents a single page of card templates
+type CardTemplatePage struct {
+	Items []CardTemplate `json:"items"`
+
+	// NextCursor is an opaque cursor for the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// HasMore reports whether there are more pages after this one
+	HasMore bool `json:"hasMore"`
+}
+
+// UnmarshalJSON accepts either a page object or a bare array of card templates
+func (p *CardTemplatePage) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		*p = CardTemplatePage{}
+		return json.Unmarshal(trimmed, &p.Items)
+	}
+
+	type page CardTemplatePage
+	return json.Unmarshal(data, (*page)(p))
 }
 
 // EventType represents the type of event
@@ -219,7 +971,7 @@ const (
 // EventLogEntry represents an event log entry
 type EventLogEntry struct {
 	ID        string                 `json:"id"`
-	Type      string                 `json:"type"`
+	Type      EventType              `json:"type"`
 	Timestamp string                 `json:"timestamp"`
 	UserID    string                 `json:"userId,omitempty"`
 	Device    string                 `json:"device,omitempty"`
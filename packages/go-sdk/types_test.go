@@ -0,0 +1,281 @@
+package doorpasses
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestAccessPassStateUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		want      AccessPassState
+		wantKnown bool
+	}{
+		{name: "active", json: `"active"`, want: AccessPassStateActive, wantKnown: true},
+		{name: "revoked", json: `"revoked"`, want: AccessPassStateRevoked, wantKnown: true},
+		{name: "pending", json: `"pending"`, want: AccessPassStatePending, wantKnown: true},
+		{name: "active as the API sends it", json: `"ACTIVE"`, want: AccessPassStateActive, wantKnown: true},
+		{name: "suspended as the API sends it", json: `"SUSPENDED"`, want: AccessPassStateSuspended, wantKnown: true},
+		{name: "deleted as the API sends it", json: `"DELETED"`, want: AccessPassStateDeleted, wantKnown: true},
+		{name: "mixed case", json: `"Expired"`, want: AccessPassStateExpired, wantKnown: true},
+		{name: "unknown value", json: `"ARCHIVED_V2"`, want: "ARCHIVED_V2"},
+		{name: "typo", json: `"reovked"`, want: "reovked"},
+		{name: "null", json: `null`, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accessPass AccessPass
+			err := json.Unmarshal([]byte(`{"id": "pass_123", "state": `+tt.json+`}`), &accessPass)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if accessPass.State != tt.want {
+				t.Errorf("State = %q, want %q", accessPass.State, tt.want)
+			}
+			if got := accessPass.State.Known(); got != tt.wantKnown {
+				t.Errorf("Known() = %v, want %v", got, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestAccessPassTimestamps(t *testing.T) {
+	want := time.Date(2025, 11, 1, 8, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{name: "utc", value: "2025-11-01T08:30:00Z", want: want},
+		{name: "fractional seconds", value: "2025-11-01T08:30:00.000Z", want: want},
+		{name: "offset", value: "2025-11-01T10:30:00+02:00", want: want},
+		{name: "negative offset with fraction", value: "2025-11-01T03:30:00.000-05:00", want: want},
+		{name: "no offset", value: "2025-11-01T08:30:00", want: want},
+		{name: "space separator", value: "2025-11-01 08:30:00+00:00", want: want},
+		{name: "date only", value: "2025-11-01", want: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "empty", value: ""},
+		{name: "invalid", value: "next tuesday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accessPass AccessPass
+			body := `{"id": "pass_123", "startDate": "` + tt.value + `", "expirationDate": "` + tt.value +
+				`", "createdAt": "` + tt.value + `", "updatedAt": "` + tt.value + `"}`
+			if err := json.Unmarshal([]byte(body), &accessPass); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			for field, got := range map[string]time.Time{
+				"StartAt":   accessPass.StartAt,
+				"ExpiresAt": accessPass.ExpiresAt,
+				"Created":   accessPass.Created,
+				"Updated":   accessPass.Updated,
+			} {
+				if !got.Equal(tt.want) || got.Location() != time.UTC {
+					t.Errorf("%s = %v, want %v in UTC", field, got, tt.want)
+				}
+			}
+			if accessPass.StartDate != tt.value {
+				t.Errorf("StartDate = %q, want original %q", accessPass.StartDate, tt.value)
+			}
+		})
+	}
+}
+
+func TestAccessPassInstalled(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantInstalled bool
+		wantKnown     bool
+		wantApple     time.Time
+	}{
+		{name: "unknown", body: `{"id": "pass_123"}`},
+		{name: "not installed", body: `{"id": "pass_123", "appleInstalled": false}`, wantKnown: true},
+		{
+			name:          "installed in one wallet",
+			body:          `{"id": "pass_123", "appleInstalled": true, "appleInstalledAt": "2025-11-01T08:30:00Z", "googleInstalled": false}`,
+			wantInstalled: true,
+			wantKnown:     true,
+			wantApple:     time.Date(2025, 11, 1, 8, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accessPass AccessPass
+			if err := json.Unmarshal([]byte(tt.body), &accessPass); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			installed, known := accessPass.Installed()
+			if installed != tt.wantInstalled || known != tt.wantKnown {
+				t.Errorf("Installed() = %v, %v, want %v, %v", installed, known, tt.wantInstalled, tt.wantKnown)
+			}
+			if !accessPass.AppleInstallTime.Equal(tt.wantApple) {
+				t.Errorf("AppleInstallTime = %v, want %v", accessPass.AppleInstallTime, tt.wantApple)
+			}
+			if !tt.wantKnown && (accessPass.AppleInstalled != nil || accessPass.GoogleInstalled != nil) {
+				t.Errorf("installation state = %v, %v, want both unknown", accessPass.AppleInstalled, accessPass.GoogleInstalled)
+			}
+		})
+	}
+}
+
+func TestRawPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		decode func(data []byte) (json.RawMessage, error)
+	}{
+		{
+			name: "access pass",
+			data: `{"id": "pass_123", "fullName": "John Doe", "walletKind": "watch"}`,
+			decode: func(data []byte) (json.RawMessage, error) {
+				var pass AccessPass
+				err := json.Unmarshal(data, &pass)
+				if pass.FullName != "John Doe" {
+					t.Errorf("FullName = %q, want %q", pass.FullName, "John Doe")
+				}
+				return pass.Raw, err
+			},
+		},
+		{
+			name: "card template",
+			data: `{"id": "template_123", "name": "Employee Badge", "walletKind": "watch"}`,
+			decode: func(data []byte) (json.RawMessage, error) {
+				var template CardTemplate
+				err := json.Unmarshal(data, &template)
+				if template.Name != "Employee Badge" {
+					t.Errorf("Name = %q, want %q", template.Name, "Employee Badge")
+				}
+				return template.Raw, err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.data)
+			raw, err := tt.decode(data)
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+
+			// Raw must not alias the decoded buffer
+			copy(data, bytes.Repeat([]byte{' '}, len(data)))
+
+			var extra struct {
+				WalletKind string `json:"walletKind"`
+			}
+			if err := json.Unmarshal(raw, &extra); err != nil {
+				t.Fatalf("Unmarshal(Raw) error = %v", err)
+			}
+			if extra.WalletKind != "watch" {
+				t.Errorf("Raw walletKind = %q, want %q", extra.WalletKind, "watch")
+			}
+		})
+	}
+}
+
+func TestCardTemplateSupportsWallet(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   CardTemplate
+		wantApple  bool
+		wantGoogle bool
+	}{
+		{name: "platform only", template: CardTemplate{Platform: PlatformApple}, wantApple: true},
+		{name: "platform in upper case", template: CardTemplate{Platform: "GOOGLE"}, wantGoogle: true},
+		{
+			name:       "both wallets",
+			template:   CardTemplate{Platform: PlatformApple, SupportedWallets: []Platform{PlatformApple, PlatformGoogle}},
+			wantApple:  true,
+			wantGoogle: true,
+		},
+		{
+			name:       "supported wallets override platform",
+			template:   CardTemplate{Platform: PlatformApple, SupportedWallets: []Platform{PlatformGoogle}},
+			wantGoogle: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.template.SupportsWallet(PlatformApple); got != tt.wantApple {
+				t.Errorf("SupportsWallet(apple) = %v, want %v", got, tt.wantApple)
+			}
+			if got := tt.template.SupportsWallet(PlatformGoogle); got != tt.wantGoogle {
+				t.Errorf("SupportsWallet(google) = %v, want %v", got, tt.wantGoogle)
+			}
+		})
+	}
+}
+
+func TestParamsJSONFields(t *testing.T) {
+	empty := ""
+	tests := []struct {
+		name   string
+		params interface{}
+		want   []string
+	}{
+		{
+			name:   "issue leaves unset fields out",
+			params: IssueAccessPassParams{CardTemplateID: "template_123", FileData: "data", FullName: "Jane Doe"},
+			want:   []string{"cardTemplateId", "fileData", "fullName"},
+		},
+		{
+			name:   "issue sends set fields",
+			params: validIssueParams(),
+			want:   []string{"cardNumber", "cardTemplateId", "email", "expirationDate", "fullName", "startDate"},
+		},
+		{
+			name:   "update leaves empty fields out",
+			params: UpdateAccessPassParams{AccessPassID: "pass_123", Title: "Engineer"},
+			want:   []string{"title"},
+		},
+		{
+			name:   "patch leaves nil fields out",
+			params: PatchAccessPassParams{},
+			want:   []string{},
+		},
+		{
+			name:   "patch sends fields set to empty",
+			params: PatchAccessPassParams{Title: &empty, Metadata: map[string]interface{}{}},
+			want:   []string{"metadata", "title"},
+		},
+		{
+			name:   "update template leaves unset fields out",
+			params: UpdateCardTemplateParams{CardTemplateID: "template_123", Name: "Lobby"},
+			want:   []string{"name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.params)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(data, &fields); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			got := make([]string, 0, len(fields))
+			for field := range fields {
+				got = append(got, field)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fields = %v, want %v (body %s)", got, tt.want, data)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package doorpasses
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AccountUsage is the account's issuance quota and how much of it has been
+// used in the current period
+type AccountUsage struct {
+	// PassesIssued is the number of access passes issued this period
+	PassesIssued int
+
+	// PassesLimit is the number of access passes the account may issue per
+	// period. It is only meaningful when Unlimited is false.
+	PassesLimit int
+
+	// Unlimited reports that the account has no issuance limit
+	Unlimited bool
+
+	// ResetsAt is when the period ends and PassesIssued goes back to zero,
+	// zero when the API didn't say, e.g. for an unlimited account
+	ResetsAt time.Time
+}
+
+// accountUsageJSON is AccountUsage as the API sends it. A missing, null or
+// negative passesLimit means the account is unlimited.
+type accountUsageJSON struct {
+	PassesIssued int    `json:"passesIssued"`
+	PassesLimit  *int   `json:"passesLimit"`
+	ResetsAt     string `json:"resetsAt"`
+}
+
+// UnmarshalJSON decodes the API's usage object
+func (u *AccountUsage) UnmarshalJSON(data []byte) error {
+	var raw accountUsageJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*u = AccountUsage{
+		PassesIssued: raw.PassesIssued,
+		Unlimited:    raw.PassesLimit == nil || *raw.PassesLimit < 0,
+		ResetsAt:     parseTimestamp(raw.ResetsAt),
+	}
+	if !u.Unlimited {
+		u.PassesLimit = *raw.PassesLimit
+	}
+	return nil
+}
+
+// Remaining returns how many more access passes can be issued this period.
+// ok is false when the account is unlimited.
+func (u *AccountUsage) Remaining() (remaining int, ok bool) {
+	if u.Unlimited {
+		return 0, false
+	}
+	return max(u.PassesLimit-u.PassesIssued, 0), true
+}
+
+// Usage returns the account's issuance quota and usage, e.g. to warn admins
+// before a large onboarding runs into the limit
+func (c *Client) Usage(opts ...RequestOption) (*AccountUsage, error) {
+	return c.UsageWithContext(context.Background(), opts...)
+}
+
+// UsageWithContext returns the account's issuance quota and usage, aborting
+// if ctx is done
+func (c *Client) UsageWithContext(ctx context.Context, opts ...RequestOption) (*AccountUsage, error) {
+	opts = withOperation(opts, "Client.Usage")
+	var usage AccountUsage
+	if err := c.http.GetWithContext(ctx, "/v1/account/usage", nil, &usage, opts...); err != nil {
+		return nil, err
+	}
+	return &usage, nil
+}
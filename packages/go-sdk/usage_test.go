@@ -0,0 +1,76 @@
+package doorpasses
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClientUsage(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		want          AccountUsage
+		wantRemaining int
+		wantLimited   bool
+	}{
+		{
+			name:          "limited",
+			data:          `{"passesIssued": 940, "passesLimit": 1000, "resetsAt": "2025-12-01T00:00:00Z"}`,
+			want:          AccountUsage{PassesIssued: 940, PassesLimit: 1000, ResetsAt: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)},
+			wantRemaining: 60,
+			wantLimited:   true,
+		},
+		{
+			name:          "over the limit",
+			data:          `{"passesIssued": 1002, "passesLimit": 1000}`,
+			want:          AccountUsage{PassesIssued: 1002, PassesLimit: 1000},
+			wantRemaining: 0,
+			wantLimited:   true,
+		},
+		{
+			name:        "zero limit is not unlimited",
+			data:        `{"passesIssued": 0, "passesLimit": 0}`,
+			want:        AccountUsage{},
+			wantLimited: true,
+		},
+		{
+			name: "null limit",
+			data: `{"passesIssued": 5000, "passesLimit": null}`,
+			want: AccountUsage{PassesIssued: 5000, Unlimited: true},
+		},
+		{
+			name: "missing limit",
+			data: `{"passesIssued": 12}`,
+			want: AccountUsage{PassesIssued: 12, Unlimited: true},
+		},
+		{
+			name: "negative limit",
+			data: `{"passesIssued": 12, "passesLimit": -1}`,
+			want: AccountUsage{PassesIssued: 12, Unlimited: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/v1/account/usage" {
+					t.Errorf("request = %s %s, want GET /v1/account/usage", r.Method, r.URL.Path)
+				}
+				w.Write([]byte(`{"success": true, "data": ` + tt.data + `}`))
+			})
+
+			usage, err := client.Usage()
+			if err != nil {
+				t.Fatalf("Usage() error = %v", err)
+			}
+			if *usage != tt.want {
+				t.Errorf("Usage() = %+v, want %+v", *usage, tt.want)
+			}
+			remaining, limited := usage.Remaining()
+			if remaining != tt.wantRemaining || limited != tt.wantLimited {
+				t.Errorf("Remaining() = %d, %v, want %d, %v", remaining, limited, tt.wantRemaining, tt.wantLimited)
+			}
+		})
+	}
+}
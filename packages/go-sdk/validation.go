@@ -0,0 +1,241 @@
+package doorpasses
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Codes of the field errors found by client-side validation. Field errors
+// from the API carry whatever code it sent, if any.
+const (
+	FieldErrorRequired      = "required"
+	FieldErrorInvalidFormat = "invalid_format"
+	FieldErrorConflict      = "conflict"
+	FieldErrorOutOfRange    = "out_of_range"
+	FieldErrorUnexpected    = "unexpected"
+)
+
+// Limits on access pass metadata enforced by the SDK's client-side
+// validation. The API itself doesn't limit metadata, so these only apply
+// unless Config.DisableClientValidation is set.
+const (
+	// MaxMetadataKeyLength is the longest metadata key, in characters
+	MaxMetadataKeyLength = 40
+
+	// MaxMetadataValueLength is the longest metadata value, in characters.
+	// A value that isn't a string is measured by its JSON encoding.
+	MaxMetadataValueLength = 500
+)
+
+// MaxAccessPassIDLength is the longest client-assigned access pass ID, in
+// characters
+const MaxAccessPassIDLength = 64
+
+// FieldError describes a single invalid parameter
+type FieldError struct {
+	// Field is the JSON name of the invalid parameter, e.g. "cardTemplateId"
+	Field string `json:"field"`
+
+	// Code is the machine-readable reason, e.g. FieldErrorRequired
+	Code string `json:"code,omitempty"`
+
+	// Message describes why the parameter is invalid
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when request parameters fail validation,
+// either client-side or by the API. It lists every invalid field at once.
+type ValidationError struct {
+	Fields []FieldError
+
+	// Message is the API's error message. It is all there is to go on when
+	// the API rejected the parameters without listing the fields.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Fields) == 0 {
+		return fmt.Sprintf("invalid parameters: %s", e.Message)
+	}
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Field + ": " + field.Message
+	}
+	return fmt.Sprintf("invalid parameters: %s", strings.Join(messages, "; "))
+}
+
+// add records an invalid field
+func (e *ValidationError) add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// errOrNil returns e when it holds at least one field error, nil otherwise
+func (e *ValidationError) errOrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// normalized returns a copy of the params with surrounding whitespace
+// trimmed from their string fields. Card numbers are kept as strings, so
+// leading zeros survive, but one that isn't all digits is rejected with a
+// *ValidationError.
+func (p IssueAccessPassParams) normalized() (IssueAccessPassParams, error) {
+	for _, field := range []*string{
+		&p.ID, &p.CardTemplateID, &p.CardTemplateRef, &p.EmployeeID, &p.TagID, &p.SiteCode,
+		&p.CardNumber, &p.FileData, &p.FullName, &p.Email, &p.PhoneNumber, &p.Title,
+	} {
+		*field = strings.TrimSpace(*field)
+	}
+
+	if strings.TrimLeft(p.CardNumber, "0123456789") != "" {
+		errs := &ValidationError{}
+		errs.add("cardNumber", FieldErrorInvalidFormat, "must contain only digits")
+		return p, errs
+	}
+	return p, nil
+}
+
+// Validate checks the params for missing required fields, malformed IDs,
+// email addresses and dates, an expiration that isn't after the start
+// date and metadata over the length limits. It returns a
+// *ValidationError listing every failing field.
+func (p IssueAccessPassParams) Validate() error {
+	errs := &ValidationError{}
+
+	if p.ID != "" && !isValidAccessPassID(p.ID) {
+		errs.add("id", FieldErrorInvalidFormat, fmt.Sprintf("must be at most %d letters, digits, '-' or '_'", MaxAccessPassIDLength))
+	}
+
+	switch {
+	case p.CardTemplateID != "" && p.CardTemplateRef != "":
+		errs.add("cardTemplateRef", FieldErrorConflict, "must not be set together with cardTemplateId")
+	case p.CardTemplateID == "" && p.CardTemplateRef == "":
+		errs.add("cardTemplateId", FieldErrorRequired, "is required")
+	}
+	if strings.TrimSpace(p.FullName) == "" {
+		errs.add("fullName", FieldErrorRequired, "is required")
+	}
+	if p.CardNumber == "" && p.FileData == "" {
+		errs.add("cardNumber", FieldErrorRequired, "is required when fileData is not set")
+	}
+	if p.Email != "" && !isValidEmail(p.Email) {
+		errs.add("email", FieldErrorInvalidFormat, "is not a valid email address")
+	}
+
+	start := validateDate(errs, "startDate", p.StartDate, p.StartAt)
+	expiration := validateDate(errs, "expirationDate", p.ExpirationDate, p.ExpiresAt)
+	if !start.IsZero() && !expiration.IsZero() && !expiration.After(start) {
+		errs.add("expirationDate", FieldErrorOutOfRange, "must be after startDate")
+	}
+	validateMetadata(errs, p.Metadata)
+
+	return errs.errOrNil()
+}
+
+// Validate checks the metadata of the params against the key and value
+// length limits. It returns a *ValidationError listing every failing key.
+func (p UpdateAccessPassParams) Validate() error {
+	errs := &ValidationError{}
+	validateMetadata(errs, p.Metadata)
+	return errs.errOrNil()
+}
+
+// validateMetadata checks metadata keys and string values against
+// MaxMetadataKeyLength and MaxMetadataValueLength, in key order
+func validateMetadata(errs *ValidationError, metadata map[string]interface{}) {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field := "metadata." + key
+		if key == "" {
+			errs.add("metadata", FieldErrorRequired, "keys must not be empty")
+			continue
+		}
+		if utf8.RuneCountInString(key) > MaxMetadataKeyLength {
+			errs.add(field, FieldErrorOutOfRange, fmt.Sprintf("key must be at most %d characters", MaxMetadataKeyLength))
+		}
+		value, ok := metadata[key].(string)
+		if !ok {
+			encoded, err := json.Marshal(metadata[key])
+			if err != nil {
+				errs.add(field, FieldErrorInvalidFormat, "value must be encodable as JSON")
+				continue
+			}
+			value = string(encoded)
+		}
+		if utf8.RuneCountInString(value) > MaxMetadataValueLength {
+			errs.add(field, FieldErrorOutOfRange, fmt.Sprintf("value must be at most %d characters", MaxMetadataValueLength))
+		}
+	}
+}
+
+// validateDate checks a date given either as an RFC3339 string or as a
+// time.Time, returning the parsed value when it is valid
+func validateDate(errs *ValidationError, field, value string, t time.Time) time.Time {
+	if !t.IsZero() {
+		return t
+	}
+	if value == "" {
+		errs.add(field, FieldErrorRequired, "is required")
+		return time.Time{}
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		errs.add(field, FieldErrorInvalidFormat, "must be an RFC3339 timestamp")
+		return time.Time{}
+	}
+	return parsed
+}
+
+// isValidAccessPassID reports whether id can be used as a client-assigned
+// access pass ID. The ID ends up in request paths, so it is kept to
+// characters that never need escaping.
+func isValidAccessPassID(id string) bool {
+	if len(id) > MaxAccessPassIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isValidEmail reports whether email is a bare address such as
+// "john@example.com"
+func isValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && strings.Contains(email, ".")
+}
+
+// Validate checks the params for an empty full name and a malformed
+// expiration date. It returns a *ValidationError listing every failing field.
+func (p PatchAccessPassParams) Validate() error {
+	errs := &ValidationError{}
+
+	if p.FullName != nil && strings.TrimSpace(*p.FullName) == "" {
+		errs.add("fullName", FieldErrorRequired, "must not be empty")
+	}
+	if p.ExpirationDate != nil {
+		if _, err := time.Parse(time.RFC3339, *p.ExpirationDate); err != nil {
+			errs.add("expirationDate", FieldErrorInvalidFormat, "must be an RFC3339 timestamp")
+		}
+	}
+	validateMetadata(errs, p.Metadata)
+
+	return errs.errOrNil()
+}
@@ -0,0 +1,369 @@
+package doorpasses
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIssueAccessPassParamsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(p *IssueAccessPassParams)
+		wantFields []string
+	}{
+		{
+			name:   "valid params",
+			modify: func(p *IssueAccessPassParams) {},
+		},
+		{
+			name: "file data instead of card number",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardNumber = ""
+				p.FileData = "AQID"
+			},
+		},
+		{
+			name: "time fields instead of strings",
+			modify: func(p *IssueAccessPassParams) {
+				p.StartDate, p.ExpirationDate = "", ""
+				p.StartAt = time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+				p.ExpiresAt = p.StartAt.AddDate(1, 0, 0)
+			},
+		},
+		{
+			name: "template reference instead of ID",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardTemplateID = ""
+				p.CardTemplateRef = "employee-badge"
+			},
+		},
+		{
+			name: "template ID and reference",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardTemplateRef = "employee-badge"
+			},
+			wantFields: []string{"cardTemplateRef"},
+		},
+		{
+			name: "client-assigned ID",
+			modify: func(p *IssueAccessPassParams) {
+				p.ID = "emp_00042-badge"
+			},
+		},
+		{
+			name: "ID with characters that need escaping",
+			modify: func(p *IssueAccessPassParams) {
+				p.ID = "emp/42"
+			},
+			wantFields: []string{"id"},
+		},
+		{
+			name: "ID too long",
+			modify: func(p *IssueAccessPassParams) {
+				p.ID = strings.Repeat("a", MaxAccessPassIDLength+1)
+			},
+			wantFields: []string{"id"},
+		},
+		{
+			name: "metadata within limits",
+			modify: func(p *IssueAccessPassParams) {
+				p.Metadata = map[string]interface{}{
+					"costCenter": "cc-42",
+					strings.Repeat("k", MaxMetadataKeyLength): strings.Repeat("v", MaxMetadataValueLength),
+				}
+			},
+		},
+		{
+			name: "metadata over limits",
+			modify: func(p *IssueAccessPassParams) {
+				p.Metadata = map[string]interface{}{
+					"building": strings.Repeat("v", MaxMetadataValueLength+1),
+					strings.Repeat("k", MaxMetadataKeyLength+1): "A",
+				}
+			},
+			wantFields: []string{"metadata.building", "metadata." + strings.Repeat("k", MaxMetadataKeyLength+1)},
+		},
+		{
+			name: "non-string metadata within limits",
+			modify: func(p *IssueAccessPassParams) {
+				p.Metadata = map[string]interface{}{
+					"floor":  3,
+					"badges": []string{"lobby", "garage"},
+				}
+			},
+		},
+		{
+			name: "non-string metadata over limits",
+			modify: func(p *IssueAccessPassParams) {
+				p.Metadata = map[string]interface{}{
+					"doors":  map[string]string{"notes": strings.Repeat("v", MaxMetadataValueLength)},
+					"badges": make(chan int),
+				}
+			},
+			wantFields: []string{"metadata.badges", "metadata.doors"},
+		},
+		{
+			name: "every failing field is reported",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardTemplateID = ""
+				p.Email = "not-an-email"
+				p.StartDate = "tomorrow"
+			},
+			wantFields: []string{"cardTemplateId", "email", "startDate"},
+		},
+		{
+			name: "missing required fields",
+			modify: func(p *IssueAccessPassParams) {
+				*p = IssueAccessPassParams{}
+			},
+			wantFields: []string{"cardTemplateId", "fullName", "cardNumber", "startDate", "expirationDate"},
+		},
+		{
+			name: "email with display name",
+			modify: func(p *IssueAccessPassParams) {
+				p.Email = "John Doe <john@example.com>"
+			},
+			wantFields: []string{"email"},
+		},
+		{
+			name: "expiration before start",
+			modify: func(p *IssueAccessPassParams) {
+				p.ExpirationDate = "2025-10-01T00:00:00Z"
+			},
+			wantFields: []string{"expirationDate"},
+		},
+		{
+			name: "expiration equal to start",
+			modify: func(p *IssueAccessPassParams) {
+				p.ExpirationDate = p.StartDate
+			},
+			wantFields: []string{"expirationDate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := validIssueParams()
+			tt.modify(&params)
+
+			err := params.Validate()
+			if tt.wantFields == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+			var fields []string
+			for _, field := range validationErr.Fields {
+				fields = append(fields, field.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("invalid fields = %v, want %v", fields, tt.wantFields)
+			}
+			if !IsValidation(err) {
+				t.Errorf("IsValidation() = false, want true")
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       *Config
+		wantErr      bool
+		wantRequests int
+	}{
+		{
+			name:    "invalid params are rejected before sending",
+			config:  &Config{},
+			wantErr: true,
+		},
+		{
+			name:         "validation can be disabled",
+			config:       &Config{DisableClientValidation: true},
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			client := newTestClient(t, tt.config, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(http.StatusCreated)
+				w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+			})
+
+			_, err := client.AccessPasses.Issue(IssueAccessPassParams{FullName: "John Doe"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Issue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("server received %d requests, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestIssueAccessPassParamsNormalized(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(p *IssueAccessPassParams)
+		check      func(t *testing.T, p IssueAccessPassParams)
+		wantFields []string
+	}{
+		{
+			name: "trims whitespace",
+			modify: func(p *IssueAccessPassParams) {
+				p.Email = " john@example.com\n"
+				p.FullName = "\tJohn Doe "
+				p.CardNumber = " 12345 "
+			},
+			check: func(t *testing.T, p IssueAccessPassParams) {
+				if p.Email != "john@example.com" || p.FullName != "John Doe" || p.CardNumber != "12345" {
+					t.Errorf("normalized() = %q, %q, %q", p.Email, p.FullName, p.CardNumber)
+				}
+			},
+		},
+		{
+			name: "keeps leading zeros",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardNumber = " 000123"
+			},
+			check: func(t *testing.T, p IssueAccessPassParams) {
+				if p.CardNumber != "000123" {
+					t.Errorf("CardNumber = %q, want 000123", p.CardNumber)
+				}
+			},
+		},
+		{
+			name: "file data without a card number",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardNumber = ""
+				p.FileData = "AQID"
+			},
+		},
+		{
+			name: "letters in card number",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardNumber = "12A45"
+			},
+			wantFields: []string{"cardNumber"},
+		},
+		{
+			name: "inner space in card number",
+			modify: func(p *IssueAccessPassParams) {
+				p.CardNumber = "123 45"
+			},
+			wantFields: []string{"cardNumber"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := validIssueParams()
+			tt.modify(&params)
+
+			got, err := params.normalized()
+			if len(tt.wantFields) > 0 {
+				var validationErr *ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("normalized() error = %v, want *ValidationError", err)
+				}
+				var fields []string
+				for _, field := range validationErr.Fields {
+					fields = append(fields, field.Field)
+				}
+				if !reflect.DeepEqual(fields, tt.wantFields) {
+					t.Errorf("invalid fields = %v, want %v", fields, tt.wantFields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalized() error = %v", err)
+			}
+			if tt.check != nil {
+				tt.check(t, got)
+			}
+		})
+	}
+}
+
+func TestAccessPassesIssueNormalizeInputs(t *testing.T) {
+	var body map[string]interface{}
+	client := newTestClient(t, &Config{NormalizeInputs: true}, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"success": true, "data": {"id": "pass_123"}}`))
+	})
+
+	params := validIssueParams()
+	params.CardNumber = " 007 "
+	params.Email = "john@example.com "
+	if _, err := client.AccessPasses.Issue(params); err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	// The card number must reach the API as a string with its leading zeros
+	if body["cardNumber"] != "007" || body["email"] != "john@example.com" {
+		t.Errorf("body cardNumber = %#v, email = %#v, want \"007\", \"john@example.com\"", body["cardNumber"], body["email"])
+	}
+
+	body = nil
+	params.CardNumber = "12-34"
+	if _, err := client.AccessPasses.Issue(params); !IsValidation(err) {
+		t.Errorf("Issue() error = %v, want validation error", err)
+	}
+	if body != nil {
+		t.Error("malformed card number was sent")
+	}
+}
+
+func TestValidationErrorCodes(t *testing.T) {
+	params := validIssueParams()
+	params.CardTemplateID = ""
+	params.Email = "not-an-email"
+	params.ExpirationDate = params.StartDate
+
+	var validationErr *ValidationError
+	if !errors.As(params.Validate(), &validationErr) {
+		t.Fatal("Validate() did not return a *ValidationError")
+	}
+	want := []FieldError{
+		{Field: "cardTemplateId", Code: FieldErrorRequired, Message: "is required"},
+		{Field: "email", Code: FieldErrorInvalidFormat, Message: "is not a valid email address"},
+		{Field: "expirationDate", Code: FieldErrorOutOfRange, Message: "must be after startDate"},
+	}
+	if !reflect.DeepEqual(validationErr.Fields, want) {
+		t.Errorf("Fields = %+v, want %+v", validationErr.Fields, want)
+	}
+}
+
+func TestAccessPassesUpdateValidation(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	_, err := client.AccessPasses.Update(UpdateAccessPassParams{
+		AccessPassID: "pass_123",
+		Metadata:     map[string]interface{}{"cohort": strings.Repeat("v", MaxMetadataValueLength+1)},
+	})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Update() error = %v, want a *ValidationError", err)
+	}
+	want := []FieldError{{Field: "metadata.cohort", Code: FieldErrorOutOfRange, Message: "value must be at most 500 characters"}}
+	if !reflect.DeepEqual(validationErr.Fields, want) {
+		t.Errorf("Fields = %+v, want %+v", validationErr.Fields, want)
+	}
+}
@@ -0,0 +1,16 @@
+package doorpasses
+
+// Version is the version of this SDK, reported in the User-Agent header
+const Version = "1.0.0"
+
+// defaultUserAgent identifies the SDK on every request
+const defaultUserAgent = "doorpasses-go/" + Version
+
+// userAgent returns the User-Agent header sent with every request, with the
+// caller's product token appended to the SDK's own
+func userAgent(custom string) string {
+	if custom == "" {
+		return defaultUserAgent
+	}
+	return defaultUserAgent + " " + custom
+}
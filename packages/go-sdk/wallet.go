@@ -0,0 +1,275 @@
+package doorpasses
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ApplePassContentType is the media type of an Apple Wallet pass
+const ApplePassContentType = "application/vnd.apple.pkpass"
+
+// DownloadApplePass downloads the Apple Wallet .pkpass file for an access
+// pass, returning its bytes and content type. A pass that hasn't been
+// provisioned yet returns ErrPassNotProvisioned.
+func (a *AccessPasses) DownloadApplePass(accessPassID string, opts ...RequestOption) ([]byte, string, error) {
+	return a.DownloadApplePassWithContext(context.Background(), accessPassID, opts...)
+}
+
+// DownloadApplePassWithContext downloads the Apple Wallet .pkpass file for an
+// access pass, aborting if ctx is done
+func (a *AccessPasses) DownloadApplePassWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) ([]byte, string, error) {
+	opts = withOperation(opts, "AccessPasses.DownloadApplePass")
+	var buf bytes.Buffer
+	contentType, err := a.DownloadApplePassToWithContext(ctx, accessPassID, &buf, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), contentType, nil
+}
+
+// DownloadApplePassTo streams the Apple Wallet .pkpass file for an access
+// pass to w and returns its content type. Nothing is written to w when the
+// API responds with an error.
+func (a *AccessPasses) DownloadApplePassTo(accessPassID string, w io.Writer, opts ...RequestOption) (string, error) {
+	return a.DownloadApplePassToWithContext(context.Background(), accessPassID, w, opts...)
+}
+
+// DownloadApplePassToWithContext streams the Apple Wallet .pkpass file for an
+// access pass to w, aborting if ctx is done
+func (a *AccessPasses) DownloadApplePassToWithContext(ctx context.Context, accessPassID string, w io.Writer, opts ...RequestOption) (string, error) {
+	opts = withOperation(opts, "AccessPasses.DownloadApplePassTo")
+	if accessPassID == "" {
+		return "", fmt.Errorf("accessPassId is required")
+	}
+
+	sigPayload := map[string]interface{}{
+		"id": accessPassID,
+	}
+
+	contentType, err := a.http.download(ctx, fmt.Sprintf("/v1/wallet/passes/%s", accessPassID), sigPayload, ApplePassContentType, w, opts)
+	if err != nil {
+		if hasStatus(err, http.StatusNotFound) {
+			return "", fmt.Errorf("%w: %w", ErrPassNotProvisioned, err)
+		}
+		return "", err
+	}
+	return contentType, nil
+}
+
+// googleWalletPass is the API's response for a pass whose card template
+// targets Google Wallet
+type googleWalletPass struct {
+	Platform   string `json:"platform"`
+	InstallURL string `json:"installUrl"`
+}
+
+// GoogleWalletLink returns the Google Wallet "Add to Wallet" URL for an
+// access pass. If the pass's card template isn't set up for Google Wallet,
+// e.g. because it targets Apple Wallet, ErrGoogleWalletNotConfigured is
+// returned. A pass that hasn't been provisioned yet returns
+// ErrPassNotProvisioned.
+func (a *AccessPasses) GoogleWalletLink(accessPassID string, opts ...RequestOption) (string, error) {
+	return a.GoogleWalletLinkWithContext(context.Background(), accessPassID, opts...)
+}
+
+// GoogleWalletLinkWithContext returns the Google Wallet "Add to Wallet" URL
+// for an access pass, aborting if ctx is done
+func (a *AccessPasses) GoogleWalletLinkWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (string, error) {
+	opts = withOperation(opts, "AccessPasses.GoogleWalletLink")
+	if accessPassID == "" {
+		return "", fmt.Errorf("accessPassId is required")
+	}
+
+	sigPayload := map[string]interface{}{
+		"id": accessPassID,
+	}
+
+	// The endpoint serves a .pkpass file instead of JSON when the template
+	// targets Apple Wallet
+	var buf bytes.Buffer
+	raw := &rawResponse{w: &buf, allowJSON: true}
+	err := a.http.getRaw(ctx, fmt.Sprintf("/v1/wallet/passes/%s", accessPassID), sigPayload, "application/json", raw, opts)
+	if err != nil {
+		var apiErr *APIError
+		switch {
+		case hasStatus(err, http.StatusNotFound):
+			return "", fmt.Errorf("%w: %w", ErrPassNotProvisioned, err)
+		case errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "not configured for Google Wallet"):
+			return "", fmt.Errorf("%w: %w", ErrGoogleWalletNotConfigured, err)
+		}
+		return "", err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(raw.contentType)
+	if mediaType == ApplePassContentType {
+		return "", fmt.Errorf("%w: the template targets Apple Wallet", ErrGoogleWalletNotConfigured)
+	}
+	if mediaType != "application/json" {
+		return "", fmt.Errorf("unexpected response content type %q", raw.contentType)
+	}
+
+	var pass googleWalletPass
+	if err := decodeResult(buf.Bytes(), &pass, a.http.unmarshal); err != nil {
+		return "", err
+	}
+	if pass.Platform != "GOOGLE" || pass.InstallURL == "" {
+		return "", ErrGoogleWalletNotConfigured
+	}
+	return pass.InstallURL, nil
+}
+
+// EnrollmentURL returns the link a holder opens to add an access pass to
+// their wallet. It is an absolute https URL, e.g.
+// "https://doorpasses.com/install/<id>", which picks Apple or Google Wallet
+// for the holder's device; encode it unchanged to render your own QR code.
+//
+// A revoked or expired pass returns ErrPassAlreadyRevoked or ErrPassExpired
+// instead of a URL, and a pass without a link yet returns
+// ErrPassNotProvisioned.
+func (a *AccessPasses) EnrollmentURL(accessPassID string, opts ...RequestOption) (string, error) {
+	return a.EnrollmentURLWithContext(context.Background(), accessPassID, opts...)
+}
+
+// EnrollmentURLWithContext returns the enrollment link for an access pass,
+// aborting if ctx is done
+func (a *AccessPasses) EnrollmentURLWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (string, error) {
+	opts = withOperation(opts, "AccessPasses.EnrollmentURL")
+	accessPass, err := a.GetWithContext(ctx, accessPassID, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	switch accessPass.State {
+	case AccessPassStateRevoked:
+		return "", fmt.Errorf("%w: %s cannot be enrolled", ErrPassAlreadyRevoked, accessPassID)
+	case AccessPassStateExpired:
+		return "", fmt.Errorf("%w: %s cannot be enrolled", ErrPassExpired, accessPassID)
+	case AccessPassStateDeleted:
+		return "", fmt.Errorf("access pass %s is deleted and cannot be enrolled", accessPassID)
+	}
+
+	// The state may lag behind the expiration date
+	if !accessPass.ExpiresAt.IsZero() && time.Now().After(accessPass.ExpiresAt) {
+		return "", fmt.Errorf("%w: %s cannot be enrolled", ErrPassExpired, accessPassID)
+	}
+
+	if accessPass.URL == "" {
+		return "", fmt.Errorf("%w: %s has no enrollment URL", ErrPassNotProvisioned, accessPassID)
+	}
+	return accessPass.URL, nil
+}
+
+// PassArtifacts holds everything needed to distribute an access pass
+type PassArtifacts struct {
+	// ApplePass is the Apple Wallet .pkpass file, empty when the card
+	// template doesn't target Apple Wallet
+	ApplePass []byte
+
+	// GoogleWalletURL is the Google Wallet "Add to Wallet" URL, empty when
+	// the card template doesn't target Google Wallet
+	GoogleWalletURL string
+
+	// EnrollmentURL is the link that picks the wallet for the holder's
+	// device, as returned by EnrollmentURL
+	EnrollmentURL string
+}
+
+// Artifacts fetches the wallet pass and enrollment link of an access pass
+// concurrently. The API serves the wallet pass for the wallet the card
+// template targets, so the artifact of the other wallet is left empty
+// rather than failing. Errors are as for DownloadApplePass and
+// EnrollmentURL.
+func (a *AccessPasses) Artifacts(accessPassID string, opts ...RequestOption) (*PassArtifacts, error) {
+	return a.ArtifactsWithContext(context.Background(), accessPassID, opts...)
+}
+
+// ArtifactsWithContext fetches the wallet pass and enrollment link of an
+// access pass concurrently, aborting both if ctx is done or either fails
+func (a *AccessPasses) ArtifactsWithContext(ctx context.Context, accessPassID string, opts ...RequestOption) (*PassArtifacts, error) {
+	opts = withOperation(opts, "AccessPasses.Artifacts")
+	if accessPassID == "" {
+		return nil, fmt.Errorf("accessPassId is required")
+	}
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	artifacts := &PassArtifacts{}
+	var walletErr, enrollmentErr error
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if walletErr = a.walletArtifacts(fetchCtx, accessPassID, artifacts, opts); walletErr != nil {
+			cancel()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if artifacts.EnrollmentURL, enrollmentErr = a.EnrollmentURLWithContext(fetchCtx, accessPassID, opts...); enrollmentErr != nil {
+			cancel()
+		}
+	}()
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Report the failure rather than the cancellation it caused in the other
+	// fetch
+	for _, err := range []error{walletErr, enrollmentErr} {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+	if err := errors.Join(walletErr, enrollmentErr); err != nil {
+		return nil, err
+	}
+	return artifacts, nil
+}
+
+// walletArtifacts fetches the wallet pass of an access pass into artifacts,
+// as a .pkpass file or a Google Wallet URL depending on the card template
+func (a *AccessPasses) walletArtifacts(ctx context.Context, accessPassID string, artifacts *PassArtifacts, opts []RequestOption) error {
+	sigPayload := map[string]interface{}{
+		"id": accessPassID,
+	}
+
+	var buf bytes.Buffer
+	raw := &rawResponse{w: &buf, allowJSON: true}
+	err := a.http.getRaw(ctx, fmt.Sprintf("/v1/wallet/passes/%s", accessPassID), sigPayload, ApplePassContentType+", application/json", raw, opts)
+	if err != nil {
+		var apiErr *APIError
+		switch {
+		case hasStatus(err, http.StatusNotFound):
+			return fmt.Errorf("%w: %w", ErrPassNotProvisioned, err)
+		case errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "not configured for Google Wallet"):
+			return nil
+		}
+		return err
+	}
+
+	switch mediaType, _, _ := mime.ParseMediaType(raw.contentType); mediaType {
+	case ApplePassContentType:
+		artifacts.ApplePass = buf.Bytes()
+	case "application/json":
+		var pass googleWalletPass
+		if err := decodeResult(buf.Bytes(), &pass, a.http.unmarshal); err != nil {
+			return err
+		}
+		if pass.Platform == "GOOGLE" {
+			artifacts.GoogleWalletURL = pass.InstallURL
+		}
+	default:
+		return fmt.Errorf("unexpected response content type %q", raw.contentType)
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package doorpasses
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestAccessPassesDownloadApplePass(t *testing.T) {
+	pkpass := []byte("PK\x03\x04pass-contents")
+
+	tests := []struct {
+		name            string
+		handler         http.HandlerFunc
+		want            []byte
+		wantErr         bool
+		wantProvisioned bool
+	}{
+		{
+			name: "pkpass file",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/wallet/passes/pass_123" {
+					t.Errorf("path = %s, want /v1/wallet/passes/pass_123", r.URL.Path)
+				}
+				if got := r.Header.Get("Accept"); got != ApplePassContentType {
+					t.Errorf("Accept = %q, want %q", got, ApplePassContentType)
+				}
+				w.Header().Set("Content-Type", ApplePassContentType)
+				w.Write(pkpass)
+			},
+			want:            pkpass,
+			wantProvisioned: true,
+		},
+		{
+			name: "not provisioned",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+			},
+			wantErr: true,
+		},
+		{
+			name: "json instead of pkpass",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Write([]byte(`{"success": true, "data": {"platform": "GOOGLE"}}`))
+			},
+			wantErr:         true,
+			wantProvisioned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, tt.handler)
+
+			got, contentType, err := client.AccessPasses.DownloadApplePass("pass_123")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadApplePass() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if notProvisioned := errors.Is(err, ErrPassNotProvisioned); notProvisioned == tt.wantProvisioned {
+				t.Errorf("errors.Is(err, ErrPassNotProvisioned) = %v (err = %v)", notProvisioned, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("DownloadApplePass() = %q, want %q", got, tt.want)
+			}
+			if contentType != ApplePassContentType {
+				t.Errorf("content type = %q, want %q", contentType, ApplePassContentType)
+			}
+		})
+	}
+}
+
+func TestAccessPassesGoogleWalletLink(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+		wantErr error
+	}{
+		{
+			name: "install url",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v1/wallet/passes/pass_123" {
+					t.Errorf("path = %s, want /v1/wallet/passes/pass_123", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.Write([]byte(`{"success": true, "data": {"platform": "GOOGLE", "installUrl": "https://pay.google.com/gp/v/save/jwt"}}`))
+			},
+			want: "https://pay.google.com/gp/v/save/jwt",
+		},
+		{
+			name: "apple template",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", ApplePassContentType)
+				w.Write([]byte("PK\x03\x04pass-contents"))
+			},
+			wantErr: ErrGoogleWalletNotConfigured,
+		},
+		{
+			name: "missing install url",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"success": true, "data": {"platform": "GOOGLE"}}`))
+			},
+			wantErr: ErrGoogleWalletNotConfigured,
+		},
+		{
+			name: "template not configured",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"success": false, "error": {"code": "WALLET_ERROR", "message": "Card template is not configured for Google Wallet"}}`))
+			},
+			wantErr: ErrGoogleWalletNotConfigured,
+		},
+		{
+			name: "not provisioned",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+			},
+			wantErr: ErrPassNotProvisioned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, tt.handler)
+
+			got, err := client.AccessPasses.GoogleWalletLink("pass_123")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GoogleWalletLink() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("GoogleWalletLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessPassesEnrollmentURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "active pass",
+			body: `{"id": "pass_123", "state": "active", "expirationDate": "2999-01-01T00:00:00Z", "url": "https://doorpasses.com/install/pass_123"}`,
+			want: "https://doorpasses.com/install/pass_123",
+		},
+		{
+			name:    "revoked pass",
+			body:    `{"id": "pass_123", "state": "revoked", "url": "https://doorpasses.com/install/pass_123"}`,
+			wantErr: ErrPassAlreadyRevoked,
+		},
+		{
+			name:    "expired state",
+			body:    `{"id": "pass_123", "state": "expired", "url": "https://doorpasses.com/install/pass_123"}`,
+			wantErr: ErrPassExpired,
+		},
+		{
+			name:    "past expiration date",
+			body:    `{"id": "pass_123", "state": "active", "expirationDate": "2000-01-01T00:00:00Z", "url": "https://doorpasses.com/install/pass_123"}`,
+			wantErr: ErrPassExpired,
+		},
+		{
+			name:    "no url",
+			body:    `{"id": "pass_123", "state": "pending"}`,
+			wantErr: ErrPassNotProvisioned,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"success": true, "data": ` + tt.body + `}`))
+			})
+
+			got, err := client.AccessPasses.EnrollmentURL("pass_123")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("EnrollmentURL() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EnrollmentURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessPassesArtifacts(t *testing.T) {
+	activePass := `{"success": true, "data": {"id": "pass_123", "state": "active", "url": "https://doorpasses.com/install/pass_123"}}`
+
+	tests := []struct {
+		name    string
+		wallet  http.HandlerFunc
+		pass    string
+		want    PassArtifacts
+		wantErr error
+	}{
+		{
+			name: "apple template",
+			wallet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", ApplePassContentType)
+				w.Write([]byte("PK\x03\x04pass-contents"))
+			},
+			pass: activePass,
+			want: PassArtifacts{
+				ApplePass:     []byte("PK\x03\x04pass-contents"),
+				EnrollmentURL: "https://doorpasses.com/install/pass_123",
+			},
+		},
+		{
+			name: "google template",
+			wallet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"success": true, "data": {"platform": "GOOGLE", "installUrl": "https://pay.google.com/gp/v/save/jwt"}}`))
+			},
+			pass: activePass,
+			want: PassArtifacts{
+				GoogleWalletURL: "https://pay.google.com/gp/v/save/jwt",
+				EnrollmentURL:   "https://doorpasses.com/install/pass_123",
+			},
+		},
+		{
+			name: "wallet not configured",
+			wallet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"success": false, "error": {"code": "WALLET_ERROR", "message": "Card template is not configured for Google Wallet"}}`))
+			},
+			pass: activePass,
+			want: PassArtifacts{EnrollmentURL: "https://doorpasses.com/install/pass_123"},
+		},
+		{
+			name: "wallet pass not provisioned",
+			wallet: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"success": false, "error": {"code": "NOT_FOUND", "message": "Access pass not found"}}`))
+			},
+			pass:    activePass,
+			wantErr: ErrPassNotProvisioned,
+		},
+		{
+			name: "revoked pass",
+			wallet: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", ApplePassContentType)
+				w.Write([]byte("PK\x03\x04pass-contents"))
+			},
+			pass:    `{"success": true, "data": {"id": "pass_123", "state": "revoked"}}`,
+			wantErr: ErrPassAlreadyRevoked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &Config{MaxRetries: -1}, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/wallet/passes/pass_123":
+					tt.wallet(w, r)
+				case "/v1/access-passes/pass_123":
+					w.Write([]byte(tt.pass))
+				default:
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+			})
+
+			got, err := client.AccessPasses.Artifacts("pass_123")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Artifacts() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Artifacts() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Artifacts() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccessPassesArtifactsCancelled(t *testing.T) {
+	client := newTestClient(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request sent with a cancelled context")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.AccessPasses.ArtifactsWithContext(ctx, "pass_123"); !errors.Is(err, context.Canceled) {
+		t.Errorf("ArtifactsWithContext() error = %v, want context.Canceled", err)
+	}
+}
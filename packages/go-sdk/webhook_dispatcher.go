@@ -0,0 +1,137 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxWebhookBytes is the largest webhook body WebhookDispatcher accepts
+const maxWebhookBytes = 1 << 20
+
+// WebhookHandlerFunc handles a verified webhook event. Returning an error
+// answers the delivery with 500 so DoorPasses retries it.
+type WebhookHandlerFunc func(ctx context.Context, event *WebhookEvent) error
+
+// WebhookDispatcher is an http.Handler that verifies webhook deliveries and
+// routes each event to the handler registered for its type. It is safe to
+// register handlers while serving.
+//
+// Example:
+//
+//	dispatcher := doorpasses.NewWebhookDispatcher(secret)
+//	dispatcher.OnAccessPass(doorpasses.WebhookEventAccessPassRevoked, func(ctx context.Context, event *doorpasses.WebhookEvent, pass *doorpasses.AccessPass) error {
+//		return revokeBadge(ctx, pass.ID)
+//	})
+//	http.Handle("/webhooks/doorpasses", dispatcher)
+type WebhookDispatcher struct {
+	secret string
+
+	// Tolerance is the maximum age of an accepted webhook. Defaults to
+	// DefaultWebhookTolerance; zero disables the timestamp check.
+	Tolerance time.Duration
+
+	mu             sync.RWMutex
+	handlers       map[WebhookEventType]WebhookHandlerFunc
+	defaultHandler WebhookHandlerFunc
+}
+
+// NewWebhookDispatcher creates a dispatcher verifying webhooks with secret
+func NewWebhookDispatcher(secret string) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		secret:    secret,
+		Tolerance: DefaultWebhookTolerance,
+		handlers:  make(map[WebhookEventType]WebhookHandlerFunc),
+	}
+}
+
+// On registers handler for events of eventType, replacing any handler
+// registered for it before
+func (d *WebhookDispatcher) On(eventType WebhookEventType, handler WebhookHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers[eventType] = handler
+}
+
+// OnAccessPass registers handler for events of eventType, passing it the
+// event data decoded as an access pass
+func (d *WebhookDispatcher) OnAccessPass(eventType WebhookEventType, handler func(ctx context.Context, event *WebhookEvent, accessPass *AccessPass) error) {
+	d.On(eventType, func(ctx context.Context, event *WebhookEvent) error {
+		accessPass, err := event.AccessPass()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, event, accessPass)
+	})
+}
+
+// OnCardTemplate registers handler for events of eventType, passing it the
+// event data decoded as a card template
+func (d *WebhookDispatcher) OnCardTemplate(eventType WebhookEventType, handler func(ctx context.Context, event *WebhookEvent, template *CardTemplate) error) {
+	d.On(eventType, func(ctx context.Context, event *WebhookEvent) error {
+		template, err := event.CardTemplate()
+		if err != nil {
+			return err
+		}
+		return handler(ctx, event, template)
+	})
+}
+
+// OnDefault registers handler for events of types without a handler of
+// their own. Without one, such events are acknowledged and dropped.
+func (d *WebhookDispatcher) OnDefault(handler WebhookHandlerFunc) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.defaultHandler = handler
+}
+
+// ServeHTTP verifies the webhook and dispatches its event. It answers 401
+// to a delivery with a bad signature or timestamp, 400 to a malformed one,
+// 500 when the handler fails and 204 otherwise.
+func (d *WebhookDispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := readLimited(r.Body, maxWebhookBytes)
+	if errors.Is(err, ErrResponseTooLarge) {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+
+	if err := VerifyWebhookWithTolerance(payload, r.Header.Get(WebhookSignatureHeader), d.secret, d.Tolerance); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	event, err := ParseWebhookEvent(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if handler := d.handler(event.Type); handler != nil {
+		if err := handler(r.Context(), event); err != nil {
+			http.Error(w, "webhook handler failed", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handler returns the handler for eventType, falling back to the default
+func (d *WebhookDispatcher) handler(eventType WebhookEventType) WebhookHandlerFunc {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if handler, ok := d.handlers[eventType]; ok {
+		return handler
+	}
+	return d.defaultHandler
+}
@@ -0,0 +1,141 @@
+package doorpasses
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookDispatcher(t *testing.T) {
+	secret := "whsec_test"
+	issued := `{"specversion":"1.0","id":"evt_123","source":"doorpasses","type":"ag.access_pass.issued","data":{"id":"pass_123"}}`
+	published := `{"specversion":"1.0","id":"evt_456","source":"doorpasses","type":"ag.card_template.published","data":{"id":"template_123"}}`
+	unknown := `{"specversion":"1.0","id":"evt_789","source":"doorpasses","type":"ag.something.new","data":{}}`
+
+	tests := []struct {
+		name        string
+		method      string
+		payload     string
+		signature   string
+		failHandler bool
+		withDefault bool
+		wantStatus  int
+		wantHandled string
+	}{
+		{
+			name:        "access pass event",
+			payload:     issued,
+			wantStatus:  http.StatusNoContent,
+			wantHandled: "issued:pass_123",
+		},
+		{
+			name:        "card template event",
+			payload:     published,
+			wantStatus:  http.StatusNoContent,
+			wantHandled: "published:template_123",
+		},
+		{
+			name:       "unregistered type without default",
+			payload:    unknown,
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:        "unregistered type with default",
+			payload:     unknown,
+			withDefault: true,
+			wantStatus:  http.StatusNoContent,
+			wantHandled: "default:ag.something.new",
+		},
+		{
+			name:       "invalid signature",
+			payload:    issued,
+			signature:  SignWebhook([]byte(issued), "whsec_wrong", time.Now()),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "stale signature",
+			payload:    issued,
+			signature:  SignWebhook([]byte(issued), secret, time.Now().Add(-time.Hour)),
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "malformed event",
+			payload:    `not json`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "handler error",
+			payload:     issued,
+			failHandler: true,
+			wantStatus:  http.StatusInternalServerError,
+			wantHandled: "issued:pass_123",
+		},
+		{
+			name:       "wrong method",
+			method:     http.MethodGet,
+			payload:    issued,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var handled string
+			dispatcher := NewWebhookDispatcher(secret)
+			dispatcher.OnAccessPass(WebhookEventAccessPassIssued, func(ctx context.Context, event *WebhookEvent, accessPass *AccessPass) error {
+				handled = "issued:" + accessPass.ID
+				if tt.failHandler {
+					return errors.New("boom")
+				}
+				return nil
+			})
+			dispatcher.OnCardTemplate(WebhookEventCardTemplatePublished, func(ctx context.Context, event *WebhookEvent, template *CardTemplate) error {
+				handled = "published:" + template.ID
+				return nil
+			})
+			if tt.withDefault {
+				dispatcher.OnDefault(func(ctx context.Context, event *WebhookEvent) error {
+					handled = "default:" + string(event.Type)
+					return nil
+				})
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			signature := tt.signature
+			if signature == "" {
+				signature = SignWebhook([]byte(tt.payload), secret, time.Now())
+			}
+			req := httptest.NewRequest(method, "/webhooks", strings.NewReader(tt.payload))
+			req.Header.Set(WebhookSignatureHeader, signature)
+			rec := httptest.NewRecorder()
+
+			dispatcher.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if handled != tt.wantHandled {
+				t.Errorf("handled = %q, want %q", handled, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func TestWebhookDispatcherPayloadTooLarge(t *testing.T) {
+	dispatcher := NewWebhookDispatcher("whsec_test")
+	req := httptest.NewRequest(http.MethodPost, "/webhooks", strings.NewReader(strings.Repeat("a", maxWebhookBytes+1)))
+	rec := httptest.NewRecorder()
+
+	dispatcher.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
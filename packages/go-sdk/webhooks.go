@@ -0,0 +1,165 @@
+package doorpasses
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WebhookSignatureHeader is the header carrying a webhook's signature
+const WebhookSignatureHeader = "X-DoorPasses-Signature"
+
+// DefaultWebhookTolerance is the maximum age of a webhook accepted by VerifyWebhook
+const DefaultWebhookTolerance = 5 * time.Minute
+
+var (
+	// ErrInvalidWebhookSignature is returned when a webhook signature is
+	// missing, malformed, or does not match the payload
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+	// ErrWebhookTimestampOutOfRange is returned when a webhook was signed
+	// outside the accepted tolerance, which may indicate a replay
+	ErrWebhookTimestampOutOfRange = errors.New("webhook timestamp outside tolerance")
+)
+
+// WebhookEventType represents the type of a webhook event
+type WebhookEventType string
+
+const (
+	WebhookEventAccessPassIssued    WebhookEventType = "ag.access_pass.issued"
+	WebhookEventAccessPassActivated WebhookEventType = "ag.access_pass.activated"
+	WebhookEventAccessPassUpdated   WebhookEventType = "ag.access_pass.updated"
+	WebhookEventAccessPassSuspended WebhookEventType = "ag.access_pass.suspended"
+	WebhookEventAccessPassResumed   WebhookEventType = "ag.access_pass.resumed"
+	WebhookEventAccessPassUnlinked  WebhookEventType = "ag.access_pass.unlinked"
+	WebhookEventAccessPassRevoked   WebhookEventType = "ag.access_pass.revoked"
+	WebhookEventAccessPassDeleted   WebhookEventType = "ag.access_pass.deleted"
+	WebhookEventAccessPassExpired   WebhookEventType = "ag.access_pass.expired"
+
+	WebhookEventCardTemplateCreated   WebhookEventType = "ag.card_template.created"
+	WebhookEventCardTemplateUpdated   WebhookEventType = "ag.card_template.updated"
+	WebhookEventCardTemplatePublished WebhookEventType = "ag.card_template.published"
+)
+
+// WebhookEvent represents a webhook delivery, following the CloudEvents 1.0
+// specification
+type WebhookEvent struct {
+	SpecVersion     string           `json:"specversion"`
+	ID              string           `json:"id"`
+	Source          string           `json:"source"`
+	Type            WebhookEventType `json:"type"`
+	DataContentType string           `json:"datacontenttype,omitempty"`
+	Time            string           `json:"time"`
+	Data            json.RawMessage  `json:"data"`
+}
+
+// AccessPass decodes the event data as an access pass
+func (e *WebhookEvent) AccessPass() (*AccessPass, error) {
+	var accessPass AccessPass
+	if err := json.Unmarshal(e.Data, &accessPass); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal access pass: %w", err)
+	}
+	return &accessPass, nil
+}
+
+// CardTemplate decodes the event data as a card template
+func (e *WebhookEvent) CardTemplate() (*CardTemplate, error) {
+	var template CardTemplate
+	if err := json.Unmarshal(e.Data, &template); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal card template: %w", err)
+	}
+	return &template, nil
+}
+
+// ParseWebhookEvent decodes a webhook payload. Call VerifyWebhook first to
+// make sure the payload is authentic.
+func ParseWebhookEvent(payload []byte) (*WebhookEvent, error) {
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook event: %w", err)
+	}
+	if event.ID == "" || event.Type == "" {
+		return nil, fmt.Errorf("webhook event is missing id or type")
+	}
+	return &event, nil
+}
+
+// VerifyWebhook checks that payload was signed by DoorPasses with secret and
+// was sent within DefaultWebhookTolerance
+//
+// The signature header has the form "t=<unix timestamp>,v1=<signature>",
+// where the signature is computed with the same scheme as API requests over
+// "<timestamp>.<base64 payload>". Several v1 entries may be present while a
+// secret is being rotated.
+func VerifyWebhook(payload []byte, signatureHeader string, secret string) error {
+	return VerifyWebhookWithTolerance(payload, signatureHeader, secret, DefaultWebhookTolerance)
+}
+
+// VerifyWebhookWithTolerance is like VerifyWebhook with a custom maximum age.
+// A zero tolerance disables the timestamp check.
+func VerifyWebhookWithTolerance(payload []byte, signatureHeader string, secret string, tolerance time.Duration) error {
+	return verifyWebhook(payload, signatureHeader, secret, tolerance, time.Now())
+}
+
+func verifyWebhook(payload []byte, signatureHeader, secret string, tolerance time.Duration, now time.Time) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signatureHeader, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidWebhookSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	expected := []byte(webhookSignature(payload, secret, timestamp))
+	valid := false
+	for _, signature := range signatures {
+		if subtle.ConstantTimeCompare(expected, []byte(signature)) == 1 {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidWebhookSignature
+	}
+
+	if tolerance > 0 {
+		age := now.Sub(time.Unix(unix, 0))
+		if age > tolerance || age < -tolerance {
+			return ErrWebhookTimestampOutOfRange
+		}
+	}
+
+	return nil
+}
+
+// SignWebhook returns the signature header DoorPasses would send for payload
+// at the given time. It is mainly useful for testing webhook handlers.
+func SignWebhook(payload []byte, secret string, timestamp time.Time) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,v1=%s", t, webhookSignature(payload, secret, t))
+}
+
+// webhookSignature signs a webhook payload for the given timestamp
+func webhookSignature(payload []byte, secret, timestamp string) string {
+	return createSignature(secret, timestamp+"."+base64.StdEncoding.EncodeToString(payload))
+}
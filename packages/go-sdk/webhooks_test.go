@@ -0,0 +1,117 @@
+package doorpasses
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyWebhook(t *testing.T) {
+	secret := "whsec_test"
+	payload := []byte(`{"specversion":"1.0","id":"evt_123","source":"doorpasses","type":"ag.access_pass.issued","data":{"id":"pass_123"}}`)
+	now := time.Unix(1762000000, 0)
+	signature := SignWebhook(payload, secret, now)
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		header    string
+		secret    string
+		now       time.Time
+		tolerance time.Duration
+		wantErr   error
+	}{
+		{
+			name:      "valid signature",
+			payload:   payload,
+			header:    signature,
+			secret:    secret,
+			now:       now.Add(time.Minute),
+			tolerance: DefaultWebhookTolerance,
+		},
+		{
+			name:      "rotated secret",
+			payload:   payload,
+			header:    signature + ",v1=" + webhookSignature(payload, "whsec_old", "1762000000"),
+			secret:    secret,
+			now:       now,
+			tolerance: DefaultWebhookTolerance,
+		},
+		{
+			name:      "tampered payload",
+			payload:   []byte(`{"id":"evt_456"}`),
+			header:    signature,
+			secret:    secret,
+			now:       now,
+			tolerance: DefaultWebhookTolerance,
+			wantErr:   ErrInvalidWebhookSignature,
+		},
+		{
+			name:      "wrong secret",
+			payload:   payload,
+			header:    signature,
+			secret:    "whsec_wrong",
+			now:       now,
+			tolerance: DefaultWebhookTolerance,
+			wantErr:   ErrInvalidWebhookSignature,
+		},
+		{
+			name:      "malformed header",
+			payload:   payload,
+			header:    "garbage",
+			secret:    secret,
+			now:       now,
+			tolerance: DefaultWebhookTolerance,
+			wantErr:   ErrInvalidWebhookSignature,
+		},
+		{
+			name:      "replayed outside tolerance",
+			payload:   payload,
+			header:    signature,
+			secret:    secret,
+			now:       now.Add(10 * time.Minute),
+			tolerance: DefaultWebhookTolerance,
+			wantErr:   ErrWebhookTimestampOutOfRange,
+		},
+		{
+			name:      "tolerance disabled",
+			payload:   payload,
+			header:    signature,
+			secret:    secret,
+			now:       now.Add(24 * time.Hour),
+			tolerance: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyWebhook(tt.payload, tt.header, tt.secret, tt.tolerance, tt.now)
+			if err != tt.wantErr {
+				t.Errorf("verifyWebhook() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	payload := []byte(`{"specversion":"1.0","id":"evt_123","source":"doorpasses","type":"ag.access_pass.issued","time":"2025-11-01T00:00:00Z","data":{"id":"pass_123","fullName":"John Doe","state":"active"}}`)
+
+	event, err := ParseWebhookEvent(payload)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent() error = %v", err)
+	}
+	if event.Type != WebhookEventAccessPassIssued {
+		t.Errorf("Type = %v, want %v", event.Type, WebhookEventAccessPassIssued)
+	}
+
+	accessPass, err := event.AccessPass()
+	if err != nil {
+		t.Fatalf("AccessPass() error = %v", err)
+	}
+	if accessPass.ID != "pass_123" || accessPass.FullName != "John Doe" {
+		t.Errorf("AccessPass() = %+v, want pass_123", accessPass)
+	}
+
+	if _, err := ParseWebhookEvent([]byte(`{"data":{}}`)); err == nil {
+		t.Error("ParseWebhookEvent() should reject events without id and type")
+	}
+}